@@ -0,0 +1,266 @@
+// Package contract - OpenAPI контракт WorkMate API: встроенная спецификация
+// и проверка значений по ее схемам
+package contract
+
+import (
+	_ "embed"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed v1/workmate.yaml
+var specV1 []byte
+
+// SpecV1 - Исходный YAML спецификации v1
+func SpecV1() []byte {
+	return specV1
+}
+
+// Schema - схема OpenAPI в виде разобранного YAML
+type Schema map[string]interface{}
+
+// Parameter - параметр операции
+type Parameter struct {
+	Name     string
+	In       string
+	Required bool
+	Style    string
+	Explode  *bool
+	Schema   Schema
+}
+
+// Response - описание ответа операции
+type Response struct {
+	Headers map[string]Schema
+	// Content - схемы тела по media type (пусто, если тела нет)
+	Content map[string]Schema
+}
+
+// Operation - операция спецификации
+type Operation struct {
+	ID     string
+	Method string
+	// Path - путь с базовым префиксом сервера, например /api/v1/tasks/{taskId}
+	Path       string
+	Parameters []Parameter
+	// RequestBody - схемы тела запроса по media type (nil, если тела нет)
+	RequestBody         map[string]Schema
+	RequestBodyRequired bool
+	// MaxBodySize - предел размера тела из расширения x-max-body-size (0 - не задан)
+	MaxBodySize int64
+	// Responses - ответы по коду статуса ("200", "404", ...)
+	Responses map[string]Response
+}
+
+// Spec - разобранная спецификация
+type Spec struct {
+	doc        map[string]interface{}
+	Operations []Operation
+}
+
+// LoadV1 - Разобрать встроенную спецификацию v1
+func LoadV1() (*Spec, error) {
+	return Load(specV1)
+}
+
+// Load - Разобрать спецификацию из YAML
+func Load(data []byte) (*Spec, error) {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse spec: %w", err)
+	}
+	s := &Spec{doc: doc}
+
+	basePath := ""
+	if servers, ok := doc["servers"].([]interface{}); ok && len(servers) > 0 {
+		if server, ok := servers[0].(map[string]interface{}); ok {
+			basePath = serverPath(fmt.Sprint(server["url"]))
+		}
+	}
+
+	paths, _ := doc["paths"].(map[string]interface{})
+	for path, item := range paths {
+		pathItem, _ := item.(map[string]interface{})
+		common := s.parameters(pathItem["parameters"])
+		for method, raw := range pathItem {
+			op, ok := raw.(map[string]interface{})
+			if !ok || method == "parameters" {
+				continue
+			}
+			operation, err := s.operation(strings.ToUpper(method), basePath+path, op, common)
+			if err != nil {
+				return nil, err
+			}
+			s.Operations = append(s.Operations, operation)
+		}
+	}
+
+	sort.Slice(s.Operations, func(i, j int) bool {
+		if s.Operations[i].Path != s.Operations[j].Path {
+			return s.Operations[i].Path < s.Operations[j].Path
+		}
+		return s.Operations[i].Method < s.Operations[j].Method
+	})
+	return s, nil
+}
+
+// serverPath - Путь из URL сервера: http://localhost:8080/api/v1 -> /api/v1
+func serverPath(url string) string {
+	if i := strings.Index(url, "://"); i >= 0 {
+		url = url[i+3:]
+		if j := strings.Index(url, "/"); j >= 0 {
+			return strings.TrimSuffix(url[j:], "/")
+		}
+		return ""
+	}
+	return strings.TrimSuffix(url, "/")
+}
+
+func (s *Spec) operation(method, path string, op map[string]interface{}, common []Parameter) (Operation, error) {
+	operation := Operation{
+		ID:         fmt.Sprint(op["operationId"]),
+		Method:     method,
+		Path:       path,
+		Parameters: append(append([]Parameter{}, common...), s.parameters(op["parameters"])...),
+		Responses:  map[string]Response{},
+	}
+
+	if raw, ok := op["requestBody"].(map[string]interface{}); ok {
+		body := s.Resolve(raw)
+		operation.RequestBodyRequired, _ = body["required"].(bool)
+		if size, ok := number(body["x-max-body-size"]); ok {
+			operation.MaxBodySize = int64(size)
+		}
+		operation.RequestBody = s.content(body["content"])
+	}
+
+	responses, _ := op["responses"].(map[string]interface{})
+	for code, raw := range responses {
+		resp, ok := raw.(map[string]interface{})
+		if !ok {
+			return Operation{}, fmt.Errorf("%s %s: invalid response %s", method, path, code)
+		}
+		resp = s.Resolve(resp)
+		response := Response{Headers: map[string]Schema{}, Content: s.content(resp["content"])}
+		headers, _ := resp["headers"].(map[string]interface{})
+		for name, h := range headers {
+			header, _ := h.(map[string]interface{})
+			header = s.Resolve(header)
+			schema, _ := header["schema"].(map[string]interface{})
+			response.Headers[name] = Schema(schema)
+		}
+		operation.Responses[code] = response
+	}
+	return operation, nil
+}
+
+func (s *Spec) parameters(raw interface{}) []Parameter {
+	list, _ := raw.([]interface{})
+	params := make([]Parameter, 0, len(list))
+	for _, item := range list {
+		p, _ := item.(map[string]interface{})
+		p = s.Resolve(p)
+		param := Parameter{
+			Name: fmt.Sprint(p["name"]),
+			In:   fmt.Sprint(p["in"]),
+		}
+		param.Required, _ = p["required"].(bool)
+		param.Style, _ = p["style"].(string)
+		if explode, ok := p["explode"].(bool); ok {
+			param.Explode = &explode
+		}
+		schema, _ := p["schema"].(map[string]interface{})
+		param.Schema = Schema(schema)
+		params = append(params, param)
+	}
+	return params
+}
+
+func (s *Spec) content(raw interface{}) map[string]Schema {
+	content, _ := raw.(map[string]interface{})
+	result := make(map[string]Schema, len(content))
+	for mediaType, m := range content {
+		media, _ := m.(map[string]interface{})
+		schema, _ := media["schema"].(map[string]interface{})
+		result[mediaType] = Schema(schema)
+	}
+	return result
+}
+
+// Resolve - Раскрыть $ref на компонент документа (#/components/...)
+func (s *Spec) Resolve(node map[string]interface{}) map[string]interface{} {
+	for i := 0; i < 16; i++ {
+		ref, ok := node["$ref"].(string)
+		if !ok {
+			return node
+		}
+		var current interface{} = s.doc
+		for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			m, _ := current.(map[string]interface{})
+			current = m[part]
+		}
+		node, _ = current.(map[string]interface{})
+	}
+	return node
+}
+
+// namespaceSegment - сегмент пути, с которым операции с задачами доступны в пространстве имен:
+// /api/v1/namespaces/{namespace}/tasks/{taskId} - псевдоним /api/v1/tasks/{taskId}
+const namespaceSegment = "/namespaces/{namespace}/tasks"
+
+// Operation - Найти операцию по методу и шаблону пути. Пути операций с задачами
+// в пространстве имен (/namespaces/{namespace}/tasks...) находят операцию /tasks...
+func (s *Spec) Operation(method, path string) (*Operation, bool) {
+	for i := range s.Operations {
+		if s.Operations[i].Method == method && s.Operations[i].Path == path {
+			return &s.Operations[i], true
+		}
+	}
+	if strings.Contains(path, namespaceSegment) {
+		return s.Operation(method, strings.Replace(path, namespaceSegment, "/tasks", 1))
+	}
+	return nil, false
+}
+
+// OperationByID - Найти операцию по operationId (без учета регистра первой буквы)
+func (s *Spec) OperationByID(id string) (*Operation, bool) {
+	for i := range s.Operations {
+		if strings.EqualFold(s.Operations[i].ID, id) {
+			return &s.Operations[i], true
+		}
+	}
+	return nil, false
+}
+
+// MediaType - media type без параметров: "application/json; charset=UTF-8" -> "application/json"
+func MediaType(contentType string) string {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
+
+// IsJSON - JSON ли media type: application/json или структурный суффикс +json (RFC 6839)
+func IsJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// Lookup - Найти ответ по коду статуса (с учетом шаблонов 2XX и default)
+func (o *Operation) Lookup(status int) (Response, bool) {
+	code := fmt.Sprint(status)
+	if resp, ok := o.Responses[code]; ok {
+		return resp, true
+	}
+	if resp, ok := o.Responses[code[:1]+"XX"]; ok {
+		return resp, true
+	}
+	resp, ok := o.Responses["default"]
+	return resp, ok
+}
+
+// StatusText - Текст статуса для сообщений о нарушениях
+func StatusText(status int) string {
+	return fmt.Sprintf("%d %s", status, http.StatusText(status))
+}
@@ -0,0 +1,107 @@
+package contract
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestLoadV1(t *testing.T) {
+	spec, err := LoadV1()
+	if err != nil {
+		t.Fatalf("LoadV1() returned error: %v", err)
+	}
+
+	op, ok := spec.Operation(http.MethodGet, "/api/v1/tasks/{taskId}")
+	if !ok {
+		t.Fatal("Expected operation GET /api/v1/tasks/{taskId}")
+	}
+	if op.ID != "getTask" {
+		t.Errorf("Expected operationId 'getTask', got '%s'", op.ID)
+	}
+	if len(op.Parameters) != 2 || op.Parameters[0].Name != "taskId" || !op.Parameters[0].Required {
+		t.Errorf("Expected path parameter taskId, got %+v", op.Parameters)
+	}
+	if len(op.Parameters) == 2 && (op.Parameters[1].Name != "If-None-Match" || op.Parameters[1].In != "header") {
+		t.Errorf("Expected header parameter If-None-Match resolved from components, got %+v", op.Parameters[1])
+	}
+	if _, ok := op.Lookup(http.StatusTooManyRequests); !ok {
+		t.Error("Expected 429 response resolved from components")
+	}
+	if alias, ok := spec.Operation(http.MethodGet, "/api/v1/namespaces/{namespace}/tasks/{taskId}"); !ok || alias.ID != "getTask" {
+		t.Error("Expected namespaced path to resolve to getTask")
+	}
+	if _, ok := spec.OperationByID("CreateTask"); !ok {
+		t.Error("OperationByID() should ignore the case of the first letter")
+	}
+	if op, _ := spec.OperationByID("importTasks"); op.MaxBodySize != 64<<20 {
+		t.Errorf("Expected x-max-body-size 64 MB for importTasks, got %d", op.MaxBodySize)
+	}
+}
+
+func TestValidateResponse(t *testing.T) {
+	spec, _ := LoadV1()
+	op, _ := spec.OperationByID("getTask")
+	json := http.Header{"Content-Type": {"application/json; charset=UTF-8"}}
+
+	valid := `{"task":{"id":"550e8400-e29b-41d4-a716-446655440000","name":"Task","status":"running","createdAt":"2024-01-15T15:04:05Z"}}`
+	if v := spec.ValidateResponse(op, 200, json, []byte(valid)); len(v) != 0 {
+		t.Errorf("Expected valid response, got %v", v)
+	}
+
+	cases := map[string]struct {
+		status int
+		header http.Header
+		body   string
+		want   string
+	}{
+		"undeclared status": {418, json, `{}`, "status 418 I'm a teapot is not declared"},
+		"missing field":     {200, json, `{"task":{"id":"550e8400-e29b-41d4-a716-446655440000","name":"Task","status":"running"}}`, "/task/createdAt: is required"},
+		"enum":              {200, json, strings.Replace(valid, "running", "sleeping", 1), "/task/status: must be one of"},
+		"format":            {200, json, strings.Replace(valid, "2024-01-15T15:04:05Z", "yesterday", 1), "/task/createdAt: must be an RFC 3339 date-time"},
+		"undeclared field":  {200, json, strings.Replace(valid, `"name"`, `"priority":1,"name"`, 1), "/task/priority: is not declared"},
+		"content type":      {404, http.Header{"Content-Type": {"text/plain"}}, `not found`, `content type "text/plain" is not declared`},
+		"header":            {429, http.Header{"Content-Type": {"application/problem+json"}, "Retry-After": {"soon"}}, `{"type":"urn:workmate:problem:rate_limited","title":"Rate limited","status":429,"code":"rate_limited"}`, "header:/Retry-After: must be an integer"},
+	}
+	for name, c := range cases {
+		violations := spec.ValidateResponse(op, c.status, c.header, []byte(c.body))
+		found := false
+		for _, v := range violations {
+			if strings.Contains(v.String(), c.want) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("%s: expected violation %q, got %v", name, c.want, violations)
+		}
+	}
+}
+
+func TestValidateResponseMediaRange(t *testing.T) {
+	spec, _ := LoadV1()
+	op, _ := spec.OperationByID("getTaskResultContent")
+
+	csv := http.Header{"Content-Type": {"text/csv"}, "Accept-Ranges": {"bytes"}}
+	if v := spec.ValidateResponse(op, 200, csv, []byte("id,value\n1,2\n")); len(v) != 0 {
+		t.Errorf("Expected content matching */* to be valid, got %v", v)
+	}
+	csv.Set("Accept-Ranges", "pages")
+	if v := spec.ValidateResponse(op, 200, csv, nil); len(v) != 1 || v[0].Pointer != "/Accept-Ranges" {
+		t.Errorf("Expected header violation for content matching */*, got %v", v)
+	}
+	problem := http.Header{"Content-Type": {"application/json"}}
+	if v := spec.ValidateResponse(op, 404, problem, []byte(`{}`)); len(v) != 1 {
+		t.Errorf("Expected undeclared content type without media range, got %v", v)
+	}
+}
+
+func TestValidateReadOnly(t *testing.T) {
+	spec, _ := LoadV1()
+	schema := Schema{"$ref": "#/components/schemas/Task"}
+	task := map[string]interface{}{"id": "550e8400-e29b-41d4-a716-446655440000", "name": "Task", "status": "pending"}
+
+	violations := spec.Validate(schema, task, "", Options{Direction: Inbound})
+	if len(violations) != 1 || violations[0].Pointer != "/id" {
+		t.Errorf("Expected read-only violation at /id, got %v", violations)
+	}
+}
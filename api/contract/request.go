@@ -0,0 +1,101 @@
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ValidateParameters - Проверить параметры path и query операции по их схемам.
+// Значения query массивов разбираются по style/explode параметра
+func (s *Spec) ValidateParameters(op *Operation, path map[string]string, query url.Values) []Violation {
+	var violations []Violation
+	for _, param := range op.Parameters {
+		var raw []string
+		switch param.In {
+		case "path":
+			if value, ok := path[param.Name]; ok {
+				raw = []string{value}
+			}
+		case "query":
+			raw = query[param.Name]
+		default:
+			continue
+		}
+
+		pointer := "/" + escape(param.Name)
+		if len(raw) == 0 {
+			if param.Required {
+				violations = append(violations, Violation{In: param.In, Pointer: pointer, Message: "is required"})
+			}
+			continue
+		}
+
+		schema := Schema(s.Resolve(param.Schema))
+		var value interface{}
+		if schema["type"] == "array" {
+			items, _ := schema["items"].(map[string]interface{})
+			itemSchema := Schema(s.Resolve(items))
+			// form + explode=false: status=a,b; по умолчанию explode=true: status=a&status=b
+			if param.Explode != nil && !*param.Explode {
+				raw = strings.Split(strings.Join(raw, ","), ",")
+			}
+			list := make([]interface{}, len(raw))
+			for i, item := range raw {
+				list[i] = ParseParameter(itemSchema, item)
+			}
+			value = list
+		} else {
+			if len(raw) > 1 {
+				violations = append(violations, Violation{In: param.In, Pointer: pointer, Message: "must be specified once"})
+				continue
+			}
+			value = ParseParameter(schema, raw[0])
+		}
+
+		for _, v := range s.Validate(schema, value, pointer, Options{Direction: Inbound}) {
+			v.In = param.In
+			violations = append(violations, v)
+		}
+	}
+	return violations
+}
+
+// ValidateBody - Проверить тело запроса операции. malformed - тело не разбирается
+// (неверный JSON, неподдерживаемый Content-Type, отсутствует обязательное тело),
+// иначе нарушения схемы возвращаются списком
+func (s *Spec) ValidateBody(op *Operation, contentType string, body []byte) (violations []Violation, malformed bool) {
+	if op.RequestBody == nil {
+		return nil, false
+	}
+	if len(strings.TrimSpace(string(body))) == 0 {
+		if op.RequestBodyRequired {
+			return []Violation{{In: "body", Message: "request body is required"}}, true
+		}
+		return nil, false
+	}
+
+	mediaType := MediaType(contentType)
+	if mediaType == "" {
+		mediaType = "application/json"
+	}
+	schema, ok := op.RequestBody[mediaType]
+	if !ok {
+		return []Violation{{In: "header", Pointer: "/Content-Type", Message: fmt.Sprintf("content type %q is not supported", mediaType)}}, true
+	}
+	if !IsJSON(mediaType) {
+		return nil, false
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return []Violation{{In: "body", Message: "invalid JSON: " + err.Error()}}, true
+	}
+
+	violations = s.Validate(schema, value, "", Options{Direction: Inbound})
+	for i := range violations {
+		violations[i].In = "body"
+	}
+	return violations, false
+}
@@ -0,0 +1,373 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Violation - нарушение контракта с указателем на место (RFC 6901).
+// In - часть запроса: body, query, path или header; параметры query и path
+// адресуются как объект {"имя": значение}
+type Violation struct {
+	In      string `json:"in,omitempty"`
+	Pointer string `json:"pointer"`
+	Message string `json:"message"`
+}
+
+func (v Violation) String() string {
+	location := v.Pointer
+	if v.In != "" {
+		location = v.In + ":" + v.Pointer
+	}
+	if location == "" {
+		return v.Message
+	}
+	return location + ": " + v.Message
+}
+
+// Direction - направление данных: readOnly поля запрещены во входящих запросах,
+// writeOnly - в исходящих ответах
+type Direction int
+
+const (
+	Inbound Direction = iota
+	Outbound
+)
+
+// Options - параметры проверки значения по схеме
+type Options struct {
+	Direction Direction
+	// Strict - свойства, не описанные в схеме, считаются нарушением,
+	// если additionalProperties не разрешает их явно
+	Strict bool
+}
+
+// Validate - Проверить значение (результат json.Unmarshal в interface{}) по схеме
+func (s *Spec) Validate(schema Schema, value interface{}, pointer string, opts Options) []Violation {
+	var violations []Violation
+	s.validate(schema, value, pointer, opts, &violations)
+	return violations
+}
+
+func (s *Spec) validate(schema Schema, value interface{}, pointer string, opts Options, out *[]Violation) {
+	if schema == nil {
+		return
+	}
+	schema = Schema(s.Resolve(schema))
+	report := func(format string, args ...interface{}) {
+		*out = append(*out, Violation{Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if value == nil {
+		if nullable, _ := schema["nullable"].(bool); !nullable && schema["type"] != nil {
+			report("must not be null")
+		}
+		return
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok && !inEnum(enum, value) {
+		report("must be one of %s", formatEnum(enum))
+	}
+
+	switch schema["type"] {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			report("must be an object")
+			return
+		}
+		s.validateObject(schema, obj, pointer, opts, out)
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			report("must be an array")
+			return
+		}
+		if min, ok := number(schema["minItems"]); ok && float64(len(items)) < min {
+			report("must contain at least %v items", min)
+		}
+		if max, ok := number(schema["maxItems"]); ok && float64(len(items)) > max {
+			report("must contain at most %v items", max)
+		}
+		itemSchema, _ := schema["items"].(map[string]interface{})
+		for i, item := range items {
+			s.validate(Schema(itemSchema), item, pointer+"/"+strconv.Itoa(i), opts, out)
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			report("must be a string")
+			return
+		}
+		validateString(schema, str, report)
+	case "integer", "number":
+		n, ok := value.(float64)
+		if !ok && schema["type"] == "integer" {
+			report("must be an integer")
+			return
+		}
+		if !ok {
+			report("must be a number")
+			return
+		}
+		validateNumber(schema, n, report)
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			report("must be a boolean")
+		}
+	}
+}
+
+func (s *Spec) validateObject(schema Schema, obj map[string]interface{}, pointer string, opts Options, out *[]Violation) {
+	properties, _ := schema["properties"].(map[string]interface{})
+	required, _ := schema["required"].([]interface{})
+	for _, r := range required {
+		name := fmt.Sprint(r)
+		prop, _ := properties[name].(map[string]interface{})
+		prop = s.Resolve(prop)
+		if readOnly, _ := prop["readOnly"].(bool); readOnly && opts.Direction == Inbound {
+			continue
+		}
+		if writeOnly, _ := prop["writeOnly"].(bool); writeOnly && opts.Direction == Outbound {
+			continue
+		}
+		if _, ok := obj[name]; !ok {
+			*out = append(*out, Violation{Pointer: pointer + "/" + escape(name), Message: "is required"})
+		}
+	}
+
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		child := pointer + "/" + escape(name)
+		raw, declared := properties[name].(map[string]interface{})
+		if !declared {
+			switch additional := schema["additionalProperties"].(type) {
+			case map[string]interface{}:
+				s.validate(Schema(additional), obj[name], child, opts, out)
+			case bool:
+				if !additional {
+					*out = append(*out, Violation{Pointer: child, Message: "is not allowed"})
+				}
+			default:
+				if opts.Strict {
+					*out = append(*out, Violation{Pointer: child, Message: "is not declared in the schema"})
+				}
+			}
+			continue
+		}
+		prop := Schema(s.Resolve(raw))
+		if readOnly, _ := prop["readOnly"].(bool); readOnly && opts.Direction == Inbound {
+			*out = append(*out, Violation{Pointer: child, Message: "is read-only"})
+			continue
+		}
+		if writeOnly, _ := prop["writeOnly"].(bool); writeOnly && opts.Direction == Outbound {
+			*out = append(*out, Violation{Pointer: child, Message: "is write-only"})
+			continue
+		}
+		s.validate(prop, obj[name], child, opts, out)
+	}
+}
+
+func validateString(schema Schema, str string, report func(string, ...interface{})) {
+	length := float64(len([]rune(str)))
+	if min, ok := number(schema["minLength"]); ok && length < min {
+		report("must be at least %v characters long", min)
+	}
+	if max, ok := number(schema["maxLength"]); ok && length > max {
+		report("must be at most %v characters long", max)
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(str) {
+			report("must match pattern %q", pattern)
+		}
+	}
+	switch schema["format"] {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, str); err != nil {
+			report("must be an RFC 3339 date-time")
+		}
+	case "uuid":
+		if _, err := uuid.Parse(str); err != nil {
+			report("must be a UUID")
+		}
+	case "uri":
+		if u, err := url.Parse(str); err != nil || !u.IsAbs() {
+			report("must be an absolute URI")
+		}
+	case "byte":
+		if _, err := base64.StdEncoding.DecodeString(str); err != nil {
+			report("must be base64 encoded")
+		}
+	}
+}
+
+func validateNumber(schema Schema, n float64, report func(string, ...interface{})) {
+	if schema["type"] == "integer" {
+		if n != math.Trunc(n) {
+			report("must be an integer")
+			return
+		}
+		switch schema["format"] {
+		case "int32":
+			if n < math.MinInt32 || n > math.MaxInt32 {
+				report("must fit into int32")
+			}
+		case "int64":
+			if n < math.MinInt64 || n > math.MaxInt64 {
+				report("must fit into int64")
+			}
+		}
+	}
+	if min, ok := number(schema["minimum"]); ok {
+		if exclusive, _ := schema["exclusiveMinimum"].(bool); exclusive && n <= min {
+			report("must be greater than %v", min)
+		} else if n < min {
+			report("must be greater than or equal to %v", min)
+		}
+	}
+	if max, ok := number(schema["maximum"]); ok {
+		if exclusive, _ := schema["exclusiveMaximum"].(bool); exclusive && n >= max {
+			report("must be less than %v", max)
+		} else if n > max {
+			report("must be less than or equal to %v", max)
+		}
+	}
+}
+
+// ValidateResponse - Проверить ответ операции: код статуса должен быть объявлен,
+// Content-Type и тело - соответствовать объявленной схеме, заголовки - своим схемам
+func (s *Spec) ValidateResponse(op *Operation, status int, header http.Header, body []byte) []Violation {
+	resp, ok := op.Lookup(status)
+	if !ok {
+		return []Violation{{Message: fmt.Sprintf("%s: status %s is not declared", op.ID, StatusText(status))}}
+	}
+
+	var violations []Violation
+	names := make([]string, 0, len(resp.Headers))
+	for name := range resp.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := header.Get(name)
+		if value == "" {
+			continue
+		}
+		for _, v := range s.Validate(resp.Headers[name], ParseParameter(resp.Headers[name], value), "/"+name, Options{Direction: Outbound}) {
+			v.In = "header"
+			violations = append(violations, v)
+		}
+	}
+
+	if len(resp.Content) == 0 {
+		if len(bytes.TrimSpace(body)) > 0 {
+			violations = append(violations, Violation{Message: fmt.Sprintf("%s: status %s must not have a body", op.ID, StatusText(status))})
+		}
+		return violations
+	}
+
+	mediaType := MediaType(header.Get("Content-Type"))
+	schema, ok := resp.Content[mediaType]
+	if !ok {
+		// Диапазоны типов (text/*, */*) описывают произвольное содержимое без проверки тела
+		if _, ok = lookupMediaRange(resp.Content, mediaType); !ok {
+			return append(violations, Violation{Message: fmt.Sprintf("%s: content type %q is not declared for status %s", op.ID, mediaType, StatusText(status))})
+		}
+		return violations
+	}
+	if !IsJSON(mediaType) {
+		return violations
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return append(violations, Violation{Message: fmt.Sprintf("%s: invalid JSON body: %v", op.ID, err)})
+	}
+	return append(violations, s.Validate(schema, value, "", Options{Direction: Outbound, Strict: true})...)
+}
+
+// lookupMediaRange - Найти схему диапазона типов для типа содержимого: сначала type/*, затем */*
+func lookupMediaRange(content map[string]Schema, mediaType string) (Schema, bool) {
+	if i := strings.IndexByte(mediaType, '/'); i > 0 {
+		if schema, ok := content[mediaType[:i]+"/*"]; ok {
+			return schema, true
+		}
+	}
+	schema, ok := content["*/*"]
+	return schema, ok
+}
+
+// ParseParameter - Привести строковое значение параметра или заголовка к типу схемы;
+// если значение не приводится, оно возвращается строкой и проверка типа сообщит о нарушении
+func ParseParameter(schema Schema, value string) interface{} {
+	switch schema["type"] {
+	case "integer", "number":
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, e := range enum {
+		if n, ok := number(e); ok {
+			if v, ok := value.(float64); ok && v == n {
+				return true
+			}
+			continue
+		}
+		if e == value {
+			return true
+		}
+	}
+	return false
+}
+
+func formatEnum(enum []interface{}) string {
+	values := make([]string, len(enum))
+	for i, e := range enum {
+		values[i] = fmt.Sprint(e)
+	}
+	return "[" + strings.Join(values, ", ") + "]"
+}
+
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// escape - Экранирование сегмента JSON pointer (RFC 6901)
+func escape(segment string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(segment)
+}
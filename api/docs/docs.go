@@ -0,0 +1,176 @@
+// Package docs - Swagger UI для WorkMate API, полностью из встроенных файлов:
+// спецификация из api/contract, ресурсы интерфейса из swagger-ui-dist
+package docs
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"workmate/api/contract"
+
+	swaggerFiles "github.com/swaggo/files/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// SpecFile - имя спецификации относительно корня документации
+const SpecFile = "workmate.yaml"
+
+// assets - ресурсы swagger-ui-dist, которые нужны странице (index.html и
+// swagger-initializer.js из дистрибутива не отдаются - они открывают демо Petstore)
+var assets = map[string]bool{
+	"swagger-ui.css":                  true,
+	"swagger-ui-bundle.js":            true,
+	"swagger-ui-standalone-preset.js": true,
+	"favicon-16x16.png":               true,
+	"favicon-32x32.png":               true,
+	"oauth2-redirect.html":            true,
+}
+
+// indexHTML - страница Swagger UI; все ресурсы подключаются относительно нее
+const indexHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>WorkMate API Documentation</title>
+    <link rel="stylesheet" type="text/css" href="swagger-ui.css" />
+    <link rel="icon" type="image/png" href="favicon-32x32.png" sizes="32x32" />
+    <link rel="icon" type="image/png" href="favicon-16x16.png" sizes="16x16" />
+    <style>
+        html {
+            box-sizing: border-box;
+            overflow: -moz-scrollbars-vertical;
+            overflow-y: scroll;
+        }
+        *, *:before, *:after {
+            box-sizing: inherit;
+        }
+        body {
+            margin:0;
+            background: #fafafa;
+        }
+    </style>
+</head>
+<body>
+    <div id="swagger-ui"></div>
+    <script src="swagger-ui-bundle.js"></script>
+    <script src="swagger-ui-standalone-preset.js"></script>
+    <script>
+        window.onload = function() {
+            window.ui = SwaggerUIBundle({
+                url: '` + SpecFile + `',
+                dom_id: '#swagger-ui',
+                deepLinking: true,
+                persistAuthorization: true,
+                presets: [
+                    SwaggerUIBundle.presets.apis,
+                    SwaggerUIStandalonePreset
+                ],
+                plugins: [
+                    SwaggerUIBundle.plugins.DownloadUrl
+                ],
+                layout: "StandaloneLayout"
+            });
+        };
+    </script>
+</body>
+</html>
+`
+
+// Handler - обработчик документации, смонтированный по префиксу пути
+type Handler struct {
+	prefix string
+	spec   []byte
+	files  http.Handler
+}
+
+// NewHandler создает обработчик документации. prefix - путь монтирования ("/docs" или "" для корня),
+// serverURL - адрес API для "Try it out": относительный "/api/v1" направляет запросы
+// на тот же origin, с которого открыта документация
+func NewHandler(prefix, serverURL string) (*Handler, error) {
+	spec, err := WithServer(contract.SpecV1(), serverURL)
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{
+		prefix: strings.TrimSuffix(prefix, "/"),
+		spec:   spec,
+		files:  http.FileServer(http.FS(swaggerFiles.FS)),
+	}, nil
+}
+
+// ServeHTTP - Страница, спецификация или ресурс интерфейса
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name, ok := strings.CutPrefix(r.URL.Path, h.prefix)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch name {
+	case "":
+		// Относительные ссылки страницы работают только от пути со слешем
+		http.Redirect(w, r, h.prefix+"/", http.StatusMovedPermanently)
+	case "/", "/index.html":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(indexHTML))
+	case "/" + SpecFile:
+		w.Header().Set("Content-Type", "application/yaml; charset=utf-8")
+		_, _ = w.Write(h.spec)
+	default:
+		if !assets[strings.TrimPrefix(name, "/")] {
+			http.NotFound(w, r)
+			return
+		}
+		r2 := r.Clone(r.Context())
+		r2.URL.Path = name
+		h.files.ServeHTTP(w, r2)
+	}
+}
+
+// WithServer - Спецификация, в которой первым сервером указан serverURL.
+// Остальные серверы сохраняются, чтобы их можно было выбрать в интерфейсе
+func WithServer(spec []byte, serverURL string) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(spec, &doc); err != nil {
+		return nil, fmt.Errorf("parse spec: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("parse spec: document is not a mapping")
+	}
+	root := doc.Content[0]
+
+	server := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+		{Kind: yaml.ScalarNode, Value: "url"},
+		{Kind: yaml.ScalarNode, Value: serverURL},
+		{Kind: yaml.ScalarNode, Value: "description"},
+		{Kind: yaml.ScalarNode, Value: "Текущий сервер"},
+	}}
+
+	servers := -1
+	for i := 0; i < len(root.Content); i += 2 {
+		if root.Content[i].Value == "servers" {
+			servers = i + 1
+		}
+	}
+	if servers < 0 {
+		root.Content = append(root.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: "servers"},
+			&yaml.Node{Kind: yaml.SequenceNode})
+		servers = len(root.Content) - 1
+	}
+	list := root.Content[servers]
+	list.Content = append([]*yaml.Node{server}, list.Content...)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("encode spec: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("encode spec: %w", err)
+	}
+	return buf.Bytes(), nil
+}
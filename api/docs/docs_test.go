@@ -0,0 +1,53 @@
+package docs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"workmate/api/contract"
+)
+
+func TestHandler(t *testing.T) {
+	h, err := NewHandler("/docs", "/api/v1")
+	if err != nil {
+		t.Fatalf("NewHandler() returned error: %v", err)
+	}
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	if rec := get("/docs"); rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != "/docs/" {
+		t.Errorf("Expected redirect to /docs/, got %d %s", rec.Code, rec.Header().Get("Location"))
+	}
+
+	// Страница не ссылается на внешние ресурсы
+	rec := get("/docs/")
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "://") {
+		t.Errorf("Expected offline index page, got %d", rec.Code)
+	}
+
+	for _, asset := range []string{"swagger-ui.css", "swagger-ui-bundle.js", "swagger-ui-standalone-preset.js"} {
+		if rec := get("/docs/" + asset); rec.Code != http.StatusOK || rec.Body.Len() == 0 {
+			t.Errorf("Expected embedded asset %s, got %d", asset, rec.Code)
+		}
+	}
+	if rec := get("/docs/swagger-initializer.js"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for Petstore initializer, got %d", rec.Code)
+	}
+
+	// Спецификация направляет "Try it out" на тот же origin
+	rec = get("/docs/" + SpecFile)
+	spec, err := contract.Load(rec.Body.Bytes())
+	if rec.Code != http.StatusOK || err != nil {
+		t.Fatalf("Expected valid spec, got %d: %v", rec.Code, err)
+	}
+	if _, ok := spec.Operation(http.MethodGet, "/api/v1/tasks"); !ok {
+		t.Error("Expected operations under the relative server /api/v1")
+	}
+	if !strings.Contains(rec.Body.String(), "http://localhost:8080/api/v1") {
+		t.Error("Expected original servers to be kept")
+	}
+}
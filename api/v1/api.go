@@ -13,7 +13,9 @@ package openapi
 
 import (
 	"context"
+	"io"
 	"net/http"
+	"time"
 )
 
 
@@ -23,10 +25,24 @@ import (
 // pass the data to a TasksAPIServicer to perform the required actions, then write the service results to the http response.
 type TasksAPIRouter interface { 
 	GetTasks(http.ResponseWriter, *http.Request)
+	ExportTasks(http.ResponseWriter, *http.Request)
+	ImportTasks(http.ResponseWriter, *http.Request)
 	CreateTask(http.ResponseWriter, *http.Request)
 	GetTask(http.ResponseWriter, *http.Request)
 	DeleteTask(http.ResponseWriter, *http.Request)
 	GetTaskResult(http.ResponseWriter, *http.Request)
+	GetTaskResultContent(http.ResponseWriter, *http.Request)
+	GetTaskHistory(http.ResponseWriter, *http.Request)
+	GetTaskDeliveries(http.ResponseWriter, *http.Request)
+	ListNamespaces(http.ResponseWriter, *http.Request)
+	PurgeNamespace(http.ResponseWriter, *http.Request)
+	GetUsage(http.ResponseWriter, *http.Request)
+	CancelTask(http.ResponseWriter, *http.Request)
+	PauseTask(http.ResponseWriter, *http.Request)
+	ResumeTask(http.ResponseWriter, *http.Request)
+	GetDispatch(http.ResponseWriter, *http.Request)
+	PauseDispatch(http.ResponseWriter, *http.Request)
+	ResumeDispatch(http.ResponseWriter, *http.Request)
 }
 
 
@@ -35,9 +51,23 @@ type TasksAPIRouter interface {
 // while the service implementation can be ignored with the .openapi-generator-ignore file
 // and updated with the logic required for the API.
 type TasksAPIServicer interface { 
-	GetTasks(context.Context) (ImplResponse, error)
+	GetTasks(context.Context, []string, time.Time, time.Time, int32) (ImplResponse, error)
+	ExportTasks(context.Context, []string, time.Time, time.Time, int32, string, []string) (ImplResponse, error)
+	ImportTasks(context.Context, string, bool, io.Reader) (ImplResponse, error)
 	CreateTask(context.Context, CreateTaskRequest) (ImplResponse, error)
-	GetTask(context.Context, string) (ImplResponse, error)
-	DeleteTask(context.Context, string) (ImplResponse, error)
-	GetTaskResult(context.Context, string) (ImplResponse, error)
+	GetTask(context.Context, string, string) (ImplResponse, error)
+	DeleteTask(context.Context, string, string) (ImplResponse, error)
+	GetTaskResult(context.Context, string, string) (ImplResponse, error)
+	GetTaskResultContent(context.Context, string) (ImplResponse, error)
+	GetTaskHistory(context.Context, string) (ImplResponse, error)
+	GetTaskDeliveries(context.Context, string) (ImplResponse, error)
+	ListNamespaces(context.Context) (ImplResponse, error)
+	PurgeNamespace(context.Context, string) (ImplResponse, error)
+	GetUsage(context.Context, time.Time, time.Time, string) (ImplResponse, error)
+	CancelTask(context.Context, string) (ImplResponse, error)
+	PauseTask(context.Context, string) (ImplResponse, error)
+	ResumeTask(context.Context, string) (ImplResponse, error)
+	GetDispatch(context.Context) (ImplResponse, error)
+	PauseDispatch(context.Context) (ImplResponse, error)
+	ResumeDispatch(context.Context) (ImplResponse, error)
 }
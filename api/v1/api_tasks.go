@@ -15,6 +15,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 )
@@ -58,6 +59,18 @@ func (c *TasksAPIController) Routes() Routes {
 			"/api/v1/tasks",
 			c.GetTasks,
 		},
+		"ExportTasks": Route{
+			"ExportTasks",
+			strings.ToUpper("Get"),
+			"/api/v1/tasks/export",
+			c.ExportTasks,
+		},
+		"ImportTasks": Route{
+			"ImportTasks",
+			strings.ToUpper("Post"),
+			"/api/v1/tasks/import",
+			c.ImportTasks,
+		},
 		"CreateTask": Route{
 			"CreateTask",
 			strings.ToUpper("Post"),
@@ -82,6 +95,78 @@ func (c *TasksAPIController) Routes() Routes {
 			"/api/v1/tasks/{taskId}/result",
 			c.GetTaskResult,
 		},
+		"GetTaskResultContent": Route{
+			"GetTaskResultContent",
+			strings.ToUpper("Get"),
+			"/api/v1/tasks/{taskId}/result/content",
+			c.GetTaskResultContent,
+		},
+		"GetTaskHistory": Route{
+			"GetTaskHistory",
+			strings.ToUpper("Get"),
+			"/api/v1/tasks/{taskId}/history",
+			c.GetTaskHistory,
+		},
+		"GetTaskDeliveries": Route{
+			"GetTaskDeliveries",
+			strings.ToUpper("Get"),
+			"/api/v1/tasks/{taskId}/deliveries",
+			c.GetTaskDeliveries,
+		},
+		"ListNamespaces": Route{
+			"ListNamespaces",
+			strings.ToUpper("Get"),
+			"/api/v1/namespaces",
+			c.ListNamespaces,
+		},
+		"PurgeNamespace": Route{
+			"PurgeNamespace",
+			strings.ToUpper("Delete"),
+			"/api/v1/namespaces/{namespace}",
+			c.PurgeNamespace,
+		},
+		"GetUsage": Route{
+			"GetUsage",
+			strings.ToUpper("Get"),
+			"/api/v1/usage",
+			c.GetUsage,
+		},
+		"CancelTask": Route{
+			"CancelTask",
+			strings.ToUpper("Post"),
+			"/api/v1/tasks/{taskId}/cancel",
+			c.CancelTask,
+		},
+		"PauseTask": Route{
+			"PauseTask",
+			strings.ToUpper("Post"),
+			"/api/v1/tasks/{taskId}/pause",
+			c.PauseTask,
+		},
+		"ResumeTask": Route{
+			"ResumeTask",
+			strings.ToUpper("Post"),
+			"/api/v1/tasks/{taskId}/resume",
+			c.ResumeTask,
+		},
+		"GetDispatch": Route{
+			"GetDispatch",
+			strings.ToUpper("Get"),
+			"/api/v1/dispatch",
+			c.GetDispatch,
+		},
+		"PauseDispatch": Route{
+			"PauseDispatch",
+			strings.ToUpper("Post"),
+			"/api/v1/dispatch/pause",
+			c.PauseDispatch,
+		},
+		"ResumeDispatch": Route{
+			"ResumeDispatch",
+			strings.ToUpper("Post"),
+			"/api/v1/dispatch/resume",
+			c.ResumeDispatch,
+		},
 	}
 }
 
@@ -94,6 +179,18 @@ func (c *TasksAPIController) OrderedRoutes() []Route {
 			"/api/v1/tasks",
 			c.GetTasks,
 		},
+		Route{
+			"ExportTasks",
+			strings.ToUpper("Get"),
+			"/api/v1/tasks/export",
+			c.ExportTasks,
+		},
+		Route{
+			"ImportTasks",
+			strings.ToUpper("Post"),
+			"/api/v1/tasks/import",
+			c.ImportTasks,
+		},
 		Route{
 			"CreateTask",
 			strings.ToUpper("Post"),
@@ -118,6 +215,78 @@ func (c *TasksAPIController) OrderedRoutes() []Route {
 			"/api/v1/tasks/{taskId}/result",
 			c.GetTaskResult,
 		},
+		Route{
+			"GetTaskResultContent",
+			strings.ToUpper("Get"),
+			"/api/v1/tasks/{taskId}/result/content",
+			c.GetTaskResultContent,
+		},
+		Route{
+			"GetTaskHistory",
+			strings.ToUpper("Get"),
+			"/api/v1/tasks/{taskId}/history",
+			c.GetTaskHistory,
+		},
+		Route{
+			"GetTaskDeliveries",
+			strings.ToUpper("Get"),
+			"/api/v1/tasks/{taskId}/deliveries",
+			c.GetTaskDeliveries,
+		},
+		Route{
+			"ListNamespaces",
+			strings.ToUpper("Get"),
+			"/api/v1/namespaces",
+			c.ListNamespaces,
+		},
+		Route{
+			"PurgeNamespace",
+			strings.ToUpper("Delete"),
+			"/api/v1/namespaces/{namespace}",
+			c.PurgeNamespace,
+		},
+		Route{
+			"GetUsage",
+			strings.ToUpper("Get"),
+			"/api/v1/usage",
+			c.GetUsage,
+		},
+		Route{
+			"CancelTask",
+			strings.ToUpper("Post"),
+			"/api/v1/tasks/{taskId}/cancel",
+			c.CancelTask,
+		},
+		Route{
+			"PauseTask",
+			strings.ToUpper("Post"),
+			"/api/v1/tasks/{taskId}/pause",
+			c.PauseTask,
+		},
+		Route{
+			"ResumeTask",
+			strings.ToUpper("Post"),
+			"/api/v1/tasks/{taskId}/resume",
+			c.ResumeTask,
+		},
+		Route{
+			"GetDispatch",
+			strings.ToUpper("Get"),
+			"/api/v1/dispatch",
+			c.GetDispatch,
+		},
+		Route{
+			"PauseDispatch",
+			strings.ToUpper("Post"),
+			"/api/v1/dispatch/pause",
+			c.PauseDispatch,
+		},
+		Route{
+			"ResumeDispatch",
+			strings.ToUpper("Post"),
+			"/api/v1/dispatch/resume",
+			c.ResumeDispatch,
+		},
 	}
 }
 
@@ -125,14 +294,173 @@ func (c *TasksAPIController) OrderedRoutes() []Route {
 
 // GetTasks - Получить список всех задач
 func (c *TasksAPIController) GetTasks(w http.ResponseWriter, r *http.Request) {
-	result, err := c.service.GetTasks(r.Context())
+	query, err := parseQuery(r.URL.RawQuery)
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	var statusParam []string
+	if query.Has("status") {
+		statusParam = strings.Split(query.Get("status"), ",")
+	}
+	var createdAfterParam time.Time
+	if query.Has("createdAfter"){
+		param, err := parseTime(query.Get("createdAfter"))
+		if err != nil {
+			c.errorHandler(w, r, &ParsingError{Param: "createdAfter", Err: err}, nil)
+			return
+		}
+
+		createdAfterParam = param
+	} else {
+	}
+	var createdBeforeParam time.Time
+	if query.Has("createdBefore"){
+		param, err := parseTime(query.Get("createdBefore"))
+		if err != nil {
+			c.errorHandler(w, r, &ParsingError{Param: "createdBefore", Err: err}, nil)
+			return
+		}
+
+		createdBeforeParam = param
+	} else {
+	}
+	var limitParam int32
+	if query.Has("limit") {
+		param, err := parseNumericParameter[int32](
+			query.Get("limit"),
+			WithParse[int32](parseInt32),
+			WithMinimum[int32](1),
+			WithMaximum[int32](1000),
+		)
+		if err != nil {
+			c.errorHandler(w, r, &ParsingError{Param: "limit", Err: err}, nil)
+			return
+		}
+
+		limitParam = param
+	} else {
+	}
+	result, err := c.service.GetTasks(r.Context(), statusParam, createdAfterParam, createdBeforeParam, limitParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+}
+
+// ExportTasks - Выгрузить задачи потоком
+func (c *TasksAPIController) ExportTasks(w http.ResponseWriter, r *http.Request) {
+	query, err := parseQuery(r.URL.RawQuery)
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	var statusParam []string
+	if query.Has("status") {
+		statusParam = strings.Split(query.Get("status"), ",")
+	}
+	var createdAfterParam time.Time
+	if query.Has("createdAfter"){
+		param, err := parseTime(query.Get("createdAfter"))
+		if err != nil {
+			c.errorHandler(w, r, &ParsingError{Param: "createdAfter", Err: err}, nil)
+			return
+		}
+
+		createdAfterParam = param
+	} else {
+	}
+	var createdBeforeParam time.Time
+	if query.Has("createdBefore"){
+		param, err := parseTime(query.Get("createdBefore"))
+		if err != nil {
+			c.errorHandler(w, r, &ParsingError{Param: "createdBefore", Err: err}, nil)
+			return
+		}
+
+		createdBeforeParam = param
+	} else {
+	}
+	var limitParam int32
+	if query.Has("limit") {
+		param, err := parseNumericParameter[int32](
+			query.Get("limit"),
+			WithParse[int32](parseInt32),
+			WithMinimum[int32](1),
+		)
+		if err != nil {
+			c.errorHandler(w, r, &ParsingError{Param: "limit", Err: err}, nil)
+			return
+		}
+
+		limitParam = param
+	} else {
+	}
+	var formatParam string
+	if query.Has("format") {
+		param := query.Get("format")
+
+		formatParam = param
+	} else {
+		formatParam = NegotiateExportFormat(r.Header.Get("Accept"))
+	}
+	var columnsParam []string
+	if query.Has("columns") {
+		columnsParam = strings.Split(query.Get("columns"), ",")
+	}
+	result, err := c.service.ExportTasks(r.Context(), statusParam, createdAfterParam, createdBeforeParam, limitParam, formatParam, columnsParam)
 	// If an error occurred, encode the error with the status code
 	if err != nil {
 		c.errorHandler(w, r, err, &result)
 		return
 	}
 	// If no error, encode the body and the result code
-	_ = EncodeJSONResponse(result.Body, &result.Code, w)
+	_ = EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+}
+
+// ImportTasks - Импортировать задачи из выгрузки
+func (c *TasksAPIController) ImportTasks(w http.ResponseWriter, r *http.Request) {
+	query, err := parseQuery(r.URL.RawQuery)
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	var conflictParam string
+	if query.Has("conflict") {
+		param := query.Get("conflict")
+
+		conflictParam = param
+	} else {
+		param := "fail"
+		conflictParam = param
+	}
+	var requeueParam bool
+	if query.Has("requeue") {
+		param, err := parseBoolParameter(
+			query.Get("requeue"),
+			WithParse[bool](parseBool),
+		)
+		if err != nil {
+			c.errorHandler(w, r, &ParsingError{Param: "requeue", Err: err}, nil)
+			return
+		}
+
+		requeueParam = param
+	} else {
+		var param bool = false
+		requeueParam = param
+	}
+	result, err := c.service.ImportTasks(r.Context(), conflictParam, requeueParam, r.Body)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
 }
 
 // CreateTask - Создать новую задачу
@@ -159,7 +487,7 @@ func (c *TasksAPIController) CreateTask(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	// If no error, encode the body and the result code
-	_ = EncodeJSONResponse(result.Body, &result.Code, w)
+	_ = EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
 }
 
 // GetTask - Получить информацию о задаче
@@ -170,14 +498,15 @@ func (c *TasksAPIController) GetTask(w http.ResponseWriter, r *http.Request) {
 		c.errorHandler(w, r, &RequiredError{"taskId"}, nil)
 		return
 	}
-	result, err := c.service.GetTask(r.Context(), taskIdParam)
+	ifNoneMatchParam := r.Header.Get("If-None-Match")
+	result, err := c.service.GetTask(r.Context(), taskIdParam, ifNoneMatchParam)
 	// If an error occurred, encode the error with the status code
 	if err != nil {
 		c.errorHandler(w, r, err, &result)
 		return
 	}
 	// If no error, encode the body and the result code
-	_ = EncodeJSONResponse(result.Body, &result.Code, w)
+	_ = EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
 }
 
 // DeleteTask - Удалить задачу
@@ -188,14 +517,15 @@ func (c *TasksAPIController) DeleteTask(w http.ResponseWriter, r *http.Request)
 		c.errorHandler(w, r, &RequiredError{"taskId"}, nil)
 		return
 	}
-	result, err := c.service.DeleteTask(r.Context(), taskIdParam)
+	ifMatchParam := r.Header.Get("If-Match")
+	result, err := c.service.DeleteTask(r.Context(), taskIdParam, ifMatchParam)
 	// If an error occurred, encode the error with the status code
 	if err != nil {
 		c.errorHandler(w, r, err, &result)
 		return
 	}
 	// If no error, encode the body and the result code
-	_ = EncodeJSONResponse(result.Body, &result.Code, w)
+	_ = EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
 }
 
 // GetTaskResult - Получить результат задачи
@@ -206,12 +536,233 @@ func (c *TasksAPIController) GetTaskResult(w http.ResponseWriter, r *http.Reques
 		c.errorHandler(w, r, &RequiredError{"taskId"}, nil)
 		return
 	}
-	result, err := c.service.GetTaskResult(r.Context(), taskIdParam)
+	ifNoneMatchParam := r.Header.Get("If-None-Match")
+	result, err := c.service.GetTaskResult(r.Context(), taskIdParam, ifNoneMatchParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+}
+
+// GetTaskResultContent - Получить выходные данные задачи
+func (c *TasksAPIController) GetTaskResultContent(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	taskIdParam := params["taskId"]
+	if taskIdParam == "" {
+		c.errorHandler(w, r, &RequiredError{"taskId"}, nil)
+		return
+	}
+	result, err := c.service.GetTaskResultContent(r.Context(), taskIdParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, serve the content (Range, conditional requests) or encode the problem
+	_ = EncodeContentResponse(result, w, r)
+}
+
+// GetTaskHistory - Получить историю переходов статуса задачи
+func (c *TasksAPIController) GetTaskHistory(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	taskIdParam := params["taskId"]
+	if taskIdParam == "" {
+		c.errorHandler(w, r, &RequiredError{"taskId"}, nil)
+		return
+	}
+	result, err := c.service.GetTaskHistory(r.Context(), taskIdParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+}
+
+// GetTaskDeliveries - Получить историю доставки webhook
+func (c *TasksAPIController) GetTaskDeliveries(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	taskIdParam := params["taskId"]
+	if taskIdParam == "" {
+		c.errorHandler(w, r, &RequiredError{"taskId"}, nil)
+		return
+	}
+	result, err := c.service.GetTaskDeliveries(r.Context(), taskIdParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+}
+
+// ListNamespaces - Получить список пространств имен
+func (c *TasksAPIController) ListNamespaces(w http.ResponseWriter, r *http.Request) {
+	result, err := c.service.ListNamespaces(r.Context())
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+}
+
+// PurgeNamespace - Очистить пространство имен
+func (c *TasksAPIController) PurgeNamespace(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	namespaceParam := params["namespace"]
+	if namespaceParam == "" {
+		c.errorHandler(w, r, &RequiredError{"namespace"}, nil)
+		return
+	}
+	result, err := c.service.PurgeNamespace(r.Context(), namespaceParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+}
+
+// GetUsage - Получить потребление по принципалам за окно
+func (c *TasksAPIController) GetUsage(w http.ResponseWriter, r *http.Request) {
+	query, err := parseQuery(r.URL.RawQuery)
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	var fromParam time.Time
+	if query.Has("from"){
+		param, err := parseTime(query.Get("from"))
+		if err != nil {
+			c.errorHandler(w, r, &ParsingError{Param: "from", Err: err}, nil)
+			return
+		}
+
+		fromParam = param
+	} else {
+	}
+	var toParam time.Time
+	if query.Has("to"){
+		param, err := parseTime(query.Get("to"))
+		if err != nil {
+			c.errorHandler(w, r, &ParsingError{Param: "to", Err: err}, nil)
+			return
+		}
+
+		toParam = param
+	} else {
+	}
+	var principalParam string
+	if query.Has("principal") {
+		param := query.Get("principal")
+
+		principalParam = param
+	} else {
+	}
+	result, err := c.service.GetUsage(r.Context(), fromParam, toParam, principalParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+}
+
+// CancelTask - Отменить задачу
+func (c *TasksAPIController) CancelTask(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	taskIdParam := params["taskId"]
+	if taskIdParam == "" {
+		c.errorHandler(w, r, &RequiredError{"taskId"}, nil)
+		return
+	}
+	result, err := c.service.CancelTask(r.Context(), taskIdParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+}
+
+// PauseTask - Приостановить задачу
+func (c *TasksAPIController) PauseTask(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	taskIdParam := params["taskId"]
+	if taskIdParam == "" {
+		c.errorHandler(w, r, &RequiredError{"taskId"}, nil)
+		return
+	}
+	result, err := c.service.PauseTask(r.Context(), taskIdParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+}
+
+// ResumeTask - Возобновить задачу
+func (c *TasksAPIController) ResumeTask(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	taskIdParam := params["taskId"]
+	if taskIdParam == "" {
+		c.errorHandler(w, r, &RequiredError{"taskId"}, nil)
+		return
+	}
+	result, err := c.service.ResumeTask(r.Context(), taskIdParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+}
+
+// GetDispatch - Получить состояние запуска задач
+func (c *TasksAPIController) GetDispatch(w http.ResponseWriter, r *http.Request) {
+	result, err := c.service.GetDispatch(r.Context())
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+}
+
+// PauseDispatch - Остановить запуск задач
+func (c *TasksAPIController) PauseDispatch(w http.ResponseWriter, r *http.Request) {
+	result, err := c.service.PauseDispatch(r.Context())
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	_ = EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+}
+
+// ResumeDispatch - Возобновить запуск задач
+func (c *TasksAPIController) ResumeDispatch(w http.ResponseWriter, r *http.Request) {
+	result, err := c.service.ResumeDispatch(r.Context())
 	// If an error occurred, encode the error with the status code
 	if err != nil {
 		c.errorHandler(w, r, err, &result)
 		return
 	}
 	// If no error, encode the body and the result code
-	_ = EncodeJSONResponse(result.Body, &result.Code, w)
+	_ = EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
 }
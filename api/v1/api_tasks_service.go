@@ -13,10 +13,20 @@ package openapi
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
 	"workmate/internal"
 	"workmate/pkg"
 )
 
+// admissionRetryAfter - через сколько секунд клиенту предлагается повторить создание задачи
+// при превышении лимита активных задач
+const admissionRetryAfter = 30
+
 // TasksAPIService is a service that implements the logic for the TasksAPIServicer
 // This service should implement the business logic for every endpoint for the TasksAPI API.
 // Include any external packages or services that will be required by this service.
@@ -25,71 +35,240 @@ type TasksAPIService struct {
 }
 
 // NewTasksAPIService creates a default api service
-func NewTasksAPIService() *TasksAPIService {
+func NewTasksAPIService(opts ...internal.ServiceOption) *TasksAPIService {
+	// Webhook отправляет ту же TaskResponse, что возвращает GetTask
+	opts = append([]internal.ServiceOption{internal.WithWebhookEncoder(encodeTaskWebhook)}, opts...)
 	return &TasksAPIService{
-		service: internal.NewService(),
+		service: internal.NewService(opts...),
 	}
 }
 
+// encodeTaskWebhook - Тело webhook о завершении задачи (продолжительность - до завершения)
+func encodeTaskWebhook(task pkg.InternalTask) ([]byte, error) {
+	return json.Marshal(TaskResponse{Task: MapInternalTaskToAPI(task, task.FinishedAt)})
+}
+
 // GetTasks - Получить список всех задач
-func (s *TasksAPIService) GetTasks(ctx context.Context) (ImplResponse, error) {
-	tasks, err := s.service.GetTasks(ctx)
+func (s *TasksAPIService) GetTasks(ctx context.Context, status []string, createdAfter time.Time, createdBefore time.Time, limit int32) (ImplResponse, error) {
+	tasks, err := s.service.GetTasks(ctx, pkg.TaskFilter{
+		Statuses:      status,
+		CreatedAfter:  createdAfter,
+		CreatedBefore: createdBefore,
+		Limit:         int(limit),
+	})
 	if err != nil {
-		return Response(500, ErrorResponse{Error: err.Error()}), nil
+		return ProblemResponse(ctx, err), nil
 	}
 
-	apiTasks := MapInternalTasksToAPI(tasks)
+	apiTasks := MapInternalTasksToAPI(tasks, s.service.Now())
 	return Response(200, TaskListResponse{Tasks: apiTasks}), nil
 }
 
+// ExportTasks - Выгрузить задачи потоком в NDJSON или CSV. Параметры проверяются до начала ответа,
+// сами задачи читаются из хранилища уже при записи тела
+func (s *TasksAPIService) ExportTasks(ctx context.Context, status []string, createdAfter time.Time, createdBefore time.Time, limit int32, format string, columns []string) (ImplResponse, error) {
+	filter := pkg.TaskFilter{
+		Statuses:      status,
+		CreatedAfter:  createdAfter,
+		CreatedBefore: createdBefore,
+		Limit:         int(limit),
+	}
+	if err := filter.Validate(); err != nil {
+		return ProblemResponse(ctx, err), nil
+	}
+	export, err := newTaskExport(ctx, s.service, filter, format, columns)
+	if err != nil {
+		return ProblemResponse(ctx, err), nil
+	}
+	return ResponseWithHeaders(200, map[string][]string{
+		"Content-Disposition": {"attachment; filename=tasks." + format},
+	}, export), nil
+}
+
+// ImportTasks - Импортировать задачи из NDJSON выгрузки. Ошибки записей возвращаются Problem
+// с нарушением на каждую запись; в этом случае ничего не импортируется
+func (s *TasksAPIService) ImportTasks(ctx context.Context, conflict string, requeue bool, body io.Reader) (ImplResponse, error) {
+	tasks, lines, violations, err := decodeImport(body)
+	if err != nil {
+		return ProblemResponse(ctx, err), nil
+	}
+	if len(violations) > 0 {
+		problem := NewProblem(ctx, fmt.Errorf("%w: %d of the lines are not task records", pkg.ErrInvalidTaskRecord, len(violations)))
+		problem.Violations = violations
+		return Response(int(problem.Status), problem), nil
+	}
+
+	results, err := s.service.ImportTasks(ctx, tasks, internal.ImportOptions{Conflict: conflict, Requeue: requeue})
+	var records *pkg.RecordErrors
+	if errors.As(err, &records) {
+		problem := NewProblem(ctx, err)
+		problem.Violations = recordViolations(records, lines)
+		return Response(int(problem.Status), problem), nil
+	}
+	if err != nil {
+		return ProblemResponse(ctx, err), nil
+	}
+
+	return Response(200, MapImportResultsToAPI(results, lines)), nil
+}
+
 // CreateTask - Создать новую задачу
 func (s *TasksAPIService) CreateTask(ctx context.Context, createTaskRequest CreateTaskRequest) (ImplResponse, error) {
-	task, err := s.service.CreateTask(ctx, createTaskRequest.Name)
+	task, err := s.service.CreateTask(ctx, createTaskRequest.Name,
+		pkg.WithCallback(createTaskRequest.CallbackUrl, createTaskRequest.CallbackEvents))
 	if err != nil {
-		if err.Error() == pkg.TaskErrorNameRequired {
-			return Response(400, ErrorResponse{Error: err.Error()}), nil
+		resp := ProblemResponse(ctx, err)
+		if errors.Is(err, pkg.ErrTooManyTasks) || errors.Is(err, pkg.ErrQuotaExceeded) || errors.Is(err, pkg.ErrPrincipalQuotaExceeded) {
+			resp.Headers = map[string][]string{"Retry-After": {strconv.Itoa(admissionRetryAfter)}}
 		}
-		return Response(500, ErrorResponse{Error: err.Error()}), nil
+		return resp, nil
 	}
 
-	apiTask := MapInternalTaskToAPI(task)
-	return Response(201, TaskResponse{Task: apiTask}), nil
+	return taskResponse(201, task, s.service.Now(), ""), nil
 }
 
 // GetTask - Получить информацию о задаче
-func (s *TasksAPIService) GetTask(ctx context.Context, taskId string) (ImplResponse, error) {
+func (s *TasksAPIService) GetTask(ctx context.Context, taskId string, ifNoneMatch string) (ImplResponse, error) {
 	task, err := s.service.GetTask(ctx, taskId)
 	if err != nil {
-		return Response(404, ErrorResponse{Error: err.Error()}), nil
+		return ProblemResponse(ctx, err), nil
 	}
 
-	apiTask := MapInternalTaskToAPI(task)
-	return Response(200, TaskResponse{Task: apiTask}), nil
+	return taskResponse(200, task, s.service.Now(), ifNoneMatch), nil
 }
 
 // DeleteTask - Удалить задачу
-func (s *TasksAPIService) DeleteTask(ctx context.Context, taskId string) (ImplResponse, error) {
-	err := s.service.DeleteTask(ctx, taskId)
+func (s *TasksAPIService) DeleteTask(ctx context.Context, taskId string, ifMatchHeader string) (ImplResponse, error) {
+	err := s.service.DeleteTaskIfMatch(ctx, taskId, ifMatch(ifMatchHeader))
 	if err != nil {
-		return Response(404, ErrorResponse{Error: err.Error()}), nil
+		return ProblemResponse(ctx, err), nil
 	}
 
 	return Response(204, nil), nil
 }
 
 // GetTaskResult - Получить результат задачи
-func (s *TasksAPIService) GetTaskResult(ctx context.Context, taskId string) (ImplResponse, error) {
+func (s *TasksAPIService) GetTaskResult(ctx context.Context, taskId string, ifNoneMatch string) (ImplResponse, error) {
 	task, err := s.service.GetTaskResult(ctx, taskId)
 	if err != nil {
-		if err.Error() == pkg.TaskErrorNotFound {
-			return Response(404, ErrorResponse{Error: err.Error()}), nil
-		}
-		if err.Error() == pkg.TaskErrorNotCompleted {
-			return Response(425, ErrorResponse{Error: err.Error()}), nil
-		}
-		return Response(500, ErrorResponse{Error: err.Error()}), nil
+		return ProblemResponse(ctx, err), nil
+	}
+
+	return taskResponse(200, task, s.service.Now(), ifNoneMatch), nil
+}
+
+// GetTaskResultContent - Получить выходные данные задачи потоком: Content-Type и Content-Length
+// из хранилища результатов, Range и условные запросы по ETag (контрольной сумме)
+func (s *TasksAPIService) GetTaskResultContent(ctx context.Context, taskId string) (ImplResponse, error) {
+	task, content, err := s.service.OpenResult(ctx, taskId)
+	if err != nil {
+		return ProblemResponse(ctx, err), nil
+	}
+
+	return ResponseWithHeaders(200, contentHeaders(*task.Output), &ContentBody{
+		Content:     content,
+		ContentType: task.Output.ContentType,
+		ModTime:     task.Output.CreatedAt,
+	}), nil
+}
+
+// GetTaskHistory - Получить историю переходов статуса задачи
+func (s *TasksAPIService) GetTaskHistory(ctx context.Context, taskId string) (ImplResponse, error) {
+	history, err := s.service.GetTaskHistory(ctx, taskId)
+	if err != nil {
+		return ProblemResponse(ctx, err), nil
+	}
+
+	return Response(200, TaskHistoryResponse{History: MapTaskHistoryToAPI(history)}), nil
+}
+
+// GetTaskDeliveries - Получить историю доставки webhook
+func (s *TasksAPIService) GetTaskDeliveries(ctx context.Context, taskId string) (ImplResponse, error) {
+	deliveries, err := s.service.GetTaskDeliveries(ctx, taskId)
+	if err != nil {
+		return ProblemResponse(ctx, err), nil
+	}
+
+	return Response(200, DeliveryListResponse{Deliveries: MapWebhookDeliveriesToAPI(deliveries)}), nil
+}
+
+// ListNamespaces - Получить список пространств имен
+func (s *TasksAPIService) ListNamespaces(ctx context.Context) (ImplResponse, error) {
+	namespaces := s.service.ListNamespaces(ctx)
+	return Response(200, NamespaceListResponse{Namespaces: MapNamespacesToAPI(namespaces)}), nil
+}
+
+// PurgeNamespace - Очистить пространство имен
+func (s *TasksAPIService) PurgeNamespace(ctx context.Context, namespace string) (ImplResponse, error) {
+	deleted, err := s.service.PurgeNamespace(ctx, namespace)
+	if err != nil {
+		return ProblemResponse(ctx, err), nil
+	}
+
+	return Response(200, NamespacePurgeReport{Namespace: namespace, Deleted: int32(deleted)}), nil
+}
+
+// GetUsage - Получить потребление по принципалам за окно [from, to)
+func (s *TasksAPIService) GetUsage(ctx context.Context, from time.Time, to time.Time, principal string) (ImplResponse, error) {
+	report, err := s.service.Usage(ctx, from, to, principal)
+	if err != nil {
+		return ProblemResponse(ctx, err), nil
+	}
+
+	return Response(200, UsageReport{From: report.From, To: report.To, Usage: MapUsageToAPI(report.Usage)}), nil
+}
+
+// CancelTask - Отменить задачу
+func (s *TasksAPIService) CancelTask(ctx context.Context, taskId string) (ImplResponse, error) {
+	task, err := s.service.CancelTask(ctx, taskId)
+	if err != nil {
+		return ProblemResponse(ctx, err), nil
+	}
+
+	return taskResponse(200, task, s.service.Now(), ""), nil
+}
+
+// PauseTask - Приостановить задачу
+func (s *TasksAPIService) PauseTask(ctx context.Context, taskId string) (ImplResponse, error) {
+	task, err := s.service.PauseTask(ctx, taskId)
+	if err != nil {
+		return ProblemResponse(ctx, err), nil
+	}
+
+	return taskResponse(200, task, s.service.Now(), ""), nil
+}
+
+// ResumeTask - Возобновить задачу
+func (s *TasksAPIService) ResumeTask(ctx context.Context, taskId string) (ImplResponse, error) {
+	task, err := s.service.ResumeTask(ctx, taskId)
+	if err != nil {
+		return ProblemResponse(ctx, err), nil
+	}
+
+	return taskResponse(200, task, s.service.Now(), ""), nil
+}
+
+// GetDispatch - Получить состояние запуска задач
+func (s *TasksAPIService) GetDispatch(ctx context.Context) (ImplResponse, error) {
+	return dispatchResponse(ctx, s.service.DispatchState)
+}
+
+// PauseDispatch - Остановить запуск задач
+func (s *TasksAPIService) PauseDispatch(ctx context.Context) (ImplResponse, error) {
+	return dispatchResponse(ctx, s.service.PauseDispatch)
+}
+
+// ResumeDispatch - Возобновить запуск задач
+func (s *TasksAPIService) ResumeDispatch(ctx context.Context) (ImplResponse, error) {
+	return dispatchResponse(ctx, s.service.ResumeDispatch)
+}
+
+// dispatchResponse - Ответ с состоянием запуска задач после операции op
+func dispatchResponse(ctx context.Context, op func(ctx context.Context) (internal.DispatchState, error)) (ImplResponse, error) {
+	state, err := op(ctx)
+	if err != nil {
+		return ProblemResponse(ctx, err), nil
 	}
 
-	apiTask := MapInternalTaskToAPI(task)
-	return Response(200, TaskResponse{Task: apiTask}), nil
+	return Response(200, MapDispatchStateToAPI(state)), nil
 }
@@ -3,6 +3,7 @@ package openapi
 import (
 	"context"
 	"testing"
+	"time"
 	"workmate/pkg"
 )
 
@@ -15,12 +16,15 @@ func assertResponseCode(t *testing.T, expected, actual int) {
 
 // Вспомогательная функция для проверки ошибки
 func assertError(t *testing.T, expected string, resp ImplResponse) {
-	errResp, ok := resp.Body.(ErrorResponse)
+	problem, ok := resp.Body.(Problem)
 	if !ok {
-		t.Fatalf("Expected ErrorResponse, got %T", resp.Body)
+		t.Fatalf("Expected Problem, got %T", resp.Body)
 	}
-	if errResp.Error != expected {
-		t.Errorf("Expected error '%s', got '%s'", expected, errResp.Error)
+	if problem.Detail != expected {
+		t.Errorf("Expected error '%s', got '%s'", expected, problem.Detail)
+	}
+	if int(problem.Status) != resp.Code {
+		t.Errorf("Expected problem status %d, got %d", resp.Code, problem.Status)
 	}
 }
 
@@ -62,7 +66,7 @@ func TestGetTask(t *testing.T) {
 	taskId := taskResp.Task.Id
 
 	// Тест на получение существующей задачи
-	resp, err := service.GetTask(ctx, taskId)
+	resp, err := service.GetTask(ctx, taskId, "")
 	if err != nil {
 		t.Fatalf("GetTask() returned error: %v", err)
 	}
@@ -77,7 +81,7 @@ func TestGetTask(t *testing.T) {
 	}
 
 	// Тест на получение несуществующей задачи
-	resp, err = service.GetTask(ctx, "non-existent-id")
+	resp, err = service.GetTask(ctx, "non-existent-id", "")
 	if err != nil {
 		t.Fatalf("GetTask() returned error: %v", err)
 	}
@@ -85,12 +89,29 @@ func TestGetTask(t *testing.T) {
 	assertError(t, pkg.TaskErrorNotFound, resp)
 }
 
+func TestMapInternalTaskToAPI(t *testing.T) {
+	start := time.Date(2024, 1, 15, 15, 4, 5, 0, time.UTC)
+	task := pkg.InternalTask{Id: "task", Name: "Test Task", Status: pkg.TaskStatusRunning, CreatedAt: start, StartedAt: start}
+
+	// Продолжительность выполняющейся задачи считается до момента ответа
+	apiTask := MapInternalTaskToAPI(task, start.Add(90*time.Second+400*time.Millisecond))
+	if apiTask.Duration != "1m30s" || apiTask.DurationMs != 90400 {
+		t.Errorf("Expected duration 1m30s (90400 ms), got %s (%d ms)", apiTask.Duration, apiTask.DurationMs)
+	}
+
+	// Ожидающая задача без продолжительности
+	task.StartedAt = time.Time{}
+	if apiTask := MapInternalTaskToAPI(task, start.Add(time.Hour)); apiTask.Duration != "" || apiTask.DurationMs != 0 {
+		t.Errorf("Expected no duration for a pending task, got %s (%d ms)", apiTask.Duration, apiTask.DurationMs)
+	}
+}
+
 func TestGetTasks(t *testing.T) {
 	service := NewTasksAPIService()
 	ctx := context.Background()
 
 	// Проверяем, что изначально список пуст
-	resp, err := service.GetTasks(ctx)
+	resp, err := service.GetTasks(ctx, nil, time.Time{}, time.Time{}, 0)
 	if err != nil {
 		t.Fatalf("GetTasks() returned error: %v", err)
 	}
@@ -108,7 +129,7 @@ func TestGetTasks(t *testing.T) {
 	service.CreateTask(ctx, CreateTaskRequest{Name: "Task 2"})
 
 	// Проверяем, что все задачи возвращаются
-	resp, err = service.GetTasks(ctx)
+	resp, err = service.GetTasks(ctx, nil, time.Time{}, time.Time{}, 0)
 	if err != nil {
 		t.Fatalf("GetTasks() returned error: %v", err)
 	}
@@ -132,19 +153,26 @@ func TestDeleteTask(t *testing.T) {
 	taskResp := createResp.Body.(TaskResponse)
 	taskId := taskResp.Task.Id
 
+	// Задача с другой версией не удаляется
+	resp, _ := service.DeleteTask(ctx, taskId, `"0"`)
+	assertResponseCode(t, 412, resp.Code)
+	if problem := resp.Body.(Problem); problem.Code != pkg.ErrVersionConflict.Code {
+		t.Errorf("Expected code '%s', got '%s'", pkg.ErrVersionConflict.Code, problem.Code)
+	}
+
 	// Тест на удаление существующей задачи
-	resp, err := service.DeleteTask(ctx, taskId)
+	resp, err := service.DeleteTask(ctx, taskId, "")
 	if err != nil {
 		t.Fatalf("DeleteTask() returned error: %v", err)
 	}
 	assertResponseCode(t, 204, resp.Code)
 
 	// Проверяем, что задача удалена
-	getResp, _ := service.GetTask(ctx, taskId)
+	getResp, _ := service.GetTask(ctx, taskId, "")
 	assertResponseCode(t, 404, getResp.Code)
 
 	// Тест на удаление несуществующей задачи
-	resp, err = service.DeleteTask(ctx, "non-existent-id")
+	resp, err = service.DeleteTask(ctx, "non-existent-id", "")
 	if err != nil {
 		t.Fatalf("DeleteTask() returned error: %v", err)
 	}
@@ -162,7 +190,7 @@ func TestGetTaskResult(t *testing.T) {
 	taskId := taskResp.Task.Id
 
 	// Пытаемся получить результат незавершенной задачи
-	resp, err := service.GetTaskResult(ctx, taskId)
+	resp, err := service.GetTaskResult(ctx, taskId, "")
 	if err != nil {
 		t.Fatalf("GetTaskResult() returned error: %v", err)
 	}
@@ -170,7 +198,7 @@ func TestGetTaskResult(t *testing.T) {
 	assertError(t, pkg.TaskErrorNotCompleted, resp)
 
 	// Тест на получение результата несуществующей задачи
-	resp, err = service.GetTaskResult(ctx, "non-existent-id")
+	resp, err = service.GetTaskResult(ctx, "non-existent-id", "")
 	if err != nil {
 		t.Fatalf("GetTaskResult() returned error: %v", err)
 	}
@@ -0,0 +1,131 @@
+package openapi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"workmate/pkg"
+)
+
+const (
+	authErrorMissingToken = "Authorization header with Bearer token is required"
+	authErrorInvalidToken = "Invalid API key"
+	authErrorMissingCreds = "Client certificate or Bearer token is required"
+)
+
+// APIKey - запись о статическом API ключе. Сам ключ не хранится, только его SHA-256
+type APIKey struct {
+	Principal string   `json:"principal"`
+	Hash      string   `json:"hash"`
+	Scopes    []string `json:"scopes,omitempty"`
+}
+
+// apiKeysConfig - формат файла с API ключами
+type apiKeysConfig struct {
+	Keys []APIKey `json:"keys"`
+}
+
+// HashAPIKey - Посчитать хеш API ключа в формате, в котором он хранится в конфиге
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// LoadAPIKeys - Загрузить API ключи из JSON файла
+func LoadAPIKeys(path string) ([]APIKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var config apiKeysConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return config.Keys, nil
+}
+
+// Authenticator проверяет Bearer токены запросов по списку API ключей
+// и, если включено, клиентские TLS сертификаты
+type Authenticator struct {
+	principals  map[string]pkg.Principal
+	clientCerts bool
+}
+
+// AuthenticatorOption - параметр настройки аутентификатора
+type AuthenticatorOption func(*Authenticator)
+
+// WithClientCertificates - Принимать проверенный клиентский сертификат (mTLS) как способ аутентификации
+func WithClientCertificates() AuthenticatorOption {
+	return func(a *Authenticator) {
+		a.clientCerts = true
+	}
+}
+
+// NewAuthenticator создает аутентификатор по списку ключей
+func NewAuthenticator(keys []APIKey, opts ...AuthenticatorOption) (*Authenticator, error) {
+	a := &Authenticator{
+		principals: make(map[string]pkg.Principal, len(keys)),
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	for i, key := range keys {
+		hash := strings.ToLower(key.Hash)
+		if key.Principal == "" {
+			return nil, fmt.Errorf("api key #%d: principal is required", i)
+		}
+		if len(hash) != sha256.Size*2 {
+			return nil, fmt.Errorf("api key %q: hash must be hex-encoded SHA-256", key.Principal)
+		}
+		if _, exists := a.principals[hash]; exists {
+			return nil, fmt.Errorf("api key %q: duplicate hash", key.Principal)
+		}
+		a.principals[hash] = pkg.Principal{Name: key.Principal, Scopes: key.Scopes}
+	}
+	return a, nil
+}
+
+// Authenticate - Определить вызывающего по заголовку Authorization,
+// а при его отсутствии - по клиентскому сертификату
+func (a *Authenticator) Authenticate(r *http.Request) (pkg.Principal, error) {
+	header := r.Header.Get("Authorization")
+	if header == "" && a.clientCerts {
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+			return pkg.Principal{}, fmt.Errorf("%s", authErrorMissingCreds)
+		}
+		return PrincipalFromCertificate(r.TLS.VerifiedChains[0][0])
+	}
+
+	// Схема аутентификации не зависит от регистра (RFC 9110, раздел 11.1)
+	scheme, token, _ := strings.Cut(header, " ")
+	token = strings.TrimSpace(token)
+	if !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return pkg.Principal{}, fmt.Errorf("%s", authErrorMissingToken)
+	}
+
+	principal, exists := a.principals[HashAPIKey(token)]
+	if !exists {
+		return pkg.Principal{}, fmt.Errorf("%s", authErrorInvalidToken)
+	}
+	return principal, nil
+}
+
+// Middleware - HTTP middleware: пропускает только аутентифицированные запросы
+// и кладет вызывающего в контекст запроса
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, err := a.Authenticate(r)
+		if err != nil {
+			headers := map[string][]string{"WWW-Authenticate": {`Bearer realm="workmate"`}}
+			WriteProblem(w, NewProblem(r.Context(), fmt.Errorf("%w: %v", pkg.ErrUnauthorized, err)), headers)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(pkg.WithPrincipal(r.Context(), principal)))
+	})
+}
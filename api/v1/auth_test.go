@@ -0,0 +1,99 @@
+package openapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"workmate/pkg"
+)
+
+func newTestAuthenticator(t *testing.T) *Authenticator {
+	auth, err := NewAuthenticator([]APIKey{
+		{Principal: "team-a", Hash: HashAPIKey("key-a")},
+		{Principal: "ops", Hash: HashAPIKey("key-ops"), Scopes: []string{pkg.ScopeAdmin}},
+	})
+	if err != nil {
+		t.Fatalf("NewAuthenticator() returned error: %v", err)
+	}
+	return auth
+}
+
+func TestAuthenticatorMiddleware(t *testing.T) {
+	auth := newTestAuthenticator(t)
+
+	var got pkg.Principal
+	handler := auth.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = pkg.PrincipalFromContext(r.Context())
+	}))
+
+	// Запрос без токена и с неверным токеном
+	for _, header := range []string{"", "Bearer wrong", "Basic key-a", "Bearer", "Bearerkey-a"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assertResponseCode(t, http.StatusUnauthorized, rec.Code)
+		if rec.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("Expected WWW-Authenticate header for %q", header)
+		}
+	}
+
+	// Запрос с верным токеном; схема - без учета регистра
+	for _, header := range []string{"Bearer key-ops", "bearer key-ops", "BEARER key-ops"} {
+		got = pkg.Principal{}
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks", nil)
+		req.Header.Set("Authorization", header)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assertResponseCode(t, http.StatusOK, rec.Code)
+		if got.Name != "ops" || !got.IsAdmin() {
+			t.Errorf("Expected admin principal 'ops' for %q, got %+v", header, got)
+		}
+	}
+}
+
+func TestNewAuthenticatorValidation(t *testing.T) {
+	if _, err := NewAuthenticator([]APIKey{{Principal: "a", Hash: "plain-key"}}); err == nil {
+		t.Error("NewAuthenticator() with non-hash key should return error")
+	}
+	if _, err := NewAuthenticator([]APIKey{{Hash: HashAPIKey("k")}}); err == nil {
+		t.Error("NewAuthenticator() without principal should return error")
+	}
+}
+
+func TestTaskOwnership(t *testing.T) {
+	service := NewTasksAPIService()
+	alice := pkg.WithPrincipal(context.Background(), pkg.Principal{Name: "alice"})
+	bob := pkg.WithPrincipal(context.Background(), pkg.Principal{Name: "bob"})
+	admin := pkg.WithPrincipal(context.Background(), pkg.Principal{Name: "ops", Scopes: []string{pkg.ScopeAdmin}})
+
+	createResp, _ := service.CreateTask(alice, CreateTaskRequest{Name: "Alice Task"})
+	task := createResp.Body.(TaskResponse).Task
+	if task.Owner != "alice" {
+		t.Errorf("Expected owner 'alice', got '%s'", task.Owner)
+	}
+
+	// Чужая задача не видна
+	resp, _ := service.GetTask(bob, task.Id, "")
+	assertResponseCode(t, 404, resp.Code)
+	resp, _ = service.DeleteTask(bob, task.Id, "")
+	assertResponseCode(t, 404, resp.Code)
+	resp, _ = service.GetTasks(bob, nil, time.Time{}, time.Time{}, 0)
+	if n := len(resp.Body.(TaskListResponse).Tasks); n != 0 {
+		t.Errorf("Expected 0 tasks for bob, got %d", n)
+	}
+
+	// Владелец и admin видят задачу
+	resp, _ = service.GetTask(alice, task.Id, "")
+	assertResponseCode(t, 200, resp.Code)
+	resp, _ = service.GetTasks(admin, nil, time.Time{}, time.Time{}, 0)
+	if n := len(resp.Body.(TaskListResponse).Tasks); n != 1 {
+		t.Errorf("Expected 1 task for admin, got %d", n)
+	}
+	resp, _ = service.DeleteTask(admin, task.Id, "")
+	assertResponseCode(t, 204, resp.Code)
+}
@@ -0,0 +1,129 @@
+package openapi
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// CertExpiryWarning - за сколько до истечения сертификата начинать предупреждать
+const CertExpiryWarning = 30 * 24 * time.Hour
+
+// CertReloader отдает серверу TLS сертификат через tls.Config.GetCertificate
+// и перечитывает пару cert/key при изменении файлов без перезапуска сервера
+type CertReloader struct {
+	certFile string
+	keyFile  string
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	notAfter time.Time
+	modTime  time.Time
+}
+
+// NewCertReloader создает загрузчик и сразу читает текущую пару ключей
+func NewCertReloader(certFile, keyFile string) (*CertReloader, error) {
+	c := &CertReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+	if err := c.Reload(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Reload - Перечитать пару ключей. Если новая пара не парсится,
+// продолжаем отдавать старую и возвращаем ошибку
+func (c *CertReloader) Reload() error {
+	modTime := c.filesModTime()
+
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err == nil {
+		cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0])
+	}
+	if err != nil {
+		// Запоминаем время изменения, чтобы не повторять попытку до следующей записи файлов
+		c.mu.Lock()
+		c.modTime = modTime
+		c.mu.Unlock()
+		return err
+	}
+	leaf := cert.Leaf
+
+	c.mu.Lock()
+	c.cert = &cert
+	c.notAfter = leaf.NotAfter
+	c.modTime = modTime
+	c.mu.Unlock()
+
+	log.Printf("TLS certificate loaded from %s: CN=%s, expires at %s (in %s)",
+		c.certFile, leaf.Subject.CommonName, leaf.NotAfter.Format(time.RFC3339),
+		time.Until(leaf.NotAfter).Round(time.Hour))
+	c.warnIfExpiring()
+	return nil
+}
+
+// GetCertificate - Текущий сертификат для tls.Config.GetCertificate
+func (c *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cert, nil
+}
+
+// NotAfter - Время истечения текущего сертификата
+func (c *CertReloader) NotAfter() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.notAfter
+}
+
+// ExpiresSoon - Истекает ли текущий сертификат в пределах CertExpiryWarning
+func (c *CertReloader) ExpiresSoon() bool {
+	return time.Until(c.NotAfter()) < CertExpiryWarning
+}
+
+// Watch - Периодически проверять время изменения файлов и перечитывать пару при изменении.
+// Блокируется до отмены ctx
+func (c *CertReloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.mu.RLock()
+			changed := !c.filesModTime().Equal(c.modTime)
+			c.mu.RUnlock()
+
+			if changed {
+				if err := c.Reload(); err != nil {
+					log.Printf("Error reloading TLS certificate, keeping the previous one: %v", err)
+				}
+			}
+		}
+	}
+}
+
+// filesModTime - Время последнего изменения пары файлов (позднейшее из двух)
+func (c *CertReloader) filesModTime() time.Time {
+	var latest time.Time
+	for _, name := range []string{c.certFile, c.keyFile} {
+		if info, err := os.Stat(name); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest
+}
+
+func (c *CertReloader) warnIfExpiring() {
+	if c.ExpiresSoon() {
+		log.Printf("Warning: TLS certificate %s expires at %s", c.certFile, c.NotAfter().Format(time.RFC3339))
+	}
+}
@@ -0,0 +1,77 @@
+package openapi
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Вспомогательная функция: записать самоподписанную пару cert/key с заданным сроком действия
+func writeKeyPair(t *testing.T, certFile, keyFile string, notAfter time.Time) {
+	cert, key := issueCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+	}, nil, nil)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+
+	firstExpiry := time.Now().Add(365 * 24 * time.Hour).Truncate(time.Second)
+	writeKeyPair(t, certFile, keyFile, firstExpiry)
+
+	reloader, err := NewCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("NewCertReloader() returned error: %v", err)
+	}
+	if !reloader.NotAfter().Equal(firstExpiry) {
+		t.Errorf("Expected expiry %s, got %s", firstExpiry, reloader.NotAfter())
+	}
+	if reloader.ExpiresSoon() {
+		t.Error("Certificate valid for a year should not expire soon")
+	}
+
+	// Ротация на новую пару
+	secondExpiry := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	writeKeyPair(t, certFile, keyFile, secondExpiry)
+	if err := reloader.Reload(); err != nil {
+		t.Fatalf("Reload() returned error: %v", err)
+	}
+	cert, _ := reloader.GetCertificate(nil)
+	if !cert.Leaf.NotAfter.Equal(secondExpiry) {
+		t.Errorf("Expected reloaded certificate expiring at %s, got %s", secondExpiry, cert.Leaf.NotAfter)
+	}
+	if !reloader.ExpiresSoon() {
+		t.Error("Certificate valid for a day should expire soon")
+	}
+
+	// Битый файл: продолжаем отдавать предыдущий сертификат
+	if err := os.WriteFile(certFile, []byte("garbage"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := reloader.Reload(); err == nil {
+		t.Error("Reload() with broken certificate should return error")
+	}
+	if current, _ := reloader.GetCertificate(nil); current != cert {
+		t.Error("Previous certificate should be kept after failed reload")
+	}
+}
@@ -1,28 +1,206 @@
 package openapi
 
 import (
+	"encoding/base64"
+	"time"
+	"workmate/api/contract"
+	"workmate/internal"
 	"workmate/pkg"
 )
 
-// Маппинг из внутреннего типа сервиса в API DTO
-func MapInternalTaskToAPI(task pkg.InternalTask) Task {
-	return Task{
-		Id:         task.Id,
-		Status:     task.Status,
-		CreatedAt:  task.CreatedAt,
-		StartedAt:  task.StartedAt,
-		FinishedAt: task.FinishedAt,
-		Result:     task.Result,
-		Error:      task.Error,
-		Duration:   task.Duration,
+// Маппинг из внутреннего типа сервиса в API DTO; продолжительность выполняющейся задачи
+// считается до момента now. Контрольная точка не передается (только время), см. MapTaskRecordToAPI
+func MapInternalTaskToAPI(task pkg.InternalTask, now time.Time) Task {
+	apiTask := Task{
+		Id:           task.Id,
+		Name:         task.Name,
+		Status:       task.Status,
+		CreatedAt:    task.CreatedAt,
+		StartedAt:    task.StartedAt,
+		FinishedAt:   task.FinishedAt,
+		PausedAt:     task.PausedAt,
+		CheckpointAt: task.CheckpointAt,
+		Result:       task.Result,
+		Error:        task.Error,
+		Owner:        task.Owner,
+		Namespace:    task.Namespace,
+		Version:      int64(task.Version),
+	}
+	if task.Output != nil {
+		apiTask.Output = &TaskOutput{
+			ContentType: task.Output.ContentType,
+			Size:        task.Output.Size,
+			Checksum:    task.Output.Checksum,
+			CreatedAt:   task.Output.CreatedAt,
+		}
+	}
+	if !task.StartedAt.IsZero() {
+		elapsed := task.Elapsed(now)
+		apiTask.Duration = elapsed.Round(time.Second).String()
+		apiTask.DurationMs = elapsed.Milliseconds()
+	}
+	return apiTask
+}
+
+// Маппинг задачи для выгрузки: вместе с контрольной точкой исполнителя, но без метаданных
+// выходных данных - содержимое хранилища результатов при импорте не переносится
+func MapTaskRecordToAPI(task pkg.InternalTask, now time.Time) Task {
+	apiTask := MapInternalTaskToAPI(task, now)
+	apiTask.Output = nil
+	apiTask.Checkpoint = base64.StdEncoding.EncodeToString(task.Checkpoint)
+	return apiTask
+}
+
+// Маппинг задачи из API (запись выгрузки) во внутренние типы сервиса; контрольная точка
+// должна быть проверена при разборе записи (decodeImportRecord)
+func MapAPITaskToInternal(task Task) pkg.InternalTask {
+	checkpoint, _ := base64.StdEncoding.DecodeString(task.Checkpoint)
+	if len(checkpoint) == 0 {
+		checkpoint = nil
+	}
+	return pkg.InternalTask{
+		Id:           task.Id,
+		Name:         task.Name,
+		Status:       task.Status,
+		CreatedAt:    task.CreatedAt,
+		StartedAt:    task.StartedAt,
+		FinishedAt:   task.FinishedAt,
+		PausedAt:     task.PausedAt,
+		Checkpoint:   checkpoint,
+		CheckpointAt: task.CheckpointAt,
+		Result:       task.Result,
+		Error:        task.Error,
+		Owner:        task.Owner,
+		Namespace:    task.Namespace,
 	}
 }
 
 // Маппинг списка задач из внутренних типов сервиса в API
-func MapInternalTasksToAPI(tasks []pkg.InternalTask) []Task {
+func MapInternalTasksToAPI(tasks []pkg.InternalTask, now time.Time) []Task {
 	result := make([]Task, len(tasks))
 	for i, task := range tasks {
-		result[i] = MapInternalTaskToAPI(task)
+		result[i] = MapInternalTaskToAPI(task, now)
+	}
+	return result
+}
+
+// Маппинг итогов импорта в отчет API; lines - номера строк выгрузки для каждой задачи
+func MapImportResultsToAPI(results []internal.ImportResult, lines []int) ImportReport {
+	report := ImportReport{Records: make([]ImportRecord, len(results))}
+	for i, result := range results {
+		report.Records[i] = ImportRecord{
+			Line:     int32(lines[i]),
+			Id:       result.Id,
+			Action:   result.Action,
+			Requeued: result.Requeued,
+		}
+		switch result.Action {
+		case pkg.ImportActionCreated:
+			report.Created++
+		case pkg.ImportActionOverwritten:
+			report.Overwritten++
+		case pkg.ImportActionSkipped:
+			report.Skipped++
+		}
+		if result.Requeued {
+			report.Requeued++
+		}
+	}
+	return report
+}
+
+// Маппинг истории переходов статуса задачи в API
+func MapTaskHistoryToAPI(history []pkg.TaskTransition) []TaskTransition {
+	result := make([]TaskTransition, len(history))
+	for i, t := range history {
+		result[i] = TaskTransition{
+			From:   t.From,
+			To:     t.To,
+			At:     t.At,
+			Reason: t.Reason,
+		}
+	}
+	return result
+}
+
+// Маппинг пространств имен из внутренних типов сервиса в API
+func MapNamespacesToAPI(namespaces []pkg.NamespaceInfo) []Namespace {
+	result := make([]Namespace, len(namespaces))
+	for i, ns := range namespaces {
+		result[i] = Namespace{
+			Name:        ns.Name,
+			ActiveTasks: int32(ns.ActiveTasks),
+			StoredTasks: int32(ns.StoredTasks),
+			Quota: NamespaceQuota{
+				MaxActiveTasks: int32(ns.Quota.MaxActiveTasks),
+				MaxStoredTasks: int32(ns.Quota.MaxStoredTasks),
+			},
+		}
+	}
+	return result
+}
+
+// Маппинг потребления принципалов в API
+func MapUsageToAPI(usage []pkg.Usage) []PrincipalUsage {
+	result := make([]PrincipalUsage, len(usage))
+	for i, u := range usage {
+		result[i] = PrincipalUsage{
+			Principal:     u.Principal,
+			TasksCreated:  int32(u.TasksCreated),
+			TasksFinished: int32(u.TasksFinished),
+			TaskSeconds:   u.TaskSeconds,
+			RunningTasks:  int32(u.RunningTasks),
+			PendingTasks:  int32(u.PendingTasks),
+			Quota: TenantQuota{
+				MaxRunningTasks: int32(u.Quota.MaxRunningTasks),
+				MaxPendingTasks: int32(u.Quota.MaxPendingTasks),
+				MaxTasksPerDay:  int32(u.Quota.MaxTasksPerDay),
+				MaxTaskSeconds:  u.Quota.MaxTaskSeconds,
+				PeriodHours:     int32(u.Quota.PeriodHours),
+			},
+		}
+	}
+	return result
+}
+
+// Маппинг состояния запуска задач в API
+func MapDispatchStateToAPI(state internal.DispatchState) DispatchStatus {
+	return DispatchStatus{
+		Paused:       state.Paused,
+		PausedAt:     state.PausedAt,
+		PendingTasks: int32(state.PendingTasks),
+		RunningTasks: int32(state.RunningTasks),
+		PausedTasks:  int32(state.PausedTasks),
+	}
+}
+
+// Маппинг попыток доставки webhook в API
+func MapWebhookDeliveriesToAPI(deliveries []pkg.WebhookDelivery) []WebhookDelivery {
+	result := make([]WebhookDelivery, len(deliveries))
+	for i, d := range deliveries {
+		result[i] = WebhookDelivery{
+			Attempt:    int32(d.Attempt),
+			Event:      d.Event,
+			Url:        d.URL,
+			At:         d.At,
+			Payload:    d.Payload,
+			StatusCode: int32(d.StatusCode),
+			Response:   d.Response,
+			Error:      d.Error,
+		}
+	}
+	return result
+}
+
+// Маппинг нарушений схемы запроса в API
+func MapViolationsToAPI(violations []contract.Violation) []Violation {
+	result := make([]Violation, len(violations))
+	for i, v := range violations {
+		result[i] = Violation{
+			In:      v.In,
+			Pointer: v.Pointer,
+			Message: v.Message,
+		}
 	}
 	return result
 }
@@ -0,0 +1,50 @@
+package openapi
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+	"workmate/pkg"
+)
+
+// ContentBody - тело ответа с содержимым из хранилища результатов. Отдается через
+// http.ServeContent: Content-Length, Range (206, 416) и условные запросы (304)
+type ContentBody struct {
+	Content     io.ReadSeekCloser
+	ContentType string
+	ModTime     time.Time
+}
+
+// contentHeaders - Заголовки содержимого: ETag и Repr-Digest (RFC 9530) из контрольной суммы
+func contentHeaders(info pkg.ResultInfo) map[string][]string {
+	sum := info.ChecksumBytes()
+	if sum == nil {
+		return nil
+	}
+	return map[string][]string{
+		"ETag":        {`"` + hex.EncodeToString(sum) + `"`},
+		"Repr-Digest": {"sha-256=:" + base64.StdEncoding.EncodeToString(sum) + ":"},
+	}
+}
+
+// EncodeContentResponse - Отдать ContentBody с учетом Range и условных заголовков запроса r;
+// остальные ответы (Problem) кодируются как обычно
+func EncodeContentResponse(result ImplResponse, w http.ResponseWriter, r *http.Request) error {
+	body, ok := result.Body.(*ContentBody)
+	if !ok {
+		return EncodeJSONResponse(result.Body, &result.Code, result.Headers, w)
+	}
+	defer body.Content.Close()
+
+	header := w.Header()
+	for key, values := range result.Headers {
+		for _, value := range values {
+			header.Add(key, value)
+		}
+	}
+	header.Set("Content-Type", body.ContentType)
+	http.ServeContent(w, r, "", body.ModTime, body.Content)
+	return nil
+}
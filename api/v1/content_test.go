@@ -0,0 +1,88 @@
+package openapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"workmate/internal"
+	"workmate/pkg"
+)
+
+// contentExecutor - исполнитель, сразу записывающий выходные данные content
+type contentExecutor struct {
+	content string
+}
+
+func (e contentExecutor) Execute(ctx context.Context, task pkg.InternalTask) (string, error) {
+	if _, err := internal.WriteResult(ctx, "text/csv", strings.NewReader(e.content)); err != nil {
+		return "", err
+	}
+	return "written", nil
+}
+
+func TestGetTaskResultContent(t *testing.T) {
+	ctx := context.Background()
+	content := "id,value\n1,2\n3,4\n"
+	store := pkg.NewFileResultStore(t.TempDir())
+	service := NewTasksAPIService(internal.WithExecutor(contentExecutor{content: content}), internal.WithResultStore(store))
+	router := NewRouter(NewTasksAPIController(service))
+
+	resp, _ := service.CreateTask(ctx, CreateTaskRequest{Name: "Report"})
+	taskId := resp.Body.(TaskResponse).Task.Id
+	for i := 0; i < 1000; i++ {
+		if task, _ := service.service.GetTask(ctx, taskId); task.Status == pkg.TaskStatusCompleted {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Результат задачи - только метаданные
+	resp, _ = service.GetTaskResult(ctx, taskId, "")
+	output := resp.Body.(TaskResponse).Task.Output
+	if output == nil || output.ContentType != "text/csv" || output.Size != int64(len(content)) {
+		t.Fatalf("Unexpected task output %+v", output)
+	}
+
+	get := func(header http.Header) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/tasks/"+taskId+"/result/content", nil)
+		for name, values := range header {
+			r.Header[name] = values
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, r)
+		return rec
+	}
+
+	rec := get(nil)
+	assertResponseCode(t, http.StatusOK, rec.Code)
+	sum := sha256.Sum256([]byte(content))
+	if rec.Body.String() != content || rec.Header().Get("Content-Type") != "text/csv" || rec.Header().Get("Content-Length") != "17" {
+		t.Errorf("Unexpected content %q with headers %v", rec.Body.String(), rec.Header())
+	}
+	if digest := rec.Header().Get("Repr-Digest"); digest != "sha-256=:"+base64.StdEncoding.EncodeToString(sum[:])+":" {
+		t.Errorf("Unexpected Repr-Digest %q", digest)
+	}
+
+	// Часть содержимого; Repr-Digest - по-прежнему сумма всего содержимого
+	rec = get(http.Header{"Range": {"bytes=9-11"}})
+	assertResponseCode(t, http.StatusPartialContent, rec.Code)
+	if rec.Body.String() != "1,2" || rec.Header().Get("Content-Range") != "bytes 9-11/17" || rec.Header().Get("Repr-Digest") == "" {
+		t.Errorf("Unexpected partial content %q with headers %v", rec.Body.String(), rec.Header())
+	}
+
+	rec = get(http.Header{"If-None-Match": {rec.Header().Get("ETag")}})
+	assertResponseCode(t, http.StatusNotModified, rec.Code)
+
+	// Удаление задачи удаляет и выходные данные
+	resp, _ = service.DeleteTask(ctx, taskId, "")
+	assertResponseCode(t, http.StatusNoContent, resp.Code)
+	if _, err := store.Open(taskId); !errors.Is(err, pkg.ErrResultNotFound) {
+		t.Errorf("Expected result content to be deleted with the task, got %v", err)
+	}
+}
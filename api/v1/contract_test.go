@@ -0,0 +1,415 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+	"workmate/api/contract"
+	"workmate/internal"
+	"workmate/pkg"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// contractRequest - запрос к API и ожидаемый код ответа
+type contractRequest struct {
+	method      string
+	path        string
+	token       string
+	body        string
+	contentType string // по умолчанию application/json
+	header      http.Header
+	status      int
+}
+
+// build - Собрать HTTP запрос
+func (req contractRequest) build() *http.Request {
+	r := httptest.NewRequest(req.method, req.path, strings.NewReader(req.body))
+	if req.contentType != "" {
+		r.Header.Set("Content-Type", req.contentType)
+	} else if req.body != "" {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	if req.token != "" {
+		r.Header.Set("Authorization", "Bearer "+req.token)
+	}
+	for name, values := range req.header {
+		r.Header[name] = values
+	}
+	return r
+}
+
+// contractHarness - полный стек обработчиков, как в launcher (аутентификация, лимиты, политика),
+// проверяющий каждый ответ по спецификации и учитывающий покрытые ответы операций
+type contractHarness struct {
+	spec    *contract.Spec
+	covered map[string]map[string]bool
+}
+
+// contractExecutor - исполнитель контрактных тестов: через 3 минуты записывает выходные данные
+type contractExecutor struct {
+	clock pkg.Clock
+}
+
+func (e *contractExecutor) Execute(ctx context.Context, task pkg.InternalTask) (string, error) {
+	select {
+	case <-e.clock.After(3 * time.Minute):
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	if _, err := internal.WriteResult(ctx, "text/csv", strings.NewReader("id,value\n1,2\n")); err != nil {
+		return "", err
+	}
+	return "2 rows", nil
+}
+
+// newContractRouter - Роутер с ключами team-a (submitter), dash (viewer), guest (без ролей), ops (admin)
+func newContractRouter(t *testing.T, spec *contract.Spec, clock pkg.Clock, limit RateLimit) *mux.Router {
+	t.Helper()
+
+	policy := DefaultPolicy()
+	policy.Bindings = map[string][]string{
+		"dash":  {pkg.RoleViewer},
+		"guest": {},
+		"ops":   {pkg.RoleAdmin},
+	}
+	service := NewTasksAPIPolicy(NewTasksAPIService(internal.WithClock(clock), internal.WithExecutor(&contractExecutor{clock: clock}),
+		internal.WithResultStore(pkg.NewFileResultStore(t.TempDir()))), policy)
+	router := NewRouter(NewTasksAPIController(service))
+
+	auth, err := NewAuthenticator([]APIKey{
+		{Principal: "team-a", Hash: HashAPIKey("key-a")},
+		{Principal: "dash", Hash: HashAPIKey("key-dash")},
+		{Principal: "guest", Hash: HashAPIKey("key-guest")},
+		{Principal: "ops", Hash: HashAPIKey("key-ops")},
+	})
+	if err != nil {
+		t.Fatalf("NewAuthenticator() returned error: %v", err)
+	}
+	router.Use(auth.Middleware)
+	router.Use(NewRateLimiter(&RateLimitConfig{Default: &limit}).Middleware)
+	router.Use(NewRequestValidator(spec).Middleware)
+	return router
+}
+
+// do - Выполнить запрос, проверить код и соответствие ответа контракту
+func (h *contractHarness) do(t *testing.T, router *mux.Router, req contractRequest) *httptest.ResponseRecorder {
+	t.Helper()
+
+	r := req.build()
+	var match mux.RouteMatch
+	if !router.Match(r, &match) {
+		t.Fatalf("%s %s: no route", req.method, req.path)
+	}
+	template, _ := match.Route.GetPathTemplate()
+	op, ok := h.spec.Operation(req.method, template)
+	if !ok {
+		t.Fatalf("%s %s: operation is not declared in the spec", req.method, template)
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, r)
+
+	if rec.Code != req.status {
+		t.Errorf("%s %s: expected status %d, got %d: %s", req.method, req.path, req.status, rec.Code, rec.Body.String())
+	}
+	for _, v := range h.spec.ValidateResponse(op, rec.Code, rec.Header(), rec.Body.Bytes()) {
+		t.Errorf("%s %s -> %d: %s", req.method, req.path, rec.Code, v)
+	}
+
+	if h.covered[op.ID] == nil {
+		h.covered[op.ID] = map[string]bool{}
+	}
+	h.covered[op.ID][fmt.Sprint(rec.Code)] = true
+	return rec
+}
+
+// decodeTask - Достать задачу из TaskResponse
+func decodeTask(t *testing.T, rec *httptest.ResponseRecorder) Task {
+	t.Helper()
+	var resp TaskResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode TaskResponse: %v", err)
+	}
+	return resp.Task
+}
+
+func TestRoutesMatchContract(t *testing.T) {
+	spec, err := contract.LoadV1()
+	if err != nil {
+		t.Fatalf("LoadV1() returned error: %v", err)
+	}
+
+	routes := NewTasksAPIController(NewTasksAPIService()).OrderedRoutes()
+	if len(routes) != len(spec.Operations) {
+		t.Errorf("Expected %d routes for %d spec operations, got %d", len(spec.Operations), len(spec.Operations), len(routes))
+	}
+	for _, route := range routes {
+		op, ok := spec.Operation(route.Method, route.Pattern)
+		if !ok {
+			t.Errorf("Route %s %s %s is not declared in the spec", route.Name, route.Method, route.Pattern)
+			continue
+		}
+		if !strings.EqualFold(op.ID, route.Name) {
+			t.Errorf("Route %s has operationId %s in the spec", route.Name, op.ID)
+		}
+	}
+}
+
+func TestResponsesMatchContract(t *testing.T) {
+	spec, err := contract.LoadV1()
+	if err != nil {
+		t.Fatalf("LoadV1() returned error: %v", err)
+	}
+	h := &contractHarness{spec: spec, covered: map[string]map[string]bool{}}
+
+	clock := pkg.NewFakeClock(time.Date(2024, 1, 15, 15, 4, 5, 0, time.UTC))
+	router := newContractRouter(t, spec, clock, RateLimit{Rate: 1000, Burst: 1000})
+	// Второй роутер с burst 1: повторный запрос к любой операции получает 429
+	throttled := newContractRouter(t, spec, pkg.NewFakeClock(time.Now()), RateLimit{Rate: 0.001, Burst: 1})
+
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer hook.Close()
+
+	// Завершенная задача с доставленным webhook
+	rec := h.do(t, router, contractRequest{method: "POST", path: "/api/v1/tasks", token: "key-a",
+		body: fmt.Sprintf(`{"name":"Contract Task","callbackUrl":%q}`, hook.URL), status: 201})
+	completed := decodeTask(t, rec)
+	clock.BlockUntil(1)
+	clock.Advance(5 * time.Minute)
+	for i := 0; ; i++ {
+		rec = h.do(t, router, contractRequest{method: "GET", path: "/api/v1/tasks/" + completed.Id + "/deliveries", token: "key-a", status: 200})
+		if strings.Contains(rec.Body.String(), `"attempt"`) {
+			break
+		}
+		if i == 1000 {
+			t.Fatalf("Webhook for task %s was not delivered", completed.Id)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Задача, ожидающая таймер
+	pending := decodeTask(t, h.do(t, router, contractRequest{method: "POST", path: "/api/v1/tasks", token: "key-a",
+		body: fmt.Sprintf(`{"name":"Pending Task","callbackUrl":%q,"callbackEvents":["failed"]}`, hook.URL), status: 201}))
+
+	// Версия завершенной задачи больше не меняется
+	etag := http.Header{"If-None-Match": {h.do(t, router, contractRequest{method: "GET", path: "/api/v1/tasks/" + completed.Id,
+		token: "key-a", status: 200}).Header().Get("ETag")}}
+
+	// ETag выходных данных - контрольная сумма содержимого
+	contentETag := http.Header{"If-None-Match": {h.do(t, router, contractRequest{method: "GET", path: "/api/v1/tasks/" + completed.Id + "/result/content",
+		token: "key-a", status: 200}).Header().Get("ETag")}}
+
+	// Задача пространства имен team-a не видна в пространстве по умолчанию
+	teamTask := decodeTask(t, h.do(t, router, contractRequest{method: "POST", path: "/api/v1/namespaces/team-a/tasks", token: "key-a",
+		body: `{"name":"Team Task"}`, status: 201}))
+	if teamTask.Namespace != "team-a" {
+		t.Errorf("Expected task in namespace team-a, got %q", teamTask.Namespace)
+	}
+
+	missing := uuid.NewString()
+	imported := uuid.NewString()
+	record := func(id string) string {
+		return fmt.Sprintf(`{"id":%q,"name":"Imported Task","status":"completed","createdAt":"2024-01-15T15:04:05Z",`+
+			`"startedAt":"2024-01-15T15:04:06Z","finishedAt":"2024-01-15T15:07:06Z","result":"done","duration":"3m0s"}`, id)
+	}
+	requests := []contractRequest{
+		// createTask
+		{method: "POST", path: "/api/v1/tasks", token: "key-a", body: `{"name":`, status: 400},
+		{method: "POST", path: "/api/v1/tasks", token: "key-a", body: `{"name":"Task","callbackUrl":"ftp://example.com"}`, status: 400},
+		{method: "POST", path: "/api/v1/tasks", token: "key-a", body: `{}`, status: 422},
+		{method: "POST", path: "/api/v1/tasks", token: "key-a", body: `{"name":"` + strings.Repeat("x", 256) + `","priority":1}`, status: 422},
+		{method: "POST", path: "/api/v1/tasks", token: "key-a", body: `{"name":"` + strings.Repeat("x", maxRequestBody) + `"}`, status: 413},
+		{method: "POST", path: "/api/v1/tasks", body: `{"name":"Task"}`, status: 401},
+		{method: "POST", path: "/api/v1/tasks", token: "key-dash", body: `{"name":"Task"}`, status: 403},
+
+		// getTasks
+		{method: "GET", path: "/api/v1/tasks", token: "key-a", status: 200},
+		{method: "GET", path: "/api/v1/tasks?status=completed,pending&createdAfter=2024-01-01T00:00:00Z&limit=10", token: "key-dash", status: 200},
+		{method: "GET", path: "/api/v1/tasks?status=unknown", token: "key-a", status: 400},
+		{method: "GET", path: "/api/v1/tasks?limit=many", token: "key-a", status: 400},
+		{method: "GET", path: "/api/v1/tasks", token: "wrong", status: 401},
+		{method: "GET", path: "/api/v1/tasks", token: "key-guest", status: 403},
+
+		// exportTasks
+		{method: "GET", path: "/api/v1/tasks/export", token: "key-a", status: 200},
+		{method: "GET", path: "/api/v1/tasks/export?format=csv&columns=id,status,finishedAt&status=completed,pending", token: "key-dash", status: 200},
+		{method: "GET", path: "/api/v1/tasks/export?columns=id,callbackUrl", token: "key-a", status: 400},
+		{method: "GET", path: "/api/v1/tasks/export", status: 401},
+		{method: "GET", path: "/api/v1/tasks/export", token: "key-guest", status: 403},
+
+		// importTasks
+		{method: "POST", path: "/api/v1/tasks/import", token: "key-ops", contentType: NDJSONContentType,
+			body: record(uuid.NewString()) + "\n\n" + record(completed.Id) + "\n", status: 409},
+		{method: "POST", path: "/api/v1/tasks/import?conflict=skip&requeue=true", token: "key-ops", contentType: NDJSONContentType,
+			body: record(imported) + "\n" + record(completed.Id) + "\n", status: 200},
+		{method: "POST", path: "/api/v1/tasks/import", token: "key-ops", contentType: NDJSONContentType,
+			body: `{"id":"42","name":"Task","status":"done"}` + "\nnot json\n", status: 422},
+		{method: "POST", path: "/api/v1/tasks/import?conflict=replace", token: "key-ops", contentType: NDJSONContentType,
+			body: record(missing), status: 400},
+		{method: "POST", path: "/api/v1/tasks/import", token: "key-ops", contentType: NDJSONContentType,
+			body: strings.Repeat(" ", 64<<20+1), status: 413},
+		{method: "POST", path: "/api/v1/tasks/import", contentType: NDJSONContentType, body: record(missing), status: 401},
+		{method: "POST", path: "/api/v1/tasks/import", token: "key-a", contentType: NDJSONContentType, body: record(missing), status: 403},
+
+		// getTask
+		{method: "GET", path: "/api/v1/tasks/" + completed.Id, token: "key-a", status: 200},
+		{method: "GET", path: "/api/v1/tasks/" + pending.Id, token: "key-dash", status: 200},
+		{method: "GET", path: "/api/v1/tasks/" + completed.Id, token: "key-a", header: etag, status: 304},
+		{method: "GET", path: "/api/v1/tasks/" + missing, token: "key-a", status: 404},
+		{method: "GET", path: "/api/v1/tasks/not-a-uuid", token: "key-a", status: 400},
+		{method: "GET", path: "/api/v1/tasks/" + completed.Id, status: 401},
+		{method: "GET", path: "/api/v1/tasks/" + completed.Id, token: "key-guest", status: 403},
+
+		// getTaskResult
+		{method: "GET", path: "/api/v1/tasks/" + completed.Id + "/result", token: "key-a", status: 200},
+		{method: "GET", path: "/api/v1/tasks/" + completed.Id + "/result", token: "key-a", header: etag, status: 304},
+		{method: "GET", path: "/api/v1/tasks/" + pending.Id + "/result", token: "key-a", status: 425},
+		{method: "GET", path: "/api/v1/tasks/" + missing + "/result", token: "key-a", status: 404},
+		{method: "GET", path: "/api/v1/tasks/not-a-uuid/result", token: "key-a", status: 400},
+		{method: "GET", path: "/api/v1/tasks/" + completed.Id + "/result", status: 401},
+		{method: "GET", path: "/api/v1/tasks/" + completed.Id + "/result", token: "key-guest", status: 403},
+
+		// getTaskResultContent
+		{method: "GET", path: "/api/v1/tasks/" + completed.Id + "/result/content", token: "key-a", status: 200},
+		{method: "GET", path: "/api/v1/tasks/" + completed.Id + "/result/content", token: "key-dash",
+			header: http.Header{"Range": {"bytes=0-7"}}, status: 206},
+		{method: "GET", path: "/api/v1/tasks/" + completed.Id + "/result/content", token: "key-a",
+			header: http.Header{"Range": {"bytes=100-"}}, status: 416},
+		{method: "GET", path: "/api/v1/tasks/" + completed.Id + "/result/content", token: "key-a", header: contentETag, status: 304},
+		{method: "GET", path: "/api/v1/tasks/" + pending.Id + "/result/content", token: "key-a", status: 425},
+		{method: "GET", path: "/api/v1/tasks/" + imported + "/result/content", token: "key-a", status: 404},
+		{method: "GET", path: "/api/v1/tasks/" + missing + "/result/content", token: "key-a", status: 404},
+		{method: "GET", path: "/api/v1/tasks/not-a-uuid/result/content", token: "key-a", status: 400},
+		{method: "GET", path: "/api/v1/tasks/" + completed.Id + "/result/content", status: 401},
+		{method: "GET", path: "/api/v1/tasks/" + completed.Id + "/result/content", token: "key-guest", status: 403},
+
+		// getTaskHistory
+		{method: "GET", path: "/api/v1/tasks/" + completed.Id + "/history", token: "key-a", status: 200},
+		{method: "GET", path: "/api/v1/tasks/" + missing + "/history", token: "key-a", status: 404},
+		{method: "GET", path: "/api/v1/tasks/not-a-uuid/history", token: "key-a", status: 400},
+		{method: "GET", path: "/api/v1/tasks/" + completed.Id + "/history", status: 401},
+		{method: "GET", path: "/api/v1/tasks/" + completed.Id + "/history", token: "key-guest", status: 403},
+
+		// getTaskDeliveries
+		{method: "GET", path: "/api/v1/tasks/" + missing + "/deliveries", token: "key-a", status: 404},
+		{method: "GET", path: "/api/v1/tasks/not-a-uuid/deliveries", token: "key-a", status: 400},
+		{method: "GET", path: "/api/v1/tasks/" + completed.Id + "/deliveries", status: 401},
+		{method: "GET", path: "/api/v1/tasks/" + completed.Id + "/deliveries", token: "key-guest", status: 403},
+
+		// Операции с задачами в пространстве имен
+		{method: "GET", path: "/api/v1/namespaces/team-a/tasks/" + teamTask.Id, token: "key-a", status: 200},
+		{method: "GET", path: "/api/v1/namespaces/team-a/tasks?status=pending", token: "key-a", status: 200},
+		{method: "GET", path: "/api/v1/tasks/" + teamTask.Id, token: "key-a", status: 404},
+		{method: "GET", path: "/api/v1/namespaces/team-b/tasks/" + teamTask.Id, token: "key-a", status: 404},
+		{method: "GET", path: "/api/v1/namespaces/Team_A/tasks", token: "key-a", status: 400},
+
+		// listNamespaces
+		{method: "GET", path: "/api/v1/namespaces", token: "key-dash", status: 200},
+		{method: "GET", path: "/api/v1/namespaces", status: 401},
+		{method: "GET", path: "/api/v1/namespaces", token: "key-a", status: 403},
+
+		// purgeNamespace
+		{method: "DELETE", path: "/api/v1/namespaces/team-a", token: "key-a", status: 403},
+		{method: "DELETE", path: "/api/v1/namespaces/team-a", status: 401},
+		{method: "DELETE", path: "/api/v1/namespaces/Team_A", token: "key-ops", status: 400},
+		{method: "DELETE", path: "/api/v1/namespaces/team-a", token: "key-ops", status: 200},
+		{method: "GET", path: "/api/v1/namespaces/team-a/tasks/" + teamTask.Id, token: "key-a", status: 404},
+
+		// getUsage
+		{method: "GET", path: "/api/v1/usage", token: "key-dash", status: 200},
+		{method: "GET", path: "/api/v1/usage?principal=team-a&from=2024-01-15T00:00:00Z", token: "key-a", status: 200},
+		{method: "GET", path: "/api/v1/usage?principal=dash", token: "key-a", status: 403},
+		{method: "GET", path: "/api/v1/usage", token: "key-guest", status: 403},
+		{method: "GET", path: "/api/v1/usage", status: 401},
+		{method: "GET", path: "/api/v1/usage?from=yesterday", token: "key-dash", status: 400},
+		{method: "GET", path: "/api/v1/usage?from=2024-01-15T16:00:00Z&to=2024-01-15T15:00:00Z", token: "key-dash", status: 400},
+
+		// deleteTask
+		{method: "DELETE", path: "/api/v1/tasks/" + pending.Id, token: "key-dash", status: 403},
+		{method: "DELETE", path: "/api/v1/tasks/" + pending.Id, token: "key-a", status: 403},
+		{method: "DELETE", path: "/api/v1/tasks/" + pending.Id, status: 401},
+		{method: "DELETE", path: "/api/v1/tasks/" + pending.Id, token: "key-ops", header: http.Header{"If-Match": {`"0"`}}, status: 412},
+		{method: "DELETE", path: "/api/v1/tasks/" + pending.Id, token: "key-ops", header: http.Header{"If-Match": {"*"}}, status: 204},
+		{method: "DELETE", path: "/api/v1/tasks/" + pending.Id, token: "key-ops", status: 404},
+		{method: "DELETE", path: "/api/v1/tasks/not-a-uuid", token: "key-ops", status: 400},
+	}
+	for _, req := range requests {
+		h.do(t, router, req)
+	}
+
+	// Пока запуск остановлен, новая задача ждет в pending: ее можно приостановить, возобновить и отменить
+	h.do(t, router, contractRequest{method: "POST", path: "/api/v1/dispatch/pause", token: "key-ops", status: 200})
+	queued := decodeTask(t, h.do(t, router, contractRequest{method: "POST", path: "/api/v1/tasks", token: "key-a",
+		body: `{"name":"Queued Task"}`, status: 201}))
+	requests = []contractRequest{
+		// pauseTask
+		{method: "POST", path: "/api/v1/tasks/" + queued.Id + "/pause", token: "key-ops", status: 200},
+		{method: "POST", path: "/api/v1/tasks/" + queued.Id + "/pause", token: "key-ops", status: 409},
+		{method: "POST", path: "/api/v1/tasks/" + queued.Id + "/pause", token: "key-dash", status: 403},
+		{method: "POST", path: "/api/v1/tasks/" + queued.Id + "/pause", status: 401},
+		{method: "POST", path: "/api/v1/tasks/" + missing + "/pause", token: "key-ops", status: 404},
+		{method: "POST", path: "/api/v1/tasks/not-a-uuid/pause", token: "key-ops", status: 400},
+
+		// resumeTask
+		{method: "POST", path: "/api/v1/tasks/" + queued.Id + "/resume", token: "key-ops", status: 200},
+		{method: "POST", path: "/api/v1/tasks/" + queued.Id + "/resume", token: "key-ops", status: 409},
+		{method: "POST", path: "/api/v1/tasks/" + queued.Id + "/resume", token: "key-dash", status: 403},
+		{method: "POST", path: "/api/v1/tasks/" + queued.Id + "/resume", status: 401},
+		{method: "POST", path: "/api/v1/tasks/" + missing + "/resume", token: "key-ops", status: 404},
+		{method: "POST", path: "/api/v1/tasks/not-a-uuid/resume", token: "key-ops", status: 400},
+
+		// cancelTask
+		{method: "POST", path: "/api/v1/tasks/" + queued.Id + "/cancel", token: "key-dash", status: 403},
+		{method: "POST", path: "/api/v1/tasks/" + queued.Id + "/cancel", token: "key-a", status: 200},
+		{method: "POST", path: "/api/v1/tasks/" + queued.Id + "/cancel", token: "key-ops", status: 409},
+		{method: "POST", path: "/api/v1/tasks/" + queued.Id + "/cancel", status: 401},
+		{method: "POST", path: "/api/v1/tasks/" + missing + "/cancel", token: "key-a", status: 404},
+		{method: "POST", path: "/api/v1/tasks/not-a-uuid/cancel", token: "key-a", status: 400},
+
+		// getDispatch, pauseDispatch, resumeDispatch
+		{method: "GET", path: "/api/v1/dispatch", token: "key-ops", status: 200},
+		{method: "GET", path: "/api/v1/dispatch", token: "key-dash", status: 403},
+		{method: "GET", path: "/api/v1/dispatch", status: 401},
+		{method: "POST", path: "/api/v1/dispatch/pause", token: "key-a", status: 403},
+		{method: "POST", path: "/api/v1/dispatch/pause", status: 401},
+		{method: "POST", path: "/api/v1/dispatch/resume", token: "key-a", status: 403},
+		{method: "POST", path: "/api/v1/dispatch/resume", status: 401},
+		{method: "POST", path: "/api/v1/dispatch/resume", token: "key-ops", status: 200},
+	}
+	for _, req := range requests {
+		h.do(t, router, req)
+	}
+
+	// Превышение лимита запросов для каждой операции: первый запрос исчерпывает burst
+	for _, op := range spec.Operations {
+		req := contractRequest{method: op.Method, path: strings.NewReplacer("{taskId}", missing, "{namespace}", "team-a").Replace(op.Path), token: "key-a"}
+		if op.RequestBody != nil {
+			req.body = `{"name":"Throttled Task"}`
+		}
+		throttled.ServeHTTP(httptest.NewRecorder(), req.build())
+		req.status = http.StatusTooManyRequests
+		h.do(t, throttled, req)
+	}
+
+	// Каждый объявленный ответ каждой операции получен хотя бы раз
+	for _, op := range spec.Operations {
+		var missed []string
+		for code := range op.Responses {
+			if !h.covered[op.ID][code] {
+				missed = append(missed, code)
+			}
+		}
+		sort.Strings(missed)
+		if len(missed) > 0 {
+			t.Errorf("%s: declared responses %v are not covered by the contract test", op.ID, missed)
+		}
+	}
+}
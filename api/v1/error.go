@@ -15,6 +15,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"workmate/pkg"
 )
 
 var (
@@ -55,21 +56,34 @@ type ErrorHandler func(w http.ResponseWriter, r *http.Request, err error, result
 
 // DefaultErrorHandler defines the default logic on how to handle errors from the controller. Any errors from parsing
 // request params will return a StatusBadRequest. Otherwise, the error code originating from the servicer will be used.
-func DefaultErrorHandler(w http.ResponseWriter, _ *http.Request, err error, result *ImplResponse) {
+// Errors are encoded as application/problem+json (RFC 7807), see NewProblem.
+func DefaultErrorHandler(w http.ResponseWriter, r *http.Request, err error, result *ImplResponse) {
 	var parsingErr *ParsingError
 	if ok := errors.As(err, &parsingErr); ok {
 		// Handle parsing errors
-		_ = EncodeJSONResponse(err.Error(), func(i int) *int { return &i }(http.StatusBadRequest), w)
+		kind := pkg.ErrMalformedRequest
+		if parsingErr.Param != "" {
+			kind = pkg.ErrInvalidParameters
+		}
+		WriteProblem(w, NewProblem(r.Context(), fmt.Errorf("%w: %v", kind, err)), nil)
 		return
 	}
 
 	var requiredErr *RequiredError
 	if ok := errors.As(err, &requiredErr); ok {
 		// Handle missing required errors
-		_ = EncodeJSONResponse(err.Error(), func(i int) *int { return &i }(http.StatusUnprocessableEntity), w)
+		WriteProblem(w, NewProblem(r.Context(), fmt.Errorf("%w: %v", pkg.ErrInvalidBody, err)), nil)
 		return
-	} 
+	}
 
 	// Handle all other errors
-	_ = EncodeJSONResponse(err.Error(), &result.Code, w)
+	problem := NewProblem(r.Context(), err)
+	if result != nil && result.Code != 0 {
+		problem.Status = int32(result.Code)
+	}
+	var headers map[string][]string
+	if result != nil {
+		headers = result.Headers
+	}
+	WriteProblem(w, problem, headers)
 }
@@ -0,0 +1,56 @@
+package openapi
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"workmate/pkg"
+)
+
+// ETag - Значение заголовка ETag для версии задачи: "3"
+func ETag(version uint64) string {
+	return `"` + strconv.FormatUint(version, 10) + `"`
+}
+
+// matchETag - Совпадает ли версия с одним из ETag заголовка If-Match или If-None-Match
+// (список через запятую или *). Слабые ETag (W/"3") совпадают только при weak - для If-None-Match
+func matchETag(header string, version uint64, weak bool) bool {
+	etag := ETag(version)
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strings.HasPrefix(candidate, "W/") {
+			if !weak {
+				continue
+			}
+			candidate = candidate[2:]
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatch - Условие удаления или изменения по заголовку If-Match; nil - заголовка нет
+func ifMatch(header string) func(version uint64) bool {
+	if header == "" {
+		return nil
+	}
+	return func(version uint64) bool {
+		return matchETag(header, version, false)
+	}
+}
+
+// taskResponse - Ответ с задачей и ее ETag; 304 без тела, если версия совпала с If-None-Match.
+// Продолжительность выполняющейся задачи считается до момента now и в версию не входит
+func taskResponse(code int, task pkg.InternalTask, now time.Time, ifNoneMatch string) ImplResponse {
+	headers := map[string][]string{"ETag": {ETag(task.Version)}}
+	if ifNoneMatch != "" && matchETag(ifNoneMatch, task.Version, true) {
+		return ResponseWithHeaders(http.StatusNotModified, headers, nil)
+	}
+	return ResponseWithHeaders(code, headers, TaskResponse{Task: MapInternalTaskToAPI(task, now)})
+}
@@ -0,0 +1,51 @@
+package openapi
+
+import (
+	"testing"
+	"time"
+	"workmate/pkg"
+)
+
+func TestMatchETag(t *testing.T) {
+	tests := []struct {
+		header string
+		weak   bool
+		match  bool
+	}{
+		{`"3"`, false, true},
+		{`"2", "3"`, false, true},
+		{`*`, false, true},
+		{`"4"`, false, false},
+		{`3`, false, false},
+		{`W/"3"`, false, false},
+		{`W/"3"`, true, true},
+		{`"1", W/"3"`, true, true},
+	}
+	for _, test := range tests {
+		if match := matchETag(test.header, 3, test.weak); match != test.match {
+			t.Errorf("matchETag(%s, 3, %t) = %t, expected %t", test.header, test.weak, match, test.match)
+		}
+	}
+}
+
+func TestTaskResponseETag(t *testing.T) {
+	task := pkg.InternalTask{Id: "task", Name: "Test Task", Status: pkg.TaskStatusCompleted, Version: 7}
+
+	resp := taskResponse(200, task, time.Time{}, "")
+	assertResponseCode(t, 200, resp.Code)
+	if etag := resp.Headers["ETag"]; len(etag) != 1 || etag[0] != `"7"` {
+		t.Errorf(`Expected ETag "7", got %v`, etag)
+	}
+	if body, ok := resp.Body.(TaskResponse); !ok || body.Task.Version != 7 {
+		t.Errorf("Expected TaskResponse with version 7, got %+v", resp.Body)
+	}
+
+	// Неизмененная задача - 304 без тела, измененная - полный ответ
+	resp = taskResponse(200, task, time.Time{}, `"6", "7"`)
+	assertResponseCode(t, 304, resp.Code)
+	if resp.Body != nil {
+		t.Errorf("Expected no body for 304, got %+v", resp.Body)
+	}
+	resp = taskResponse(200, task, time.Time{}, `"6"`)
+	assertResponseCode(t, 200, resp.Code)
+}
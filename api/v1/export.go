@@ -0,0 +1,231 @@
+package openapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"strings"
+	"time"
+	"workmate/internal"
+	"workmate/pkg"
+)
+
+// Форматы потоковой выгрузки задач
+const (
+	ExportFormatNDJSON = "ndjson"
+	ExportFormatCSV    = "csv"
+)
+
+// Типы содержимого выгрузки
+const (
+	NDJSONContentType = "application/x-ndjson"
+	CSVContentType    = "text/csv; charset=utf-8"
+)
+
+// ExportColumns - колонки выгрузки (имена полей Task) в порядке по умолчанию
+var ExportColumns = []string{"id", "name", "status", "createdAt", "startedAt", "finishedAt", "result", "error", "duration", "owner", "namespace", "pausedAt", "checkpointAt", "checkpoint"}
+
+// StreamingBody - тело ответа, которое пишется потоком, а не кодируется в JSON целиком
+type StreamingBody interface {
+	// ContentType - Тип содержимого ответа
+	ContentType() string
+	// WriteTo - Записать тело ответа
+	WriteTo(w io.Writer) (int64, error)
+}
+
+// NegotiateExportFormat - Формат выгрузки по заголовку Accept: первый из известных типов, иначе NDJSON
+func NegotiateExportFormat(accept string) string {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case "text/csv":
+			return ExportFormatCSV
+		case NDJSONContentType:
+			return ExportFormatNDJSON
+		}
+	}
+	return ExportFormatNDJSON
+}
+
+// taskExport - потоковая выгрузка задач: задачи читаются из хранилища по мере записи ответа
+type taskExport struct {
+	ctx     context.Context
+	service *internal.Service
+	filter  pkg.TaskFilter
+	format  string
+	columns []string
+}
+
+// newTaskExport - Проверить формат и колонки выгрузки (пустой список - все колонки)
+func newTaskExport(ctx context.Context, service *internal.Service, filter pkg.TaskFilter, format string, columns []string) (*taskExport, error) {
+	if format != ExportFormatNDJSON && format != ExportFormatCSV {
+		return nil, fmt.Errorf("%w: unknown export format %q", pkg.ErrInvalidParameters, format)
+	}
+	if len(columns) == 0 {
+		columns = ExportColumns
+	}
+	for _, column := range columns {
+		if !isExportColumn(column) {
+			return nil, fmt.Errorf("%w: unknown export column %q", pkg.ErrInvalidParameters, column)
+		}
+	}
+	return &taskExport{ctx: ctx, service: service, filter: filter, format: format, columns: columns}, nil
+}
+
+// isExportColumn - Является ли строка колонкой выгрузки
+func isExportColumn(column string) bool {
+	for _, c := range ExportColumns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// ContentType - Тип содержимого выгрузки
+func (e *taskExport) ContentType() string {
+	if e.format == ExportFormatCSV {
+		return CSVContentType
+	}
+	return NDJSONContentType
+}
+
+// WriteTo - Записать выгрузку. Статус ответа к этому моменту уже отправлен,
+// поэтому ошибка в середине потока только обрывает ответ и попадает в лог
+func (e *taskExport) WriteTo(w io.Writer) (int64, error) {
+	counter := &countingWriter{w: w}
+	out := bufio.NewWriter(counter)
+	var write func(task Task) error
+	var flush func() error
+
+	if e.format == ExportFormatCSV {
+		records := csv.NewWriter(out)
+		write = func(task Task) error {
+			return records.Write(e.csvRecord(task))
+		}
+		flush = func() error {
+			records.Flush()
+			return records.Error()
+		}
+		if err := records.Write(e.columns); err != nil {
+			return counter.n, err
+		}
+	} else {
+		write = func(task Task) error {
+			return e.writeNDJSON(out, task)
+		}
+		flush = func() error { return nil }
+	}
+
+	now := e.service.Now()
+	err := e.service.ExportTasks(e.ctx, e.filter, func(task pkg.InternalTask) error {
+		return write(MapTaskRecordToAPI(task, now))
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err == nil {
+		err = out.Flush()
+	}
+	if err != nil {
+		log.Printf("export aborted (request %s): %v", RequestIDFromContext(e.ctx), err)
+	}
+	return counter.n, err
+}
+
+// csvRecord - Строка CSV: незаполненные поля остаются пустыми
+func (e *taskExport) csvRecord(task Task) []string {
+	record := make([]string, len(e.columns))
+	for i, column := range e.columns {
+		switch value := exportField(task, column).(type) {
+		case time.Time:
+			if !value.IsZero() {
+				record[i] = value.Format(time.RFC3339Nano)
+			}
+		case string:
+			record[i] = value
+		}
+	}
+	return record
+}
+
+// writeNDJSON - Записать задачу JSON объектом в строку; колонки идут в запрошенном порядке,
+// незаполненные поля опускаются
+func (e *taskExport) writeNDJSON(w io.Writer, task Task) error {
+	line := []byte{'{'}
+	for _, column := range e.columns {
+		value := exportField(task, column)
+		if value == "" || value == (time.Time{}) {
+			continue
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		if len(line) > 1 {
+			line = append(line, ',')
+		}
+		line = append(line, '"')
+		line = append(line, column...)
+		line = append(line, '"', ':')
+		line = append(line, data...)
+	}
+	line = append(line, '}', '\n')
+	_, err := w.Write(line)
+	return err
+}
+
+// exportField - Значение колонки задачи (string или time.Time)
+func exportField(task Task, column string) interface{} {
+	switch column {
+	case "id":
+		return task.Id
+	case "name":
+		return task.Name
+	case "status":
+		return task.Status
+	case "createdAt":
+		return task.CreatedAt
+	case "startedAt":
+		return task.StartedAt
+	case "finishedAt":
+		return task.FinishedAt
+	case "result":
+		return task.Result
+	case "error":
+		return task.Error
+	case "duration":
+		return task.Duration
+	case "owner":
+		return task.Owner
+	case "namespace":
+		return task.Namespace
+	case "pausedAt":
+		return task.PausedAt
+	case "checkpointAt":
+		return task.CheckpointAt
+	case "checkpoint":
+		return task.Checkpoint
+	}
+	return ""
+}
+
+// countingWriter - io.Writer, считающий записанные байты
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
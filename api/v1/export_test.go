@@ -0,0 +1,103 @@
+package openapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"workmate/pkg"
+)
+
+func TestNegotiateExportFormat(t *testing.T) {
+	cases := map[string]string{
+		"":                               ExportFormatNDJSON,
+		"*/*":                            ExportFormatNDJSON,
+		"text/csv":                       ExportFormatCSV,
+		"text/html, text/csv;q=0.9":      ExportFormatCSV,
+		"application/x-ndjson, text/csv": ExportFormatNDJSON,
+		"not a media type;;, text/csv":   ExportFormatCSV,
+	}
+	for accept, expected := range cases {
+		if format := NegotiateExportFormat(accept); format != expected {
+			t.Errorf("NegotiateExportFormat(%q) = %s, expected %s", accept, format, expected)
+		}
+	}
+}
+
+func TestExportTasks(t *testing.T) {
+	service := NewTasksAPIService()
+	router := NewRouter(NewTasksAPIController(service))
+	ctx := context.Background()
+	for _, name := range []string{"Task 1", "Task, \"quoted\""} {
+		service.CreateTask(ctx, CreateTaskRequest{Name: name})
+	}
+
+	export := func(target, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("Accept", accept)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// NDJSON по умолчанию: задача в строке, незаполненные поля опущены
+	rec := export("/api/v1/tasks/export", "")
+	assertResponseCode(t, http.StatusOK, rec.Code)
+	if ct := rec.Header().Get("Content-Type"); ct != NDJSONContentType {
+		t.Errorf("Expected Content-Type %s, got %s", NDJSONContentType, ct)
+	}
+	var names []string
+	lines := bufio.NewScanner(rec.Body)
+	for lines.Scan() {
+		var task map[string]interface{}
+		if err := json.Unmarshal(lines.Bytes(), &task); err != nil {
+			t.Fatalf("decode line %q: %v", lines.Text(), err)
+		}
+		if _, ok := task["finishedAt"]; ok {
+			t.Errorf("Expected empty finishedAt to be omitted: %s", lines.Text())
+		}
+		names = append(names, task["name"].(string))
+	}
+	if strings.Join(names, "|") != `Task 1|Task, "quoted"` {
+		t.Errorf("Unexpected exported names %q", names)
+	}
+
+	// CSV по заголовку Accept с выбранными колонками в заданном порядке
+	rec = export("/api/v1/tasks/export?columns=name,status,finishedAt&limit=1", "text/csv")
+	assertResponseCode(t, http.StatusOK, rec.Code)
+	if ct := rec.Header().Get("Content-Type"); ct != CSVContentType {
+		t.Errorf("Expected Content-Type %s, got %s", CSVContentType, ct)
+	}
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("decode CSV: %v", err)
+	}
+	expected := [][]string{{"name", "status", "finishedAt"}, {"Task 1", pkg.TaskStatusPending, ""}}
+	if len(records) != len(expected) || strings.Join(records[0], ",") != strings.Join(expected[0], ",") ||
+		strings.Join(records[1], ",") != strings.Join(expected[1], ",") {
+		t.Errorf("Expected CSV %q, got %q", expected, records)
+	}
+
+	// Параметр format важнее заголовка Accept
+	rec = export("/api/v1/tasks/export?format=ndjson&columns=id", "text/csv")
+	if ct := rec.Header().Get("Content-Type"); ct != NDJSONContentType || strings.Count(rec.Body.String(), "\n") != 2 {
+		t.Errorf("Expected 2 NDJSON lines, got %s: %q", ct, rec.Body.String())
+	}
+
+	// Ошибки параметров возвращаются до начала выгрузки
+	for _, target := range []string{
+		"/api/v1/tasks/export?columns=id,callbackUrl",
+		"/api/v1/tasks/export?format=xml",
+		"/api/v1/tasks/export?status=done",
+	} {
+		rec = export(target, "")
+		assertResponseCode(t, http.StatusBadRequest, rec.Code)
+		if ct := rec.Header().Get("Content-Type"); ct != ProblemContentType {
+			t.Errorf("%s: expected Content-Type %s, got %s", target, ProblemContentType, ct)
+		}
+	}
+}
@@ -37,6 +37,15 @@ func Response(code int, body interface{}) ImplResponse {
 	}
 }
 
+// ResponseWithHeaders return a ImplResponse struct filled, including headers
+func ResponseWithHeaders(code int, headers map[string][]string, body interface{}) ImplResponse {
+	return ImplResponse {
+		Code: code,
+		Headers: headers,
+		Body: body,
+	}
+}
+
 // IsZeroValue checks if the val is the zero-ed value.
 func IsZeroValue(val interface{}) bool {
 	return val == nil || reflect.DeepEqual(val, reflect.Zero(reflect.TypeOf(val)).Interface())
@@ -76,8 +85,13 @@ func AssertRecurseValueRequired[T any](value reflect.Value, callback func(T) err
 }
 
 // EncodeJSONResponse uses the json encoder to write an interface to the http response with an optional status code
-func EncodeJSONResponse(i interface{}, status *int, w http.ResponseWriter) error {
+func EncodeJSONResponse(i interface{}, status *int, headers map[string][]string, w http.ResponseWriter) error {
 	wHeader := w.Header()
+	for key, values := range headers {
+		for _, value := range values {
+			wHeader.Add(key, value)
+		}
+	}
 
 	f, ok := i.(*os.File)
 	if ok {
@@ -95,7 +109,21 @@ func EncodeJSONResponse(i interface{}, status *int, w http.ResponseWriter) error
 		_, err = w.Write(data)
 		return err
 	}
-	wHeader.Set("Content-Type", "application/json; charset=UTF-8")
+	if body, ok := i.(StreamingBody); ok {
+		wHeader.Set("Content-Type", body.ContentType())
+		if status != nil {
+			w.WriteHeader(*status)
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+		_, err := body.WriteTo(w)
+		return err
+	}
+	if _, ok := i.(Problem); ok {
+		wHeader.Set("Content-Type", ProblemContentType)
+	} else {
+		wHeader.Set("Content-Type", "application/json; charset=UTF-8")
+	}
 
 	if status != nil {
 		w.WriteHeader(*status)
@@ -0,0 +1,74 @@
+package openapi
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"workmate/pkg"
+)
+
+// decodeImport - Разобрать NDJSON выгрузку в задачи, пустые строки пропускаются.
+// lines - номер строки (с 0) каждой задачи, violations - строки, которые не являются записью Task
+func decodeImport(body io.Reader) (tasks []pkg.InternalTask, lines []int, violations []Violation, err error) {
+	reader := bufio.NewReader(body)
+	for line := 0; ; line++ {
+		data, readErr := reader.ReadBytes('\n')
+		var tooLarge *http.MaxBytesError
+		if errors.As(readErr, &tooLarge) {
+			return nil, nil, nil, fmt.Errorf("%w: limit is %d bytes", pkg.ErrPayloadTooLarge, tooLarge.Limit)
+		}
+		if readErr != nil && readErr != io.EOF {
+			return nil, nil, nil, fmt.Errorf("%w: read body: %v", pkg.ErrMalformedRequest, readErr)
+		}
+
+		if data = bytes.TrimSpace(data); len(data) > 0 {
+			task, err := decodeImportRecord(data)
+			if err != nil {
+				violations = append(violations, Violation{In: "body", Pointer: fmt.Sprintf("/%d", line), Message: err.Error()})
+			} else {
+				tasks = append(tasks, MapAPITaskToInternal(task))
+				lines = append(lines, line)
+			}
+		}
+		if readErr == io.EOF {
+			return tasks, lines, violations, nil
+		}
+	}
+}
+
+// decodeImportRecord - Разобрать строку выгрузки: ровно один объект Task без лишних полей
+func decodeImportRecord(data []byte) (task Task, err error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+	if err = d.Decode(&task); err != nil {
+		return task, fmt.Errorf("invalid task record: %v", err)
+	}
+	if d.More() {
+		return task, errors.New("invalid task record: more than one JSON value in the line")
+	}
+	if _, err = base64.StdEncoding.DecodeString(task.Checkpoint); err != nil {
+		return task, fmt.Errorf("invalid task record: checkpoint is not base64: %v", err)
+	}
+	return task, nil
+}
+
+// recordViolations - Нарушения по ошибкам записей импорта, адресованные номером строки
+func recordViolations(records *pkg.RecordErrors, lines []int) []Violation {
+	indexes := make([]int, 0, len(records.Records))
+	for i := range records.Records {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	violations := make([]Violation, len(indexes))
+	for n, i := range indexes {
+		violations[n] = Violation{In: "body", Pointer: fmt.Sprintf("/%d", lines[i]), Message: records.Records[i].Error()}
+	}
+	return violations
+}
@@ -0,0 +1,86 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"workmate/pkg"
+)
+
+func TestImportTasks(t *testing.T) {
+	ctx := context.Background()
+	source := NewTasksAPIService()
+	for _, name := range []string{"Task 1", "Task 2"} {
+		source.CreateTask(ctx, CreateTaskRequest{Name: name})
+	}
+	rec := httptest.NewRecorder()
+	NewRouter(NewTasksAPIController(source)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/tasks/export", nil))
+	dump := rec.Body.String()
+
+	// Выгрузка одного экземпляра загружается в другой без изменений
+	target := NewTasksAPIService()
+	resp, _ := target.ImportTasks(ctx, pkg.ImportConflictFail, false, strings.NewReader(dump))
+	assertResponseCode(t, http.StatusOK, resp.Code)
+	report, ok := resp.Body.(ImportReport)
+	if !ok {
+		t.Fatalf("Expected ImportReport, got %T", resp.Body)
+	}
+	if report.Created != 2 || len(report.Records) != 2 || report.Records[1].Line != 1 {
+		t.Errorf("Unexpected import report: %+v", report)
+	}
+	for _, record := range report.Records {
+		task, err := target.service.GetTask(ctx, record.Id)
+		original, _ := source.service.GetTask(ctx, record.Id)
+		if err != nil || task.Name != original.Name || !task.CreatedAt.Equal(original.CreatedAt) {
+			t.Errorf("Imported task %s = %+v, %v; expected %+v", record.Id, task, err, original)
+		}
+	}
+
+	// Нарушения адресуются номером строки, пустые строки учитываются
+	body := "\n" + strings.SplitN(dump, "\n", 2)[0] + "\n" + `{"id":"42","name":"Task","status":"pending","createdAt":"2024-01-15T15:04:05Z","priority":1}`
+	resp, _ = NewTasksAPIService().ImportTasks(ctx, pkg.ImportConflictFail, false, strings.NewReader(body))
+	assertResponseCode(t, http.StatusUnprocessableEntity, resp.Code)
+	problem := resp.Body.(Problem)
+	if problem.Code != pkg.ErrInvalidTaskRecord.Code || len(problem.Violations) != 1 || problem.Violations[0].Pointer != "/2" {
+		t.Errorf("Expected violation for line 2, got %+v", problem)
+	}
+
+	// Конфликт id при политике fail
+	resp, _ = target.ImportTasks(ctx, pkg.ImportConflictFail, false, strings.NewReader(dump))
+	assertResponseCode(t, http.StatusConflict, resp.Code)
+	problem = resp.Body.(Problem)
+	if problem.Code != pkg.ErrTaskExists.Code || len(problem.Violations) != 2 {
+		data, _ := json.Marshal(problem)
+		t.Errorf("Expected conflicts for both lines, got %s", data)
+	}
+}
+
+func TestImportCheckpoint(t *testing.T) {
+	ctx := context.Background()
+	service := NewTasksAPIService()
+
+	// Контрольная точка переносится выгрузкой, но не видна в ответах о задаче
+	record := `{"id":"550e8400-e29b-41d4-a716-446655440000","name":"Task","status":"paused","createdAt":"2024-01-15T15:04:05Z",` +
+		`"pausedAt":"2024-01-15T15:05:00Z","checkpointAt":"2024-01-15T15:05:00Z","checkpoint":"c3RlcC0x"}`
+	resp, _ := service.ImportTasks(ctx, pkg.ImportConflictFail, false, strings.NewReader(record))
+	assertResponseCode(t, http.StatusOK, resp.Code)
+	task, _ := service.service.GetTask(ctx, "550e8400-e29b-41d4-a716-446655440000")
+	if string(task.Checkpoint) != "step-1" {
+		t.Errorf("Expected imported checkpoint step-1, got %q", task.Checkpoint)
+	}
+	if apiTask := MapInternalTaskToAPI(task, task.CreatedAt); apiTask.Checkpoint != "" || apiTask.CheckpointAt.IsZero() {
+		t.Errorf("Expected only checkpointAt in task responses, got %+v", apiTask)
+	}
+
+	rec := httptest.NewRecorder()
+	NewRouter(NewTasksAPIController(service)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/tasks/export", nil))
+	if !strings.Contains(rec.Body.String(), `"checkpoint":"c3RlcC0x"`) {
+		t.Errorf("Expected checkpoint in export, got %s", rec.Body.String())
+	}
+
+	resp, _ = NewTasksAPIService().ImportTasks(ctx, pkg.ImportConflictFail, false, strings.NewReader(strings.Replace(record, "c3RlcC0x", "not base64!", 1)))
+	assertResponseCode(t, http.StatusUnprocessableEntity, resp.Code)
+}
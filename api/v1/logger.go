@@ -15,6 +15,7 @@ import (
 	"net/http"
 	"log"
 	"time"
+	"workmate/pkg"
 )
 
 func Logger(inner http.Handler, name string) http.Handler {
@@ -23,11 +24,18 @@ func Logger(inner http.Handler, name string) http.Handler {
 
 		inner.ServeHTTP(w, r)
 
+		principal := "-"
+		if p, ok := pkg.PrincipalFromContext(r.Context()); ok {
+			principal = p.Name
+		}
+
 		log.Printf(
-			"%s %s %s %s",
+			"%s %s %s %s %s %s",
 			r.Method,
 			r.RequestURI,
 			name,
+			principal,
+			RequestIDFromContext(r.Context()),
 			time.Since(start),
 		)
 	})
@@ -18,6 +18,12 @@ type CreateTaskRequest struct {
 
 	// Название задачи
 	Name string `json:"name"`
+
+	// Webhook, на который после перехода задачи в финальный статус отправляется POST с телом TaskResponse. Тело подписывается HMAC-SHA256 в заголовке X-WorkMate-Signature: sha256=<hex>, событие передается в X-WorkMate-Event 
+	CallbackUrl string `json:"callbackUrl,omitempty"`
+
+	// Финальные статусы, о которых нужно уведомлять (по умолчанию - все)
+	CallbackEvents []string `json:"callbackEvents,omitempty"`
 }
 
 // AssertCreateTaskRequestRequired checks if the required fields are not zero-ed
@@ -1,40 +0,0 @@
-// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
-
-/*
- * WorkMate Task Manager API
- *
- * API для управления длительными I/O задачами
- *
- * API version: 1.0.0
- * Contact: support@example.com
- */
-
-package openapi
-
-
-
-
-type ErrorResponse struct {
-
-	// Описание ошибки
-	Error string `json:"error"`
-}
-
-// AssertErrorResponseRequired checks if the required fields are not zero-ed
-func AssertErrorResponseRequired(obj ErrorResponse) error {
-	elements := map[string]interface{}{
-		"error": obj.Error,
-	}
-	for name, el := range elements {
-		if isZero := IsZeroValue(el); isZero {
-			return &RequiredError{Field: name}
-		}
-	}
-
-	return nil
-}
-
-// AssertErrorResponseConstraints checks if the values respects the defined constraints
-func AssertErrorResponseConstraints(obj ErrorResponse) error {
-	return nil
-}
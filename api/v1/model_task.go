@@ -23,6 +23,9 @@ type Task struct {
 	// Уникальный идентификатор задачи
 	Id string `json:"id"`
 
+	// Название задачи
+	Name string `json:"name"`
+
 	// Текущий статус задачи
 	Status string `json:"status"`
 
@@ -35,20 +38,45 @@ type Task struct {
 	// Время завершения задачи
 	FinishedAt time.Time `json:"finishedAt,omitempty"`
 
+	// Время приостановки задачи (только для приостановленных задач)
+	PausedAt time.Time `json:"pausedAt,omitempty"`
+
+	// Время последней контрольной точки исполнителя (сбрасывается при успешном завершении)
+	CheckpointAt time.Time `json:"checkpointAt,omitempty"`
+
+	// Контрольная точка исполнителя в base64 (не больше 64 КБ данных). Передается только в выгрузке и при импорте, чтобы восстановленная задача продолжила с сохраненного места
+	Checkpoint string `json:"checkpoint,omitempty"`
+
 	// Результат выполнения задачи (только для завершенных задач)
 	Result string `json:"result,omitempty"`
 
+	// Метаданные выходных данных задачи; содержимое - GET /tasks/{taskId}/result/content
+	Output *TaskOutput `json:"output,omitempty"`
+
 	// Описание ошибки (только для неудачных задач)
 	Error string `json:"error,omitempty"`
 
-	// Продолжительность выполнения задачи
+	// Продолжительность выполнения задачи (до завершения или до момента ответа), округленная до секунд
 	Duration string `json:"duration,omitempty"`
+
+	// Продолжительность выполнения задачи в миллисекундах
+	DurationMs int64 `json:"durationMs,omitempty"`
+
+	// Владелец задачи (аутентифицированный клиент, создавший задачу)
+	Owner string `json:"owner,omitempty"`
+
+	// Пространство имен задачи
+	Namespace string `json:"namespace,omitempty"`
+
+	// Версия задачи, растет при каждом изменении (совпадает с ETag)
+	Version int64 `json:"version,omitempty"`
 }
 
 // AssertTaskRequired checks if the required fields are not zero-ed
 func AssertTaskRequired(obj Task) error {
 	elements := map[string]interface{}{
 		"id": obj.Id,
+		"name": obj.Name,
 		"status": obj.Status,
 		"createdAt": obj.CreatedAt,
 	}
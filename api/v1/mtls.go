@@ -0,0 +1,52 @@
+package openapi
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"workmate/pkg"
+)
+
+// LoadClientCAs - Загрузить PEM бандл CA, которым подписаны клиентские сертификаты
+func LoadClientCAs(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("%s: no PEM certificates found", path)
+	}
+	return pool, nil
+}
+
+// RequireClientCertificates - Включить обязательную проверку клиентских сертификатов (mTLS)
+func RequireClientCertificates(config *tls.Config, clientCAs *x509.CertPool) {
+	config.ClientCAs = clientCAs
+	config.ClientAuth = tls.RequireAndVerifyClientCert
+}
+
+// PrincipalFromCertificate - Сопоставить клиентский сертификат принципалу.
+// Имя берется из CN, а при его отсутствии - из первого SAN (URI, email, DNS).
+// Значения OU трактуются как права доступа (например, admin)
+func PrincipalFromCertificate(cert *x509.Certificate) (pkg.Principal, error) {
+	name := cert.Subject.CommonName
+	switch {
+	case name != "":
+	case len(cert.URIs) > 0:
+		name = cert.URIs[0].String()
+	case len(cert.EmailAddresses) > 0:
+		name = cert.EmailAddresses[0]
+	case len(cert.DNSNames) > 0:
+		name = cert.DNSNames[0]
+	default:
+		return pkg.Principal{}, fmt.Errorf("client certificate has neither CN nor SAN")
+	}
+
+	return pkg.Principal{
+		Name:   name,
+		Scopes: cert.Subject.OrganizationalUnit,
+	}, nil
+}
@@ -0,0 +1,101 @@
+package openapi
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"workmate/pkg"
+)
+
+// Вспомогательная функция: выпустить сертификат, подписанный parent (или самоподписанный)
+func issueCert(t *testing.T, tmpl *x509.Certificate, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func TestClientCertificateAuthentication(t *testing.T) {
+	ca, caKey := issueCert(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil, nil)
+	client, clientKey := issueCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "alice", OrganizationalUnit: []string{pkg.ScopeAdmin}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca, caKey)
+
+	auth, err := NewAuthenticator(nil, WithClientCertificates())
+	if err != nil {
+		t.Fatalf("NewAuthenticator() returned error: %v", err)
+	}
+
+	var got pkg.Principal
+	server := httptest.NewUnstartedServer(auth.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = pkg.PrincipalFromContext(r.Context())
+	})))
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+	server.TLS = &tls.Config{}
+	RequireClientCertificates(server.TLS, pool)
+	server.StartTLS()
+	defer server.Close()
+
+	httpClient := server.Client()
+	httpClient.Transport.(*http.Transport).TLSClientConfig.Certificates = []tls.Certificate{{
+		Certificate: [][]byte{client.Raw},
+		PrivateKey:  clientKey,
+	}}
+
+	resp, err := httpClient.Get(server.URL + "/api/v1/tasks")
+	if err != nil {
+		t.Fatalf("GET with client certificate returned error: %v", err)
+	}
+	resp.Body.Close()
+	assertResponseCode(t, http.StatusOK, resp.StatusCode)
+	if got.Name != "alice" || !got.IsAdmin() {
+		t.Errorf("Expected admin principal 'alice', got %+v", got)
+	}
+}
+
+func TestPrincipalFromCertificateSAN(t *testing.T) {
+	principal, err := PrincipalFromCertificate(&x509.Certificate{EmailAddresses: []string{"bob@example.com"}})
+	if err != nil {
+		t.Fatalf("PrincipalFromCertificate() returned error: %v", err)
+	}
+	if principal.Name != "bob@example.com" {
+		t.Errorf("Expected principal 'bob@example.com', got '%s'", principal.Name)
+	}
+
+	if _, err := PrincipalFromCertificate(&x509.Certificate{}); err == nil {
+		t.Error("PrincipalFromCertificate() without CN and SAN should return error")
+	}
+}
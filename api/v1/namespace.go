@@ -0,0 +1,79 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"workmate/pkg"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// tasksPathPrefix - пути операций с задачами пространства имен по умолчанию
+	tasksPathPrefix = "/api/v1/tasks"
+	// namespacedPathPrefix - те же операции в пространстве имен из пути
+	namespacedPathPrefix = "/api/v1/namespaces/{namespace}/tasks"
+)
+
+// NamespaceConfig - лимиты задач пространств имен
+type NamespaceConfig struct {
+	// Default - лимиты пространств, не перечисленных в Namespaces (включая default)
+	Default pkg.Quota `json:"default"`
+	// Namespaces - лимиты отдельных пространств имен
+	Namespaces map[string]pkg.Quota `json:"namespaces,omitempty"`
+}
+
+// LoadNamespaceConfig - Загрузить лимиты пространств имен из JSON файла
+func LoadNamespaceConfig(path string) (*NamespaceConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var config NamespaceConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return &config, nil
+}
+
+// Validate - Проверить имена пространств и лимиты
+func (c *NamespaceConfig) Validate() error {
+	if err := c.Default.Validate(); err != nil {
+		return fmt.Errorf("namespace quota default: %w", err)
+	}
+	for name, quota := range c.Namespaces {
+		if err := pkg.ValidateNamespace(name); err != nil {
+			return fmt.Errorf("namespace quota: %w", err)
+		}
+		if err := quota.Validate(); err != nil {
+			return fmt.Errorf("namespace quota %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// namespacedPattern - Путь операции с задачами в пространстве имен: /api/v1/tasks/{taskId} ->
+// /api/v1/namespaces/{namespace}/tasks/{taskId}. ok == false для прочих операций
+func namespacedPattern(pattern string) (string, bool) {
+	if !strings.HasPrefix(pattern, tasksPathPrefix) {
+		return "", false
+	}
+	return namespacedPathPrefix + strings.TrimPrefix(pattern, tasksPathPrefix), true
+}
+
+// Namespaced - обработчик операции с задачами в пространстве имен из пути:
+// проверяет имя и кладет пространство в контекст запроса
+func Namespaced(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		namespace := mux.Vars(r)["namespace"]
+		if err := pkg.ValidateNamespace(namespace); err != nil {
+			WriteProblem(w, NewProblem(r.Context(), err), nil)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(pkg.WithNamespace(r.Context(), namespace)))
+	})
+}
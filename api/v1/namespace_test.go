@@ -0,0 +1,69 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"workmate/internal"
+	"workmate/pkg"
+)
+
+func TestNamespacedRoutes(t *testing.T) {
+	service := NewTasksAPIService(internal.WithNamespaceQuotas(pkg.Quota{}, map[string]pkg.Quota{
+		"team-a": {MaxActiveTasks: 1},
+	}))
+	router := NewRouter(NewTasksAPIController(service))
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/api/v1/namespaces/team-a/tasks", `{"name":"Team Task"}`)
+	assertResponseCode(t, 201, rec.Code)
+	var created TaskResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &created)
+	if created.Task.Namespace != "team-a" {
+		t.Errorf("Expected task in namespace team-a, got %q", created.Task.Namespace)
+	}
+
+	// Лимит пространства имен не мешает пространству по умолчанию
+	rec = do(http.MethodPost, "/api/v1/namespaces/team-a/tasks", `{"name":"Team Task 2"}`)
+	assertResponseCode(t, 429, rec.Code)
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("Expected Retry-After header on quota_exceeded")
+	}
+	assertResponseCode(t, 201, do(http.MethodPost, "/api/v1/tasks", `{"name":"Default Task"}`).Code)
+
+	// id одного пространства не видны в другом
+	assertResponseCode(t, 200, do(http.MethodGet, "/api/v1/namespaces/team-a/tasks/"+created.Task.Id, "").Code)
+	assertResponseCode(t, 404, do(http.MethodGet, "/api/v1/tasks/"+created.Task.Id, "").Code)
+	assertResponseCode(t, 404, do(http.MethodDelete, "/api/v1/namespaces/team-b/tasks/"+created.Task.Id, "").Code)
+
+	var list TaskListResponse
+	_ = json.Unmarshal(do(http.MethodGet, "/api/v1/tasks", "").Body.Bytes(), &list)
+	if len(list.Tasks) != 1 || list.Tasks[0].Namespace != pkg.DefaultNamespace {
+		t.Errorf("Expected only the default task, got %+v", list.Tasks)
+	}
+
+	assertResponseCode(t, 400, do(http.MethodGet, "/api/v1/namespaces/Team_A/tasks", "").Code)
+}
+
+func TestNamespaceConfigValidate(t *testing.T) {
+	config := &NamespaceConfig{Namespaces: map[string]pkg.Quota{"team-a": {MaxActiveTasks: 5}}}
+	if err := config.Validate(); err != nil {
+		t.Errorf("Validate() returned error: %v", err)
+	}
+	config = &NamespaceConfig{Namespaces: map[string]pkg.Quota{"Team A": {}}}
+	if err := config.Validate(); err == nil {
+		t.Error("Validate() with invalid namespace name should return error")
+	}
+	config = &NamespaceConfig{Default: pkg.Quota{MaxStoredTasks: -1}}
+	if err := config.Validate(); err == nil {
+		t.Error("Validate() with negative quota should return error")
+	}
+}
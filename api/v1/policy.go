@@ -0,0 +1,352 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"time"
+	"workmate/pkg"
+)
+
+// Policy - политика доступа: какие роли у вызывающих и какие роли допускают каждую операцию.
+// Операции называются так же, как маршруты в TasksAPIController.Routes().
+// Роль admin допускает любые операции, операции без записи в политике доступны только admin
+type Policy struct {
+	// Bindings - роли принципалов по имени
+	Bindings map[string][]string `json:"bindings"`
+	// DefaultRoles - роли принципалов, не перечисленных в Bindings
+	DefaultRoles []string `json:"defaultRoles"`
+	// Operations - роли, которым разрешена операция
+	Operations map[string][]string `json:"operations"`
+}
+
+// DefaultPolicy - Политика по умолчанию: viewer читает, submitter создает и отменяет свои задачи,
+// operator отменяет, приостанавливает и удаляет любые задачи; очистка пространства имен
+// и остановка запуска задач - только admin
+func DefaultPolicy() *Policy {
+	return &Policy{
+		Bindings:     map[string][]string{},
+		DefaultRoles: []string{pkg.RoleSubmitter},
+		Operations: map[string][]string{
+			"GetTasks":             {pkg.RoleViewer, pkg.RoleSubmitter, pkg.RoleOperator},
+			"ExportTasks":          {pkg.RoleViewer, pkg.RoleSubmitter, pkg.RoleOperator},
+			"GetTask":              {pkg.RoleViewer, pkg.RoleSubmitter, pkg.RoleOperator},
+			"GetTaskResult":        {pkg.RoleViewer, pkg.RoleSubmitter, pkg.RoleOperator},
+			"GetTaskResultContent": {pkg.RoleViewer, pkg.RoleSubmitter, pkg.RoleOperator},
+			"GetTaskHistory":       {pkg.RoleViewer, pkg.RoleSubmitter, pkg.RoleOperator},
+			"GetTaskDeliveries":    {pkg.RoleViewer, pkg.RoleSubmitter, pkg.RoleOperator},
+			"ListNamespaces":       {pkg.RoleViewer, pkg.RoleOperator},
+			"GetUsage":             {pkg.RoleViewer, pkg.RoleSubmitter, pkg.RoleOperator},
+			"CancelTask":           {pkg.RoleSubmitter, pkg.RoleOperator},
+			"PauseTask":            {pkg.RoleOperator},
+			"ResumeTask":           {pkg.RoleOperator},
+			"CreateTask":           {pkg.RoleSubmitter},
+			"DeleteTask":           {pkg.RoleOperator},
+		},
+	}
+}
+
+// LoadPolicy - Загрузить политику из JSON файла. Незаданные разделы берутся из DefaultPolicy
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var policy Policy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	defaults := DefaultPolicy()
+	if policy.Bindings == nil {
+		policy.Bindings = defaults.Bindings
+	}
+	if policy.DefaultRoles == nil {
+		policy.DefaultRoles = defaults.DefaultRoles
+	}
+	if policy.Operations == nil {
+		policy.Operations = defaults.Operations
+	}
+	return &policy, nil
+}
+
+// Validate - Проверить, что политика ссылается только на существующие операции и роли
+func (p *Policy) Validate(routes Routes) error {
+	known := map[string]bool{
+		pkg.RoleViewer: true, pkg.RoleSubmitter: true, pkg.RoleOperator: true, pkg.RoleAdmin: true,
+	}
+	checkRoles := func(where string, roles []string) error {
+		for _, role := range roles {
+			if !known[role] {
+				return fmt.Errorf("policy %s: unknown role %q", where, role)
+			}
+		}
+		return nil
+	}
+
+	for operation, roles := range p.Operations {
+		if _, exists := routes[operation]; !exists {
+			return fmt.Errorf("policy: unknown operation %q", operation)
+		}
+		if err := checkRoles("operation "+operation, roles); err != nil {
+			return err
+		}
+	}
+	for name, roles := range p.Bindings {
+		if err := checkRoles("binding "+name, roles); err != nil {
+			return err
+		}
+	}
+	return checkRoles("defaultRoles", p.DefaultRoles)
+}
+
+// Resolve - Назначить вызывающему роли: из Bindings (или DefaultRoles) и из его scopes,
+// совпадающих с названиями ролей
+func (p *Policy) Resolve(principal pkg.Principal) pkg.Principal {
+	roles, bound := p.Bindings[principal.Name]
+	if !bound {
+		roles = p.DefaultRoles
+	}
+
+	set := make(map[string]bool, len(roles)+len(principal.Scopes))
+	for _, role := range roles {
+		set[role] = true
+	}
+	for _, scope := range principal.Scopes {
+		switch scope {
+		case pkg.RoleViewer, pkg.RoleSubmitter, pkg.RoleOperator, pkg.RoleAdmin:
+			set[scope] = true
+		}
+	}
+
+	principal.Roles = make([]string, 0, len(set))
+	for role := range set {
+		principal.Roles = append(principal.Roles, role)
+	}
+	sort.Strings(principal.Roles)
+	return principal
+}
+
+// Allows - Разрешена ли операция вызывающему с ролями, назначенными Resolve
+func (p *Policy) Allows(principal pkg.Principal, operation string) bool {
+	if principal.IsAdmin() {
+		return true
+	}
+	for _, role := range p.Operations[operation] {
+		if principal.HasRole(role) {
+			return true
+		}
+	}
+	return false
+}
+
+// TasksAPIPolicy - слой авторизации между TasksAPIController и TasksAPIServicer:
+// проверяет роли вызывающего для каждой операции и логирует отказы
+type TasksAPIPolicy struct {
+	service TasksAPIServicer
+	policy  *Policy
+}
+
+// NewTasksAPIPolicy оборачивает сервис проверкой политики доступа
+func NewTasksAPIPolicy(s TasksAPIServicer, policy *Policy) *TasksAPIPolicy {
+	return &TasksAPIPolicy{
+		service: s,
+		policy:  policy,
+	}
+}
+
+// authorize - Проверить доступ к операции. При отказе возвращает готовый ответ 403,
+// при успехе - контекст с ролями вызывающего
+func (p *TasksAPIPolicy) authorize(ctx context.Context, operation string) (context.Context, *ImplResponse) {
+	principal, ok := pkg.PrincipalFromContext(ctx)
+	if !ok {
+		log.Printf("policy: denied %s for anonymous caller", operation)
+		resp := ProblemResponse(ctx, fmt.Errorf("%w: %s requires an authenticated caller", pkg.ErrForbidden, operation))
+		return ctx, &resp
+	}
+
+	principal = p.policy.Resolve(principal)
+	if !p.policy.Allows(principal, operation) {
+		log.Printf("policy: denied %s for principal %q with roles %v", operation, principal.Name, principal.Roles)
+		resp := ProblemResponse(ctx, fmt.Errorf("%w: %s is not permitted for roles %v", pkg.ErrForbidden, operation, principal.Roles))
+		return ctx, &resp
+	}
+
+	return pkg.WithPrincipal(ctx, principal), nil
+}
+
+// GetTasks - Получить список всех задач
+func (p *TasksAPIPolicy) GetTasks(ctx context.Context, status []string, createdAfter time.Time, createdBefore time.Time, limit int32) (ImplResponse, error) {
+	ctx, denied := p.authorize(ctx, "GetTasks")
+	if denied != nil {
+		return *denied, nil
+	}
+	return p.service.GetTasks(ctx, status, createdAfter, createdBefore, limit)
+}
+
+// ExportTasks - Выгрузить задачи потоком
+func (p *TasksAPIPolicy) ExportTasks(ctx context.Context, status []string, createdAfter time.Time, createdBefore time.Time, limit int32, format string, columns []string) (ImplResponse, error) {
+	ctx, denied := p.authorize(ctx, "ExportTasks")
+	if denied != nil {
+		return *denied, nil
+	}
+	return p.service.ExportTasks(ctx, status, createdAfter, createdBefore, limit, format, columns)
+}
+
+// ImportTasks - Импортировать задачи из выгрузки (в политике по умолчанию - только admin)
+func (p *TasksAPIPolicy) ImportTasks(ctx context.Context, conflict string, requeue bool, body io.Reader) (ImplResponse, error) {
+	ctx, denied := p.authorize(ctx, "ImportTasks")
+	if denied != nil {
+		return *denied, nil
+	}
+	return p.service.ImportTasks(ctx, conflict, requeue, body)
+}
+
+// CreateTask - Создать новую задачу
+func (p *TasksAPIPolicy) CreateTask(ctx context.Context, createTaskRequest CreateTaskRequest) (ImplResponse, error) {
+	ctx, denied := p.authorize(ctx, "CreateTask")
+	if denied != nil {
+		return *denied, nil
+	}
+	return p.service.CreateTask(ctx, createTaskRequest)
+}
+
+// GetTask - Получить информацию о задаче
+func (p *TasksAPIPolicy) GetTask(ctx context.Context, taskId string, ifNoneMatch string) (ImplResponse, error) {
+	ctx, denied := p.authorize(ctx, "GetTask")
+	if denied != nil {
+		return *denied, nil
+	}
+	return p.service.GetTask(ctx, taskId, ifNoneMatch)
+}
+
+// DeleteTask - Удалить задачу
+func (p *TasksAPIPolicy) DeleteTask(ctx context.Context, taskId string, ifMatch string) (ImplResponse, error) {
+	ctx, denied := p.authorize(ctx, "DeleteTask")
+	if denied != nil {
+		return *denied, nil
+	}
+	return p.service.DeleteTask(ctx, taskId, ifMatch)
+}
+
+// GetTaskResult - Получить результат задачи
+func (p *TasksAPIPolicy) GetTaskResult(ctx context.Context, taskId string, ifNoneMatch string) (ImplResponse, error) {
+	ctx, denied := p.authorize(ctx, "GetTaskResult")
+	if denied != nil {
+		return *denied, nil
+	}
+	return p.service.GetTaskResult(ctx, taskId, ifNoneMatch)
+}
+
+// GetTaskResultContent - Получить выходные данные задачи
+func (p *TasksAPIPolicy) GetTaskResultContent(ctx context.Context, taskId string) (ImplResponse, error) {
+	ctx, denied := p.authorize(ctx, "GetTaskResultContent")
+	if denied != nil {
+		return *denied, nil
+	}
+	return p.service.GetTaskResultContent(ctx, taskId)
+}
+
+// GetTaskHistory - Получить историю переходов статуса задачи
+func (p *TasksAPIPolicy) GetTaskHistory(ctx context.Context, taskId string) (ImplResponse, error) {
+	ctx, denied := p.authorize(ctx, "GetTaskHistory")
+	if denied != nil {
+		return *denied, nil
+	}
+	return p.service.GetTaskHistory(ctx, taskId)
+}
+
+// GetTaskDeliveries - Получить историю доставки webhook
+func (p *TasksAPIPolicy) GetTaskDeliveries(ctx context.Context, taskId string) (ImplResponse, error) {
+	ctx, denied := p.authorize(ctx, "GetTaskDeliveries")
+	if denied != nil {
+		return *denied, nil
+	}
+	return p.service.GetTaskDeliveries(ctx, taskId)
+}
+
+// ListNamespaces - Получить список пространств имен
+func (p *TasksAPIPolicy) ListNamespaces(ctx context.Context) (ImplResponse, error) {
+	ctx, denied := p.authorize(ctx, "ListNamespaces")
+	if denied != nil {
+		return *denied, nil
+	}
+	return p.service.ListNamespaces(ctx)
+}
+
+// PurgeNamespace - Очистить пространство имен (в политике по умолчанию - только admin)
+func (p *TasksAPIPolicy) PurgeNamespace(ctx context.Context, namespace string) (ImplResponse, error) {
+	ctx, denied := p.authorize(ctx, "PurgeNamespace")
+	if denied != nil {
+		return *denied, nil
+	}
+	return p.service.PurgeNamespace(ctx, namespace)
+}
+
+// GetUsage - Получить потребление по принципалам (submitter видит только свое)
+func (p *TasksAPIPolicy) GetUsage(ctx context.Context, from time.Time, to time.Time, principal string) (ImplResponse, error) {
+	ctx, denied := p.authorize(ctx, "GetUsage")
+	if denied != nil {
+		return *denied, nil
+	}
+	return p.service.GetUsage(ctx, from, to, principal)
+}
+
+// CancelTask - Отменить задачу
+func (p *TasksAPIPolicy) CancelTask(ctx context.Context, taskId string) (ImplResponse, error) {
+	ctx, denied := p.authorize(ctx, "CancelTask")
+	if denied != nil {
+		return *denied, nil
+	}
+	return p.service.CancelTask(ctx, taskId)
+}
+
+// PauseTask - Приостановить задачу
+func (p *TasksAPIPolicy) PauseTask(ctx context.Context, taskId string) (ImplResponse, error) {
+	ctx, denied := p.authorize(ctx, "PauseTask")
+	if denied != nil {
+		return *denied, nil
+	}
+	return p.service.PauseTask(ctx, taskId)
+}
+
+// ResumeTask - Возобновить задачу
+func (p *TasksAPIPolicy) ResumeTask(ctx context.Context, taskId string) (ImplResponse, error) {
+	ctx, denied := p.authorize(ctx, "ResumeTask")
+	if denied != nil {
+		return *denied, nil
+	}
+	return p.service.ResumeTask(ctx, taskId)
+}
+
+// GetDispatch - Получить состояние запуска задач (в политике по умолчанию - только admin)
+func (p *TasksAPIPolicy) GetDispatch(ctx context.Context) (ImplResponse, error) {
+	ctx, denied := p.authorize(ctx, "GetDispatch")
+	if denied != nil {
+		return *denied, nil
+	}
+	return p.service.GetDispatch(ctx)
+}
+
+// PauseDispatch - Остановить запуск задач (в политике по умолчанию - только admin)
+func (p *TasksAPIPolicy) PauseDispatch(ctx context.Context) (ImplResponse, error) {
+	ctx, denied := p.authorize(ctx, "PauseDispatch")
+	if denied != nil {
+		return *denied, nil
+	}
+	return p.service.PauseDispatch(ctx)
+}
+
+// ResumeDispatch - Возобновить запуск задач (в политике по умолчанию - только admin)
+func (p *TasksAPIPolicy) ResumeDispatch(ctx context.Context) (ImplResponse, error) {
+	ctx, denied := p.authorize(ctx, "ResumeDispatch")
+	if denied != nil {
+		return *denied, nil
+	}
+	return p.service.ResumeDispatch(ctx)
+}
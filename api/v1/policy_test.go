@@ -0,0 +1,71 @@
+package openapi
+
+import (
+	"context"
+	"testing"
+	"time"
+	"workmate/pkg"
+)
+
+func TestTasksAPIPolicy(t *testing.T) {
+	policy := DefaultPolicy()
+	policy.Bindings = map[string][]string{
+		"dash": {pkg.RoleViewer},
+		"ops":  {pkg.RoleOperator},
+	}
+	service := NewTasksAPIPolicy(NewTasksAPIService(), policy)
+
+	alice := pkg.WithPrincipal(context.Background(), pkg.Principal{Name: "alice"})
+	bob := pkg.WithPrincipal(context.Background(), pkg.Principal{Name: "bob"})
+	dash := pkg.WithPrincipal(context.Background(), pkg.Principal{Name: "dash"})
+	ops := pkg.WithPrincipal(context.Background(), pkg.Principal{Name: "ops"})
+
+	// submitter (роль по умолчанию) создает задачу
+	createResp, _ := service.CreateTask(alice, CreateTaskRequest{Name: "Alice Task"})
+	assertResponseCode(t, 201, createResp.Code)
+	taskId := createResp.Body.(TaskResponse).Task.Id
+
+	// viewer читает любые задачи, но не создает и не удаляет
+	resp, _ := service.GetTask(dash, taskId, "")
+	assertResponseCode(t, 200, resp.Code)
+	resp, _ = service.CreateTask(dash, CreateTaskRequest{Name: "Dash Task"})
+	assertResponseCode(t, 403, resp.Code)
+	resp, _ = service.DeleteTask(dash, taskId, "")
+	assertResponseCode(t, 403, resp.Code)
+
+	// submitter не удаляет задачи, даже свои; чужую задачу не может и отменить
+	resp, _ = service.DeleteTask(alice, taskId, "")
+	assertResponseCode(t, 403, resp.Code)
+	resp, _ = service.PauseTask(alice, taskId)
+	assertResponseCode(t, 403, resp.Code)
+	resp, _ = service.CancelTask(bob, taskId)
+	assertResponseCode(t, 404, resp.Code)
+
+	// operator удаляет любые задачи
+	resp, _ = service.DeleteTask(ops, taskId, "")
+	assertResponseCode(t, 204, resp.Code)
+
+	// Без аутентификации операции запрещены
+	resp, _ = service.GetTasks(context.Background(), nil, time.Time{}, time.Time{}, 0)
+	assertResponseCode(t, 403, resp.Code)
+}
+
+func TestPolicyValidate(t *testing.T) {
+	routes := NewTasksAPIController(NewTasksAPIService()).Routes()
+
+	if err := DefaultPolicy().Validate(routes); err != nil {
+		t.Errorf("DefaultPolicy().Validate() returned error: %v", err)
+	}
+
+	policy := DefaultPolicy()
+	policy.Operations["PurgeTasks"] = []string{pkg.RoleOperator}
+	if err := policy.Validate(routes); err == nil {
+		t.Error("Validate() with unknown operation should return error")
+	}
+
+	policy = DefaultPolicy()
+	policy.Bindings["alice"] = []string{"superuser"}
+	if err := policy.Validate(routes); err == nil {
+		t.Error("Validate() with unknown role should return error")
+	}
+}
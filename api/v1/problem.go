@@ -0,0 +1,82 @@
+package openapi
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"workmate/pkg"
+)
+
+const (
+	// ProblemContentType - media type ответов с ошибкой (RFC 7807)
+	ProblemContentType = "application/problem+json"
+
+	// problemTypePrefix - префикс URI типа ошибки, за ним следует код
+	problemTypePrefix = "urn:workmate:problem:"
+)
+
+// problemStatus - HTTP статусы типизированных ошибок pkg
+var problemStatus = map[*pkg.Error]int{
+	pkg.ErrNameRequired:           http.StatusBadRequest,
+	pkg.ErrInvalidCallbackURL:     http.StatusBadRequest,
+	pkg.ErrUnknownCallbackEvent:   http.StatusBadRequest,
+	pkg.ErrUnknownStatus:          http.StatusBadRequest,
+	pkg.ErrInvalidNamespace:       http.StatusBadRequest,
+	pkg.ErrMalformedRequest:       http.StatusBadRequest,
+	pkg.ErrInvalidParameters:      http.StatusBadRequest,
+	pkg.ErrUnauthorized:           http.StatusUnauthorized,
+	pkg.ErrForbidden:              http.StatusForbidden,
+	pkg.ErrTaskNotFound:           http.StatusNotFound,
+	pkg.ErrResultNotFound:         http.StatusNotFound,
+	pkg.ErrTaskCancelled:          http.StatusConflict,
+	pkg.ErrTaskExists:             http.StatusConflict,
+	pkg.ErrIllegalTransition:      http.StatusConflict,
+	pkg.ErrNotSuspendable:         http.StatusConflict,
+	pkg.ErrVersionConflict:        http.StatusPreconditionFailed,
+	pkg.ErrPayloadTooLarge:        http.StatusRequestEntityTooLarge,
+	pkg.ErrInvalidBody:            http.StatusUnprocessableEntity,
+	pkg.ErrInvalidTaskRecord:      http.StatusUnprocessableEntity,
+	pkg.ErrTaskNotCompleted:       http.StatusTooEarly,
+	pkg.ErrTooManyTasks:           http.StatusTooManyRequests,
+	pkg.ErrQuotaExceeded:          http.StatusTooManyRequests,
+	pkg.ErrPrincipalQuotaExceeded: http.StatusTooManyRequests,
+	pkg.ErrRateLimited:            http.StatusTooManyRequests,
+	pkg.ErrInternal:               http.StatusInternalServerError,
+}
+
+// NewProblem - Problem для ошибки: тип, заголовок, статус и код определяются типизированной
+// ошибкой pkg в цепочке err. Текст прочих ошибок не раскрывается клиенту, а логируется
+func NewProblem(ctx context.Context, err error) Problem {
+	e := pkg.AsError(err)
+	status, ok := problemStatus[e]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+
+	detail := err.Error()
+	if e == pkg.ErrInternal && err != pkg.ErrInternal {
+		log.Printf("internal error (request %s): %v", RequestIDFromContext(ctx), err)
+		detail = ""
+	}
+
+	return Problem{
+		Type:      problemTypePrefix + e.Code,
+		Title:     e.Message,
+		Status:    int32(status),
+		Detail:    detail,
+		Code:      e.Code,
+		RequestId: RequestIDFromContext(ctx),
+	}
+}
+
+// ProblemResponse - Ответ сервиса с Problem для ошибки
+func ProblemResponse(ctx context.Context, err error) ImplResponse {
+	problem := NewProblem(ctx, err)
+	return Response(int(problem.Status), problem)
+}
+
+// WriteProblem - Записать Problem в ответ (для middleware, работающих до сервиса)
+func WriteProblem(w http.ResponseWriter, problem Problem, headers map[string][]string) {
+	status := int(problem.Status)
+	_ = EncodeJSONResponse(problem, &status, headers, w)
+}
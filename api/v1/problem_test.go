@@ -0,0 +1,77 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"workmate/pkg"
+)
+
+func TestNewProblem(t *testing.T) {
+	ctx := context.Background()
+
+	// Каждая типизированная ошибка имеет статус и тип, построенный из кода
+	for e, status := range problemStatus {
+		problem := NewProblem(ctx, e)
+		if int(problem.Status) != status || problem.Code != e.Code || problem.Type != problemTypePrefix+e.Code {
+			t.Errorf("NewProblem(%s) = %+v", e.Code, problem)
+		}
+	}
+
+	// Подробности обертки попадают в detail, код определяется по цепочке
+	problem := NewProblem(ctx, fmt.Errorf("%w: %s", pkg.ErrUnknownStatus, "done"))
+	if problem.Code != pkg.ErrUnknownStatus.Code || problem.Detail != "Unknown task status: done" || problem.Status != 400 {
+		t.Errorf("Unexpected problem for wrapped error: %+v", problem)
+	}
+
+	// Текст нетипизированных ошибок не раскрывается
+	problem = NewProblem(ctx, errors.New("disk /var/lib/workmate is full"))
+	if problem.Code != pkg.ErrInternal.Code || problem.Status != 500 || problem.Detail != "" {
+		t.Errorf("Unexpected problem for internal error: %+v", problem)
+	}
+}
+
+func TestProblemResponses(t *testing.T) {
+	router := NewRouter(NewTasksAPIController(NewTasksAPIService()))
+
+	// Идентификатор клиента сохраняется в ответе и теле ошибки
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks/550e8400-e29b-41d4-a716-446655440000", nil)
+	req.Header.Set(RequestIDHeader, "trace-42")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assertResponseCode(t, http.StatusNotFound, rec.Code)
+	if ct := rec.Header().Get("Content-Type"); ct != ProblemContentType {
+		t.Errorf("Expected Content-Type %s, got %s", ProblemContentType, ct)
+	}
+	if id := rec.Header().Get(RequestIDHeader); id != "trace-42" {
+		t.Errorf("Expected %s 'trace-42', got '%s'", RequestIDHeader, id)
+	}
+	var problem Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("decode Problem: %v", err)
+	}
+	if problem.Code != pkg.ErrTaskNotFound.Code || problem.RequestId != "trace-42" {
+		t.Errorf("Unexpected problem: %+v", problem)
+	}
+
+	// Без заголовка назначается новый идентификатор; ошибки разбора тоже возвращаются как Problem
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/tasks", nil)
+	req.Header.Set(RequestIDHeader, "bad id with spaces")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assertResponseCode(t, http.StatusBadRequest, rec.Code)
+	problem = Problem{}
+	_ = json.Unmarshal(rec.Body.Bytes(), &problem)
+	if problem.Code != pkg.ErrMalformedRequest.Code {
+		t.Errorf("Expected code '%s', got '%s'", pkg.ErrMalformedRequest.Code, problem.Code)
+	}
+	if id := rec.Header().Get(RequestIDHeader); id == "" || id == "bad id with spaces" || id != problem.RequestId {
+		t.Errorf("Expected generated request id, got header '%s' and body '%s'", id, problem.RequestId)
+	}
+}
@@ -0,0 +1,46 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"workmate/pkg"
+)
+
+// QuotaConfig - лимиты принципалов (владельцев задач) во всех пространствах имен
+type QuotaConfig struct {
+	// Default - лимиты принципалов, не перечисленных в Principals
+	Default pkg.TenantQuota `json:"default"`
+	// Principals - лимиты отдельных принципалов по имени (как в apikeys.json или CN сертификата)
+	Principals map[string]pkg.TenantQuota `json:"principals,omitempty"`
+}
+
+// LoadQuotaConfig - Загрузить лимиты принципалов из JSON файла
+func LoadQuotaConfig(path string) (*QuotaConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var config QuotaConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return &config, nil
+}
+
+// Validate - Проверить имена принципалов и лимиты
+func (c *QuotaConfig) Validate() error {
+	if err := c.Default.Validate(); err != nil {
+		return fmt.Errorf("quota default: %w", err)
+	}
+	for name, quota := range c.Principals {
+		if name == "" {
+			return fmt.Errorf("quota: empty principal name")
+		}
+		if err := quota.Validate(); err != nil {
+			return fmt.Errorf("quota %s: %w", name, err)
+		}
+	}
+	return nil
+}
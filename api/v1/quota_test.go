@@ -0,0 +1,58 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"workmate/internal"
+	"workmate/pkg"
+)
+
+func TestQuotaConfigValidate(t *testing.T) {
+	config := &QuotaConfig{Principals: map[string]pkg.TenantQuota{"team-a": {MaxRunningTasks: 2, MaxTaskSeconds: 3600}}}
+	if err := config.Validate(); err != nil {
+		t.Errorf("Validate() returned error: %v", err)
+	}
+	config = &QuotaConfig{Principals: map[string]pkg.TenantQuota{"": {}}}
+	if err := config.Validate(); err == nil {
+		t.Error("Validate() with empty principal name should return error")
+	}
+	config = &QuotaConfig{Default: pkg.TenantQuota{MaxTasksPerDay: -1}}
+	if err := config.Validate(); err == nil {
+		t.Error("Validate() with negative quota should return error")
+	}
+}
+
+func TestGetUsage(t *testing.T) {
+	service := NewTasksAPIService(internal.WithTenantQuotas(pkg.TenantQuota{MaxTasksPerDay: 1}, nil))
+	router := NewRouter(NewTasksAPIController(service))
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assertResponseCode(t, 201, do(http.MethodPost, "/api/v1/tasks", `{"name":"Task"}`).Code)
+	rec := do(http.MethodPost, "/api/v1/tasks", `{"name":"Task 2"}`)
+	assertResponseCode(t, 429, rec.Code)
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("Expected Retry-After header on principal_quota_exceeded")
+	}
+
+	rec = do(http.MethodGet, "/api/v1/usage", "")
+	assertResponseCode(t, 200, rec.Code)
+	var report UsageReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("decode UsageReport: %v", err)
+	}
+	if !report.From.Before(report.To) || len(report.Usage) != 1 {
+		t.Fatalf("Unexpected usage report: %+v", report)
+	}
+	if usage := report.Usage[0]; usage.TasksCreated != 1 || usage.Quota.MaxTasksPerDay != 1 {
+		t.Errorf("Unexpected usage of the anonymous principal: %+v", usage)
+	}
+}
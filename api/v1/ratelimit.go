@@ -0,0 +1,282 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+	"workmate/pkg"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// rateLimitIdleTTL - через сколько простоя корзина клиента удаляется
+	rateLimitIdleTTL = 10 * time.Minute
+)
+
+// defaultUnauthorizedLimit - лимит отказов в аутентификации с одного IP адреса, если он не задан
+var defaultUnauthorizedLimit = RateLimit{Rate: 0.2, Burst: 10}
+
+// RateLimit - параметры token bucket: Rate токенов в секунду, не больше Burst подряд
+type RateLimit struct {
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+}
+
+// RateLimitConfig - лимиты запросов по именам маршрутов из TasksAPIController.Routes()
+type RateLimitConfig struct {
+	// Default - лимит для маршрутов, не перечисленных в Routes (nil - без лимита)
+	Default *RateLimit `json:"default,omitempty"`
+	// Routes - лимиты отдельных маршрутов
+	Routes map[string]RateLimit `json:"routes,omitempty"`
+	// MaxActiveTasks - глобальный лимит задач в статусах pending+running (0 - без лимита)
+	MaxActiveTasks int `json:"maxActiveTasks,omitempty"`
+	// Unauthorized - лимит ответов 401 на IP адрес, чтобы нельзя было перебирать API ключи
+	// (nil - defaultUnauthorizedLimit)
+	Unauthorized *RateLimit `json:"unauthorized,omitempty"`
+}
+
+// LoadRateLimitConfig - Загрузить лимиты из JSON файла
+func LoadRateLimitConfig(path string) (*RateLimitConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var config RateLimitConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return &config, nil
+}
+
+// Validate - Проверить, что лимиты заданы для существующих маршрутов и корректны
+func (c *RateLimitConfig) Validate(routes Routes) error {
+	check := func(name string, limit RateLimit) error {
+		if limit.Rate <= 0 || limit.Burst < 1 {
+			return fmt.Errorf("rate limit %s: rate must be > 0 and burst >= 1", name)
+		}
+		return nil
+	}
+
+	if c.Default != nil {
+		if err := check("default", *c.Default); err != nil {
+			return err
+		}
+	}
+	for name, limit := range c.Routes {
+		if _, exists := routes[name]; !exists {
+			return fmt.Errorf("rate limit: unknown route %q", name)
+		}
+		if err := check(name, limit); err != nil {
+			return err
+		}
+	}
+	if c.Unauthorized != nil {
+		if err := check("unauthorized", *c.Unauthorized); err != nil {
+			return err
+		}
+	}
+	if c.MaxActiveTasks < 0 {
+		return fmt.Errorf("rate limit: maxActiveTasks must be >= 0")
+	}
+	return nil
+}
+
+// bucket - корзина токенов одного клиента на одном маршруте
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateLimiter ограничивает частоту запросов по token bucket на пару (маршрут, клиент).
+// Клиент - аутентифицированный принципал, а без аутентификации - IP адрес
+type RateLimiter struct {
+	config *RateLimitConfig
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewRateLimiter создает ограничитель по конфигу
+func NewRateLimiter(config *RateLimitConfig) *RateLimiter {
+	return &RateLimiter{
+		config:    config,
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// limitFor - Лимит маршрута (ok == false, если маршрут не ограничен)
+func (l *RateLimiter) limitFor(route string) (limit RateLimit, ok bool) {
+	if limit, ok = l.config.Routes[route]; ok {
+		return
+	}
+	if l.config.Default != nil {
+		return *l.config.Default, true
+	}
+	return
+}
+
+// exhausted - Пуста ли корзина (токен не забирается); если пуста - через сколько появится токен
+func (l *RateLimiter) exhausted(key string, limit RateLimit, now time.Time) (retryAfter time.Duration, empty bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, exists := l.buckets[key]
+	if !exists {
+		return 0, false
+	}
+	tokens := math.Min(float64(limit.Burst), b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	if tokens >= 1 {
+		return 0, false
+	}
+	return time.Duration((1 - tokens) / limit.Rate * float64(time.Second)), true
+}
+
+// take - Забрать токен из корзины. Возвращает остаток токенов и,
+// если токена нет, через сколько он появится
+func (l *RateLimiter) take(key string, limit RateLimit, now time.Time) (remaining int, retryAfter time.Duration, allowed bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	b, exists := l.buckets[key]
+	if !exists {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		retryAfter = time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+		return 0, retryAfter, false
+	}
+	b.tokens--
+	return int(b.tokens), 0, true
+}
+
+// sweep - Удалить корзины простаивающих клиентов, чтобы карта не росла бесконечно
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < rateLimitIdleTTL {
+		return
+	}
+	for key, b := range l.buckets {
+		if now.Sub(b.last) > rateLimitIdleTTL {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// clientKey - Идентификатор клиента для лимита: принципал или IP адрес
+func clientKey(r *http.Request) string {
+	if principal, ok := pkg.PrincipalFromContext(r.Context()); ok {
+		return "principal:" + principal.Name
+	}
+	return ipKey(r)
+}
+
+// ipKey - Идентификатор клиента по IP адресу
+func ipKey(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// Middleware - HTTP middleware: отвечает 429 с Retry-After при превышении лимита
+// и сообщает состояние лимита в заголовках RateLimit-*
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := mux.CurrentRoute(r)
+		if route == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		limit, ok := l.limitFor(route.GetName())
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		remaining, retryAfter, allowed := l.take(route.GetName()+"|"+clientKey(r), limit, time.Now())
+
+		// До полного восстановления корзины
+		reset := math.Ceil(float64(limit.Burst-remaining) / limit.Rate)
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(limit.Burst))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(remaining))
+		w.Header().Set("RateLimit-Reset", strconv.Itoa(int(reset)))
+
+		if !allowed {
+			seconds := int(math.Ceil(retryAfter.Seconds()))
+			headers := map[string][]string{"Retry-After": {strconv.Itoa(seconds)}}
+			WriteProblem(w, NewProblem(r.Context(), pkg.ErrRateLimited), headers)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// statusRecorder - ResponseWriter, запоминающий код ответа
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusRecorder) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusRecorder) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// Unwrap - Исходный ResponseWriter для http.ResponseController
+func (w *statusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// UnauthorizedMiddleware - HTTP middleware перед аутентификацией: считает ответы 401 по IP адресу
+// и, пока лимит Unauthorized исчерпан, отвечает 429 с Retry-After, не проверяя учетные данные.
+// Успешно аутентифицированные запросы лимит не расходуют
+func (l *RateLimiter) UnauthorizedMiddleware(next http.Handler) http.Handler {
+	limit := defaultUnauthorizedLimit
+	if l.config.Unauthorized != nil {
+		limit = *l.config.Unauthorized
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := "unauthorized|" + ipKey(r)
+		if retryAfter, empty := l.exhausted(key, limit, time.Now()); empty {
+			seconds := int(math.Ceil(retryAfter.Seconds()))
+			headers := map[string][]string{"Retry-After": {strconv.Itoa(seconds)}}
+			WriteProblem(w, NewProblem(r.Context(), pkg.ErrRateLimited), headers)
+			return
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, r)
+		if recorder.status == http.StatusUnauthorized {
+			l.take(key, limit, time.Now())
+		}
+	})
+}
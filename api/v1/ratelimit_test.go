@@ -0,0 +1,119 @@
+package openapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"workmate/internal"
+)
+
+func TestRateLimiterMiddleware(t *testing.T) {
+	controller := NewTasksAPIController(NewTasksAPIService())
+	limits := &RateLimitConfig{
+		Routes: map[string]RateLimit{"GetTasks": {Rate: 0.001, Burst: 2}},
+	}
+	if err := limits.Validate(controller.Routes()); err != nil {
+		t.Fatalf("Validate() returned error: %v", err)
+	}
+	router := NewRouter(controller)
+	router.Use(NewRateLimiter(limits).Middleware)
+
+	get := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks", nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("10.0.0.1:1000")
+	assertResponseCode(t, 200, rec.Code)
+	if rec.Header().Get("RateLimit-Limit") != "2" || rec.Header().Get("RateLimit-Remaining") != "1" {
+		t.Errorf("Unexpected RateLimit headers: %v", rec.Header())
+	}
+	assertResponseCode(t, 200, get("10.0.0.1:1001").Code)
+
+	rec = get("10.0.0.1:1002")
+	assertResponseCode(t, 429, rec.Code)
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("Expected Retry-After header on 429")
+	}
+
+	// Другой клиент имеет свою корзину, неограниченный маршрут не лимитируется
+	assertResponseCode(t, 200, get("10.0.0.2:1000").Code)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/tasks", strings.NewReader(`{"name":"Task"}`))
+	req.RemoteAddr = "10.0.0.1:1003"
+	postRec := httptest.NewRecorder()
+	router.ServeHTTP(postRec, req)
+	assertResponseCode(t, 201, postRec.Code)
+	if postRec.Header().Get("RateLimit-Limit") != "" {
+		t.Error("Unlimited route should not report RateLimit headers")
+	}
+}
+
+func TestUnauthorizedRateLimit(t *testing.T) {
+	limiter := NewRateLimiter(&RateLimitConfig{Unauthorized: &RateLimit{Rate: 0.001, Burst: 2}})
+	auth, _ := NewAuthenticator([]APIKey{{Principal: "alice", Hash: HashAPIKey("secret")}})
+	router := NewRouter(NewTasksAPIController(NewTasksAPIService()))
+	router.Use(limiter.UnauthorizedMiddleware)
+	router.Use(auth.Middleware)
+
+	get := func(remoteAddr, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Успешная аутентификация лимит не расходует
+	for i := 0; i < 3; i++ {
+		assertResponseCode(t, 200, get("10.0.0.1:1000", "secret").Code)
+	}
+
+	// После исчерпания лимита отказов ключи с этого адреса не проверяются
+	assertResponseCode(t, 401, get("10.0.0.1:1001", "guess-1").Code)
+	assertResponseCode(t, 401, get("10.0.0.1:1002", "guess-2").Code)
+	rec := get("10.0.0.1:1003", "secret")
+	assertResponseCode(t, 429, rec.Code)
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("Expected Retry-After header on 429")
+	}
+
+	// Другой адрес имеет свою корзину
+	assertResponseCode(t, 401, get("10.0.0.2:1000", "guess-1").Code)
+	assertResponseCode(t, 200, get("10.0.0.2:1001", "secret").Code)
+}
+
+func TestRateLimitConfigValidate(t *testing.T) {
+	routes := NewTasksAPIController(NewTasksAPIService()).Routes()
+
+	config := &RateLimitConfig{Routes: map[string]RateLimit{"PurgeTasks": {Rate: 1, Burst: 1}}}
+	if err := config.Validate(routes); err == nil {
+		t.Error("Validate() with unknown route should return error")
+	}
+	config = &RateLimitConfig{Default: &RateLimit{Rate: 0, Burst: 1}}
+	if err := config.Validate(routes); err == nil {
+		t.Error("Validate() with zero rate should return error")
+	}
+	config = &RateLimitConfig{Unauthorized: &RateLimit{Rate: 1, Burst: 0}}
+	if err := config.Validate(routes); err == nil {
+		t.Error("Validate() with zero unauthorized burst should return error")
+	}
+}
+
+func TestCreateTaskAdmissionLimit(t *testing.T) {
+	service := NewTasksAPIService(internal.WithMaxActiveTasks(1))
+
+	resp, _ := service.CreateTask(context.Background(), CreateTaskRequest{Name: "Task 1"})
+	assertResponseCode(t, 201, resp.Code)
+
+	resp, _ = service.CreateTask(context.Background(), CreateTaskRequest{Name: "Task 2"})
+	assertResponseCode(t, 429, resp.Code)
+	if len(resp.Headers["Retry-After"]) == 0 {
+		t.Error("Expected Retry-After header on 429")
+	}
+}
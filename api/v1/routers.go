@@ -36,6 +36,7 @@ type Router interface {
 // NewRouter creates a new router for any number of api routers
 func NewRouter(routers ...Router) *mux.Router {
 	router := mux.NewRouter().StrictSlash(true)
+	router.Use(RequestID)
 	for _, api := range routers {
 		for _, route := range api.OrderedRoutes() {
 			var handler http.Handler = route.HandlerFunc
@@ -46,6 +47,15 @@ func NewRouter(routers ...Router) *mux.Router {
 				Path(route.Pattern).
 				Name(route.Name).
 				Handler(handler)
+
+			// Операции с задачами доступны и в пространстве имен из пути
+			if pattern, ok := namespacedPattern(route.Pattern); ok {
+				router.
+					Methods(route.Method).
+					Path(pattern).
+					Name(route.Name).
+					Handler(Logger(Namespaced(route.HandlerFunc), route.Name))
+			}
 		}
 	}
 
@@ -0,0 +1,96 @@
+package openapi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"workmate/api/contract"
+	"workmate/pkg"
+
+	"github.com/gorilla/mux"
+)
+
+// maxRequestBody - максимальный размер тела запроса, если операция не задает свой x-max-body-size
+const maxRequestBody = 1 << 20
+
+// RequestValidator - проверка запросов по схемам OpenAPI спецификации до вызова обработчика:
+// параметры path и query, Content-Type и JSON тело (длины, enum, форматы, additionalProperties)
+type RequestValidator struct {
+	spec *contract.Spec
+}
+
+// NewRequestValidator создает проверку запросов по спецификации
+func NewRequestValidator(spec *contract.Spec) *RequestValidator {
+	return &RequestValidator{spec: spec}
+}
+
+// Validate - Проверить запрос к операции. Возвращает все найденные нарушения и ошибку:
+// pkg.ErrInvalidParameters или pkg.ErrMalformedRequest (400), pkg.ErrPayloadTooLarge (413),
+// pkg.ErrInvalidBody (422, тело не соответствует схеме); nil - запрос корректен.
+// Тело запроса читается и подменяется копией для следующего обработчика
+func (v *RequestValidator) Validate(r *http.Request, op *contract.Operation) ([]contract.Violation, error) {
+	violations := v.spec.ValidateParameters(op, mux.Vars(r), r.URL.Query())
+
+	var body []byte
+	if r.Body != nil {
+		var err error
+		body, err = io.ReadAll(r.Body)
+		r.Body.Close()
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, fmt.Errorf("%w: limit is %d bytes", pkg.ErrPayloadTooLarge, tooLarge.Limit)
+		}
+		if err != nil {
+			return violations, fmt.Errorf("%w: read body: %v", pkg.ErrMalformedRequest, err)
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	bodyViolations, malformed := v.spec.ValidateBody(op, r.Header.Get("Content-Type"), body)
+	switch {
+	case malformed:
+		return append(violations, bodyViolations...), pkg.ErrMalformedRequest
+	case len(violations) > 0:
+		return append(violations, bodyViolations...), pkg.ErrInvalidParameters
+	case len(bodyViolations) > 0:
+		return bodyViolations, pkg.ErrInvalidBody
+	}
+	return nil, nil
+}
+
+// Middleware - HTTP middleware: отвечает Problem со списком нарушений,
+// если запрос не соответствует операции спецификации
+func (v *RequestValidator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := mux.CurrentRoute(r)
+		if route == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		template, _ := route.GetPathTemplate()
+		op, ok := v.spec.Operation(r.Method, template)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if r.Body != nil {
+			limit := int64(maxRequestBody)
+			if op.MaxBodySize > 0 {
+				limit = op.MaxBodySize
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+		}
+		violations, err := v.Validate(r, op)
+		if err == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		problem := NewProblem(r.Context(), err)
+		problem.Violations = MapViolationsToAPI(violations)
+		WriteProblem(w, problem, nil)
+	})
+}
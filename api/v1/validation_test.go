@@ -0,0 +1,80 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"workmate/api/contract"
+)
+
+func newValidatedRouter(t *testing.T) http.Handler {
+	t.Helper()
+	spec, err := contract.LoadV1()
+	if err != nil {
+		t.Fatalf("LoadV1() returned error: %v", err)
+	}
+	router := NewRouter(NewTasksAPIController(NewTasksAPIService()))
+	router.Use(NewRequestValidator(spec).Middleware)
+	return router
+}
+
+// validate - Выполнить запрос и разобрать нарушения из Problem
+func validate(t *testing.T, router http.Handler, method, target, body string) (int, []string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+
+	var resp Problem
+	_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+	violations := make([]string, len(resp.Violations))
+	for i, v := range resp.Violations {
+		violations[i] = v.In + ":" + v.Pointer
+	}
+	return rec.Code, violations
+}
+
+func TestRequestValidatorBody(t *testing.T) {
+	router := newValidatedRouter(t)
+
+	// Все нарушения тела перечисляются с JSON pointer
+	code, violations := validate(t, router, http.MethodPost, "/api/v1/tasks",
+		`{"name":"`+strings.Repeat("x", 256)+`","callbackUrl":"not a url","callbackEvents":["completed","running"],"id":"x"}`)
+	assertResponseCode(t, http.StatusUnprocessableEntity, code)
+	expected := []string{"body:/callbackEvents/1", "body:/callbackUrl", "body:/id", "body:/name"}
+	if strings.Join(violations, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected violations %v, got %v", expected, violations)
+	}
+
+	// Тело, которое не разбирается
+	code, violations = validate(t, router, http.MethodPost, "/api/v1/tasks", `{"name":`)
+	assertResponseCode(t, http.StatusBadRequest, code)
+	if len(violations) != 1 || violations[0] != "body:" {
+		t.Errorf("Expected single body violation, got %v", violations)
+	}
+
+	// Корректное тело проходит к обработчику
+	code, _ = validate(t, router, http.MethodPost, "/api/v1/tasks", `{"name":"Task"}`)
+	assertResponseCode(t, http.StatusCreated, code)
+}
+
+func TestRequestValidatorParameters(t *testing.T) {
+	router := newValidatedRouter(t)
+
+	code, violations := validate(t, router, http.MethodGet, "/api/v1/tasks?status=pending,done&createdAfter=yesterday&limit=0", "")
+	assertResponseCode(t, http.StatusBadRequest, code)
+	expected := []string{"query:/status/1", "query:/createdAfter", "query:/limit"}
+	if strings.Join(violations, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected violations %v, got %v", expected, violations)
+	}
+
+	code, violations = validate(t, router, http.MethodGet, "/api/v1/tasks/42", "")
+	assertResponseCode(t, http.StatusBadRequest, code)
+	if len(violations) != 1 || violations[0] != "path:/taskId" {
+		t.Errorf("Expected path violation, got %v", violations)
+	}
+
+	code, _ = validate(t, router, http.MethodGet, "/api/v1/tasks?status=pending,running&limit=10", "")
+	assertResponseCode(t, http.StatusOK, code)
+}
@@ -0,0 +1,532 @@
+// Package client - Go клиент WorkMate Task Manager API
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ListOptions - фильтры списка задач (нулевые поля не ограничивают выборку)
+type ListOptions struct {
+	Statuses      []string
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	Limit         int
+}
+
+// ExportOptions - параметры потоковой выгрузки задач: фильтры списка, формат и колонки
+type ExportOptions struct {
+	ListOptions
+	// Format - формат выгрузки: "ndjson" (по умолчанию) или "csv"
+	Format string
+	// Columns - колонки в нужном порядке (по умолчанию все)
+	Columns []string
+}
+
+// ImportOptions - параметры импорта задач
+type ImportOptions struct {
+	// Conflict - политика для задач с существующим id: "fail" (по умолчанию), "skip" или "overwrite"
+	Conflict string
+	// Requeue - заново поставить в очередь незавершенные задачи
+	Requeue bool
+}
+
+// UsageOptions - окно и принципал отчета о потреблении (нулевые поля - значения сервера по умолчанию)
+type UsageOptions struct {
+	// From, To - окно [From, To); по умолчанию сутки до текущего момента
+	From time.Time
+	To   time.Time
+	// Principal - только этот принципал (по умолчанию все доступные вызывающему)
+	Principal string
+}
+
+// Client - клиент WorkMate API
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	token      string
+	userAgent  string
+	namespace  string
+	tlsConfig  *tls.Config
+}
+
+// Option - параметр настройки клиента
+type Option func(*Client) error
+
+// WithHTTPClient - Использовать свой http.Client
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) error {
+		c.httpClient = httpClient
+		return nil
+	}
+}
+
+// WithToken - Передавать API ключ в заголовке Authorization: Bearer
+func WithToken(token string) Option {
+	return func(c *Client) error {
+		c.token = token
+		return nil
+	}
+}
+
+// WithUserAgent - Задать заголовок User-Agent
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) error {
+		c.userAgent = userAgent
+		return nil
+	}
+}
+
+// WithNamespace - Работать с задачами пространства имен (по умолчанию - default)
+func WithNamespace(namespace string) Option {
+	return func(c *Client) error {
+		c.namespace = namespace
+		return nil
+	}
+}
+
+// WithTLSConfig - Использовать TLS конфигурацию (HTTP/2 согласуется через ALPN).
+// Применяется после всех опций, в том числе к http.Client из WithHTTPClient в любом порядке;
+// сам переданный http.Client не изменяется: конфигурация задается на его копии
+func WithTLSConfig(config *tls.Config) Option {
+	return func(c *Client) error {
+		c.tlsConfig = config
+		return nil
+	}
+}
+
+// withTLSConfig - Копия httpClient с TLS конфигурацией: транспорт *http.Transport клонируется
+// с сохранением настроек, любой другой заменяется новым
+func withTLSConfig(httpClient *http.Client, config *tls.Config) *http.Client {
+	copied := *httpClient
+	if transport, ok := copied.Transport.(*http.Transport); ok {
+		transport = transport.Clone()
+		transport.TLSClientConfig = config
+		copied.Transport = transport
+	} else {
+		copied.Transport = &http.Transport{
+			Proxy:             http.ProxyFromEnvironment,
+			TLSClientConfig:   config,
+			ForceAttemptHTTP2: true,
+		}
+	}
+	return &copied
+}
+
+// TLSConfig - Собрать TLS конфигурацию: CA сервера (например, ca.pem из gen-certs.sh)
+// и, для mTLS, клиентский сертификат. Пустые пути пропускаются
+func TLSConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if caFile != "" {
+		data, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("%s: no PEM certificates found", caFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// New создает клиент для сервера по адресу baseURL (например, https://localhost:8080)
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("workmate: unsupported URL scheme %q", u.Scheme)
+	}
+
+	c := &Client{
+		baseURL:    u,
+		httpClient: &http.Client{Transport: &http.Transport{Proxy: http.ProxyFromEnvironment, ForceAttemptHTTP2: true}},
+		userAgent:  "workmate-go-client",
+	}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+	if c.tlsConfig != nil {
+		c.httpClient = withTLSConfig(c.httpClient, c.tlsConfig)
+	}
+	return c, nil
+}
+
+// tasksPath - Путь коллекции задач: пространство имен по умолчанию или заданное WithNamespace
+func (c *Client) tasksPath() string {
+	if c.namespace == "" {
+		return "/api/v1/tasks"
+	}
+	return "/api/v1/namespaces/" + url.PathEscape(c.namespace) + "/tasks"
+}
+
+// CreateTask - Создать новую задачу
+func (c *Client) CreateTask(ctx context.Context, request CreateTaskRequest) (Task, error) {
+	var resp taskResponse
+	err := c.do(ctx, http.MethodPost, c.tasksPath(), nil, request, &resp)
+	return resp.Task, err
+}
+
+// ListTasks - Получить список задач по фильтрам
+func (c *Client) ListTasks(ctx context.Context, opts ListOptions) ([]Task, error) {
+	var resp taskListResponse
+	err := c.do(ctx, http.MethodGet, c.tasksPath(), opts.query(), nil, &resp)
+	return resp.Tasks, err
+}
+
+// ExportTasks - Выгрузить задачи потоком в w (NDJSON или CSV), не загружая их в память
+func (c *Client) ExportTasks(ctx context.Context, w io.Writer, opts ExportOptions) error {
+	query := opts.ListOptions.query()
+	format := opts.Format
+	if format == "" {
+		format = exportFormatNDJSON
+	}
+	query.Set("format", format)
+	if len(opts.Columns) > 0 {
+		query.Set("columns", strings.Join(opts.Columns, ","))
+	}
+	return c.do(ctx, http.MethodGet, c.tasksPath()+"/export", query, nil, w)
+}
+
+// ImportTasks - Загрузить задачи из NDJSON выгрузки (ExportTasks) с сохранением id, времени и
+// результатов. Требует роли admin; ошибки записей возвращаются в APIError.Violations
+func (c *Client) ImportTasks(ctx context.Context, r io.Reader, opts ImportOptions) (ImportReport, error) {
+	query := url.Values{}
+	if opts.Conflict != "" {
+		query.Set("conflict", opts.Conflict)
+	}
+	if opts.Requeue {
+		query.Set("requeue", "true")
+	}
+
+	var report ImportReport
+	err := c.do(ctx, http.MethodPost, c.tasksPath()+"/import", query, r, &report)
+	return report, err
+}
+
+// query - Параметры запроса для фильтров списка
+func (o ListOptions) query() url.Values {
+	query := url.Values{}
+	if len(o.Statuses) > 0 {
+		query.Set("status", strings.Join(o.Statuses, ","))
+	}
+	if !o.CreatedAfter.IsZero() {
+		query.Set("createdAfter", o.CreatedAfter.Format(time.RFC3339Nano))
+	}
+	if !o.CreatedBefore.IsZero() {
+		query.Set("createdBefore", o.CreatedBefore.Format(time.RFC3339Nano))
+	}
+	if o.Limit > 0 {
+		query.Set("limit", strconv.Itoa(o.Limit))
+	}
+	return query
+}
+
+// GetTask - Получить информацию о задаче
+func (c *Client) GetTask(ctx context.Context, taskId string) (Task, error) {
+	var resp taskResponse
+	err := c.do(ctx, http.MethodGet, c.tasksPath()+"/"+url.PathEscape(taskId), nil, nil, &resp)
+	return resp.Task, err
+}
+
+// DeleteTask - Удалить задачу
+func (c *Client) DeleteTask(ctx context.Context, taskId string) error {
+	return c.do(ctx, http.MethodDelete, c.tasksPath()+"/"+url.PathEscape(taskId), nil, nil, nil)
+}
+
+// GetTaskResult - Получить результат задачи. Для незавершенной задачи возвращает ошибку ErrNotCompleted
+func (c *Client) GetTaskResult(ctx context.Context, taskId string) (Task, error) {
+	var resp taskResponse
+	err := c.do(ctx, http.MethodGet, c.tasksPath()+"/"+url.PathEscape(taskId)+"/result", nil, nil, &resp)
+	return resp.Task, err
+}
+
+// GetTaskResultContent - Скачать выходные данные завершенной задачи потоком в w (метаданные -
+// Task.Output из GetTaskResult). Контрольная сумма проверяется по Repr-Digest после загрузки:
+// при расхождении - ErrChecksumMismatch, а в w уже записано полученное содержимое
+func (c *Client) GetTaskResultContent(ctx context.Context, taskId string, w io.Writer) error {
+	digest := &digestWriter{w: w, hash: sha256.New()}
+	if err := c.do(ctx, http.MethodGet, c.tasksPath()+"/"+url.PathEscape(taskId)+"/result/content", nil, nil, digest); err != nil {
+		return err
+	}
+	return digest.verify()
+}
+
+// GetTaskHistory - Получить историю переходов статуса задачи в порядке времени
+func (c *Client) GetTaskHistory(ctx context.Context, taskId string) ([]TaskTransition, error) {
+	var resp taskHistoryResponse
+	err := c.do(ctx, http.MethodGet, c.tasksPath()+"/"+url.PathEscape(taskId)+"/history", nil, nil, &resp)
+	return resp.History, err
+}
+
+// GetTaskIfModified - Получить задачу, если она изменилась с версии version (Task.Version);
+// неизмененная задача - ошибка ErrNotModified, без передачи тела
+func (c *Client) GetTaskIfModified(ctx context.Context, taskId string, version int64) (Task, error) {
+	var resp taskResponse
+	header := http.Header{"If-None-Match": {etag(version)}}
+	err := c.doHeader(ctx, http.MethodGet, c.tasksPath()+"/"+url.PathEscape(taskId), nil, header, nil, &resp)
+	return resp.Task, err
+}
+
+// DeleteTaskIfMatch - Удалить задачу, только если она не менялась с версии version (Task.Version);
+// иначе - ошибка ErrVersionConflict
+func (c *Client) DeleteTaskIfMatch(ctx context.Context, taskId string, version int64) error {
+	header := http.Header{"If-Match": {etag(version)}}
+	return c.doHeader(ctx, http.MethodDelete, c.tasksPath()+"/"+url.PathEscape(taskId), nil, header, nil, nil)
+}
+
+// GetTaskDeliveries - Получить историю доставки webhook задачи
+func (c *Client) GetTaskDeliveries(ctx context.Context, taskId string) ([]WebhookDelivery, error) {
+	var resp deliveryListResponse
+	err := c.do(ctx, http.MethodGet, c.tasksPath()+"/"+url.PathEscape(taskId)+"/deliveries", nil, nil, &resp)
+	return resp.Deliveries, err
+}
+
+// ListNamespaces - Получить пространства имен с числом задач и лимитами
+func (c *Client) ListNamespaces(ctx context.Context) ([]Namespace, error) {
+	var resp namespaceListResponse
+	err := c.do(ctx, http.MethodGet, "/api/v1/namespaces", nil, nil, &resp)
+	return resp.Namespaces, err
+}
+
+// PurgeNamespace - Удалить все задачи пространства имен (в политике по умолчанию - только admin).
+// Возвращает число удаленных задач
+func (c *Client) PurgeNamespace(ctx context.Context, namespace string) (int, error) {
+	var report namespacePurgeReport
+	err := c.do(ctx, http.MethodDelete, "/api/v1/namespaces/"+url.PathEscape(namespace), nil, nil, &report)
+	return int(report.Deleted), err
+}
+
+// GetUsage - Получить потребление по принципалам за окно: созданные задачи, секунды выполнения и лимиты
+func (c *Client) GetUsage(ctx context.Context, opts UsageOptions) (UsageReport, error) {
+	query := url.Values{}
+	if !opts.From.IsZero() {
+		query.Set("from", opts.From.Format(time.RFC3339Nano))
+	}
+	if !opts.To.IsZero() {
+		query.Set("to", opts.To.Format(time.RFC3339Nano))
+	}
+	if opts.Principal != "" {
+		query.Set("principal", opts.Principal)
+	}
+
+	var report UsageReport
+	err := c.do(ctx, http.MethodGet, "/api/v1/usage", query, nil, &report)
+	return report, err
+}
+
+// CancelTask - Отменить задачу: она переходит в cancelled, выполнение прерывается.
+// Задачу в финальном статусе - ошибка pkg.ErrIllegalTransition
+func (c *Client) CancelTask(ctx context.Context, taskId string) (Task, error) {
+	var resp taskResponse
+	err := c.do(ctx, http.MethodPost, c.tasksPath()+"/"+url.PathEscape(taskId)+"/cancel", nil, nil, &resp)
+	return resp.Task, err
+}
+
+// PauseTask - Приостановить задачу (pending или running у исполнителя с поддержкой приостановки).
+// Задачу в другом статусе или без поддержки приостановки - ошибка pkg.ErrIllegalTransition
+// или pkg.ErrNotSuspendable
+func (c *Client) PauseTask(ctx context.Context, taskId string) (Task, error) {
+	var resp taskResponse
+	err := c.do(ctx, http.MethodPost, c.tasksPath()+"/"+url.PathEscape(taskId)+"/pause", nil, nil, &resp)
+	return resp.Task, err
+}
+
+// ResumeTask - Возобновить приостановленную задачу: она снова ждет запуска в pending
+func (c *Client) ResumeTask(ctx context.Context, taskId string) (Task, error) {
+	var resp taskResponse
+	err := c.do(ctx, http.MethodPost, c.tasksPath()+"/"+url.PathEscape(taskId)+"/resume", nil, nil, &resp)
+	return resp.Task, err
+}
+
+// GetDispatch - Получить состояние запуска задач (в политике по умолчанию - только admin)
+func (c *Client) GetDispatch(ctx context.Context) (DispatchStatus, error) {
+	var status DispatchStatus
+	err := c.do(ctx, http.MethodGet, "/api/v1/dispatch", nil, nil, &status)
+	return status, err
+}
+
+// PauseDispatch - Остановить запуск новых задач; выполняющиеся задачи продолжаются
+func (c *Client) PauseDispatch(ctx context.Context) (DispatchStatus, error) {
+	var status DispatchStatus
+	err := c.do(ctx, http.MethodPost, "/api/v1/dispatch/pause", nil, nil, &status)
+	return status, err
+}
+
+// ResumeDispatch - Возобновить запуск задач
+func (c *Client) ResumeDispatch(ctx context.Context) (DispatchStatus, error) {
+	var status DispatchStatus
+	err := c.do(ctx, http.MethodPost, "/api/v1/dispatch/resume", nil, nil, &status)
+	return status, err
+}
+
+// do - Выполнить запрос и разобрать ответ в out (nil - тело не нужно, io.Writer - скопировать тело как есть).
+// body кодируется в JSON, io.Reader отправляется как есть в формате NDJSON
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	return c.doHeader(ctx, method, path, query, nil, body, out)
+}
+
+// doHeader - Выполнить запрос с дополнительными заголовками (условные запросы)
+func (c *Client) doHeader(ctx context.Context, method, path string, query url.Values, header http.Header, body, out interface{}) error {
+	u := *c.baseURL
+	u.Path += path
+	u.RawQuery = query.Encode()
+
+	var reader io.Reader
+	contentType := "application/json"
+	if r, ok := body.(io.Reader); ok {
+		reader = r
+		contentType = ndjsonContentType
+	} else if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reader)
+	if err != nil {
+		return err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return ErrNotModified
+	}
+	if resp.StatusCode >= 300 {
+		return decodeError(resp)
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if w, ok := out.(io.Writer); ok {
+		if receiver, ok := out.(headerReceiver); ok {
+			receiver.receiveHeader(resp.Header)
+		}
+		_, err := io.Copy(w, resp.Body)
+		return err
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("workmate: decode response: %w", err)
+	}
+	return nil
+}
+
+// headerReceiver - получатель потока ответа, которому нужны заголовки ответа
+type headerReceiver interface {
+	receiveHeader(header http.Header)
+}
+
+// digestWriter - запись содержимого с подсчетом sha256 для сверки с Repr-Digest (RFC 9530)
+type digestWriter struct {
+	w        io.Writer
+	hash     hash.Hash
+	expected string
+}
+
+func (d *digestWriter) Write(p []byte) (int, error) {
+	d.hash.Write(p)
+	return d.w.Write(p)
+}
+
+func (d *digestWriter) receiveHeader(header http.Header) {
+	for _, field := range strings.Split(header.Get("Repr-Digest"), ",") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(field), "sha-256=:"); ok {
+			d.expected = strings.TrimSuffix(value, ":")
+		}
+	}
+}
+
+// verify - Сверить сумму записанного с Repr-Digest (сервер без Repr-Digest не проверяется)
+func (d *digestWriter) verify() error {
+	if d.expected == "" {
+		return nil
+	}
+	if actual := base64.StdEncoding.EncodeToString(d.hash.Sum(nil)); actual != d.expected {
+		return fmt.Errorf("%w: expected sha-256 %s, got %s", ErrChecksumMismatch, d.expected, actual)
+	}
+	return nil
+}
+
+// decodeError - Разобрать тело ошибки: Problem (RFC 7807), а для прежних версий сервера -
+// {"error": "..."} или JSON строка
+func decodeError(resp *http.Response) error {
+	apiErr := &APIError{StatusCode: resp.StatusCode, RequestID: resp.Header.Get(requestIDHeader)}
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	var problem problem
+	var legacy struct {
+		Error string `json:"error"`
+	}
+	var message string
+	switch {
+	case json.Unmarshal(data, &problem) == nil && problem.Code != "":
+		apiErr.Code = problem.Code
+		apiErr.Message = problem.Detail
+		if apiErr.Message == "" {
+			apiErr.Message = problem.Title
+		}
+		if problem.RequestId != "" {
+			apiErr.RequestID = problem.RequestId
+		}
+		apiErr.Violations = problem.Violations
+	case json.Unmarshal(data, &legacy) == nil && legacy.Error != "":
+		apiErr.Message = legacy.Error
+	case json.Unmarshal(data, &message) == nil:
+		apiErr.Message = message
+	default:
+		apiErr.Message = strings.TrimSpace(string(data))
+	}
+
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	return apiErr
+}
@@ -0,0 +1,414 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"go/build"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	openapi "workmate/api/v1"
+	"workmate/internal"
+	"workmate/pkg"
+)
+
+// Вспомогательная функция: HTTP/2 TLS сервер с настоящим роутером и клиент к нему
+func newTestClient(t *testing.T, service openapi.TasksAPIServicer, opts ...Option) *Client {
+	server := httptest.NewUnstartedServer(openapi.NewRouter(openapi.NewTasksAPIController(service)))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	pool := server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+	opts = append([]Option{WithTLSConfig(&tls.Config{RootCAs: pool})}, opts...)
+	c, err := New(server.URL, opts...)
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+	return c
+}
+
+func TestClientTasks(t *testing.T) {
+	c := newTestClient(t, openapi.NewTasksAPIService())
+	ctx := context.Background()
+
+	// Создание задачи
+	task, err := c.CreateTask(ctx, CreateTaskRequest{Name: "Test Task"})
+	if err != nil {
+		t.Fatalf("CreateTask() returned error: %v", err)
+	}
+	if task.Id == "" || task.Status != pkg.TaskStatusPending {
+		t.Errorf("Unexpected created task: %+v", task)
+	}
+	c.CreateTask(ctx, CreateTaskRequest{Name: "Second Task"})
+
+	// Список с фильтрами
+	tasks, err := c.ListTasks(ctx, ListOptions{})
+	if err != nil {
+		t.Fatalf("ListTasks() returned error: %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Errorf("Expected 2 tasks, got %d", len(tasks))
+	}
+	tasks, err = c.ListTasks(ctx, ListOptions{Limit: 1})
+	if err != nil || len(tasks) != 1 || tasks[0].Id != task.Id {
+		t.Errorf("ListTasks(Limit: 1) should return the oldest task, got %v, %v", tasks, err)
+	}
+	tasks, err = c.ListTasks(ctx, ListOptions{Statuses: []string{pkg.TaskStatusCompleted}})
+	if err != nil || len(tasks) != 0 {
+		t.Errorf("ListTasks(completed) should return no tasks, got %v, %v", tasks, err)
+	}
+	tasks, err = c.ListTasks(ctx, ListOptions{CreatedAfter: time.Now().Add(time.Hour)})
+	if err != nil || len(tasks) != 0 {
+		t.Errorf("ListTasks(CreatedAfter: future) should return no tasks, got %v, %v", tasks, err)
+	}
+
+	// Потоковая выгрузка
+	var export strings.Builder
+	err = c.ExportTasks(ctx, &export, ExportOptions{Format: "csv", Columns: []string{"id", "name"}})
+	if err != nil {
+		t.Fatalf("ExportTasks() returned error: %v", err)
+	}
+	if expected := "id,name\n" + task.Id + ",Test Task\n"; !strings.HasPrefix(export.String(), expected) {
+		t.Errorf("Expected export to start with %q, got %q", expected, export.String())
+	}
+	err = c.ExportTasks(ctx, &export, ExportOptions{Columns: []string{"callbackUrl"}})
+	if !errors.Is(err, ErrBadRequest) {
+		t.Errorf("Expected ErrBadRequest for unknown column, got %v", err)
+	}
+
+	// Получение задачи
+	got, err := c.GetTask(ctx, task.Id)
+	if err != nil || got.Id != task.Id {
+		t.Errorf("GetTask() = %+v, %v", got, err)
+	}
+
+	// Незавершенная задача
+	_, err = c.GetTaskResult(ctx, task.Id)
+	if !errors.Is(err, ErrNotCompleted) {
+		t.Errorf("Expected ErrNotCompleted, got %v", err)
+	}
+
+	// Условные запросы по версии задачи
+	if got, err := c.GetTaskIfModified(ctx, task.Id, 0); err != nil || got.Version == 0 {
+		t.Errorf("GetTaskIfModified() = %+v, %v", got, err)
+	}
+	err = c.DeleteTaskIfMatch(ctx, task.Id, 0)
+	if !errors.Is(err, ErrVersionConflict) || !errors.Is(err, pkg.ErrVersionConflict) {
+		t.Errorf("Expected ErrVersionConflict, got %v", err)
+	}
+
+	// Удаление
+	if err := c.DeleteTask(ctx, task.Id); err != nil {
+		t.Fatalf("DeleteTask() returned error: %v", err)
+	}
+	_, err = c.GetTask(ctx, task.Id)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Message != pkg.TaskErrorNotFound {
+		t.Errorf("Expected APIError with message '%s', got %v", pkg.TaskErrorNotFound, err)
+	}
+	if !errors.Is(err, pkg.ErrTaskNotFound) || apiErr.RequestID == "" {
+		t.Errorf("Expected APIError with code '%s' and request id, got %+v", pkg.ErrTaskNotFound.Code, apiErr)
+	}
+}
+
+func TestClientImport(t *testing.T) {
+	source := newTestClient(t, openapi.NewTasksAPIService())
+	target := newTestClient(t, openapi.NewTasksAPIService())
+	ctx := context.Background()
+
+	task, _ := source.CreateTask(ctx, CreateTaskRequest{Name: "Migrated Task"})
+	var dump strings.Builder
+	if err := source.ExportTasks(ctx, &dump, ExportOptions{}); err != nil {
+		t.Fatalf("ExportTasks() returned error: %v", err)
+	}
+
+	report, err := target.ImportTasks(ctx, strings.NewReader(dump.String()), ImportOptions{})
+	if err != nil || report.Created != 1 || report.Records[0].Id != task.Id {
+		t.Fatalf("ImportTasks() = %+v, %v", report, err)
+	}
+	if got, err := target.GetTask(ctx, task.Id); err != nil || got.Name != task.Name {
+		t.Errorf("GetTask() after import = %+v, %v", got, err)
+	}
+
+	// Повторный импорт: конфликт по умолчанию, пропуск с conflict=skip
+	_, err = target.ImportTasks(ctx, strings.NewReader(dump.String()), ImportOptions{})
+	var apiErr *APIError
+	if !errors.Is(err, pkg.ErrTaskExists) || !errors.As(err, &apiErr) || len(apiErr.Violations) != 1 {
+		t.Errorf("Expected conflict with one violation, got %v", err)
+	}
+	report, err = target.ImportTasks(ctx, strings.NewReader(dump.String()), ImportOptions{Conflict: "skip"})
+	if err != nil || report.Skipped != 1 {
+		t.Errorf("ImportTasks(skip) = %+v, %v", report, err)
+	}
+}
+
+func TestClientNamespaces(t *testing.T) {
+	c := newTestClient(t, openapi.NewTasksAPIService())
+	ctx := context.Background()
+
+	// Клиент пространства team-a к тому же серверу
+	teamA := *c
+	if err := WithNamespace("team-a")(&teamA); err != nil {
+		t.Fatalf("WithNamespace() returned error: %v", err)
+	}
+
+	task, err := teamA.CreateTask(ctx, CreateTaskRequest{Name: "Team Task"})
+	if err != nil || task.Namespace != "team-a" {
+		t.Fatalf("CreateTask() in namespace = %+v, %v", task, err)
+	}
+	if _, err := c.GetTask(ctx, task.Id); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound outside of the namespace, got %v", err)
+	}
+	if tasks, err := teamA.ListTasks(ctx, ListOptions{}); err != nil || len(tasks) != 1 {
+		t.Errorf("ListTasks() in namespace = %v, %v", tasks, err)
+	}
+
+	namespaces, err := c.ListNamespaces(ctx)
+	if err != nil || len(namespaces) != 1 || namespaces[0].Name != "team-a" || namespaces[0].StoredTasks != 1 {
+		t.Errorf("ListNamespaces() = %+v, %v", namespaces, err)
+	}
+	if deleted, err := c.PurgeNamespace(ctx, "team-a"); err != nil || deleted != 1 {
+		t.Errorf("PurgeNamespace() = %d, %v", deleted, err)
+	}
+	if _, err := teamA.GetTask(ctx, task.Id); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after purge, got %v", err)
+	}
+}
+
+func TestClientUsage(t *testing.T) {
+	c := newTestClient(t, openapi.NewTasksAPIService(internal.WithTenantQuotas(pkg.TenantQuota{MaxPendingTasks: 5}, nil)))
+	ctx := context.Background()
+
+	if _, err := c.CreateTask(ctx, CreateTaskRequest{Name: "Task"}); err != nil {
+		t.Fatalf("CreateTask() returned error: %v", err)
+	}
+	report, err := c.GetUsage(ctx, UsageOptions{From: time.Now().Add(-time.Hour)})
+	if err != nil || len(report.Usage) != 1 {
+		t.Fatalf("GetUsage() = %+v, %v", report, err)
+	}
+	if usage := report.Usage[0]; usage.TasksCreated != 1 || usage.Quota.MaxPendingTasks != 5 {
+		t.Errorf("Unexpected usage: %+v", usage)
+	}
+	if _, err := c.GetUsage(ctx, UsageOptions{From: time.Now(), To: time.Now().Add(-time.Hour)}); !errors.Is(err, ErrBadRequest) {
+		t.Errorf("Expected ErrBadRequest for inverted window, got %v", err)
+	}
+}
+
+func TestClientPause(t *testing.T) {
+	c := newTestClient(t, openapi.NewTasksAPIService())
+	ctx := context.Background()
+
+	status, err := c.PauseDispatch(ctx)
+	if err != nil || !status.Paused {
+		t.Fatalf("PauseDispatch() = %+v, %v", status, err)
+	}
+	task, err := c.CreateTask(ctx, CreateTaskRequest{Name: "Task"})
+	if err != nil {
+		t.Fatalf("CreateTask() returned error: %v", err)
+	}
+	if task, err = c.PauseTask(ctx, task.Id); err != nil || task.Status != pkg.TaskStatusPaused {
+		t.Fatalf("PauseTask() = %+v, %v", task, err)
+	}
+	if _, err := c.PauseTask(ctx, task.Id); !errors.Is(err, pkg.ErrIllegalTransition) {
+		t.Errorf("Expected ErrInvalidTransition for paused task, got %v", err)
+	}
+	if status, err = c.GetDispatch(ctx); err != nil || status.PausedTasks != 1 {
+		t.Errorf("GetDispatch() = %+v, %v", status, err)
+	}
+	if task, err = c.ResumeTask(ctx, task.Id); err != nil || task.Status != pkg.TaskStatusPending {
+		t.Errorf("ResumeTask() = %+v, %v", task, err)
+	}
+	if task, err = c.CancelTask(ctx, task.Id); err != nil || task.Status != pkg.TaskStatusCancelled {
+		t.Errorf("CancelTask() = %+v, %v", task, err)
+	}
+	if _, err := c.CancelTask(ctx, task.Id); !errors.Is(err, pkg.ErrIllegalTransition) {
+		t.Errorf("Expected ErrIllegalTransition for cancelled task, got %v", err)
+	}
+	if status, err = c.ResumeDispatch(ctx); err != nil || status.Paused {
+		t.Errorf("ResumeDispatch() = %+v, %v", status, err)
+	}
+}
+
+// reportExecutor - исполнитель, записывающий отчет в хранилище результатов
+type reportExecutor struct{}
+
+func (reportExecutor) Execute(ctx context.Context, task pkg.InternalTask) (string, error) {
+	_, err := internal.WriteResult(ctx, "text/csv", strings.NewReader("id,value\n1,2\n"))
+	return "1 row", err
+}
+
+func TestClientResultContent(t *testing.T) {
+	service := openapi.NewTasksAPIService(internal.WithExecutor(reportExecutor{}),
+		internal.WithResultStore(pkg.NewFileResultStore(t.TempDir())))
+	c := newTestClient(t, service)
+	ctx := context.Background()
+
+	task, err := c.CreateTask(ctx, CreateTaskRequest{Name: "Report"})
+	if err != nil {
+		t.Fatalf("CreateTask() returned error: %v", err)
+	}
+	if task, err = c.WaitForResult(ctx, task.Id, WaitOptions{Interval: time.Millisecond}); err != nil {
+		t.Fatalf("WaitForResult() returned error: %v", err)
+	}
+	if task.Output == nil || task.Output.Size != 13 {
+		t.Fatalf("Unexpected task output %+v", task.Output)
+	}
+
+	var content strings.Builder
+	if err := c.GetTaskResultContent(ctx, task.Id, &content); err != nil || content.String() != "id,value\n1,2\n" {
+		t.Errorf("GetTaskResultContent() = %q, %v", content.String(), err)
+	}
+	if err := c.GetTaskResultContent(ctx, "550e8400-e29b-41d4-a716-446655440000", &content); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for missing task, got %v", err)
+	}
+
+	// Содержимое, не совпадающее с Repr-Digest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Repr-Digest", "sha-256=:n4bQgYhMfWWaL+qgxVrQFaO/TxsrC4Is0V1sFbDwCgg=:")
+		w.Write([]byte("tampered"))
+	}))
+	defer server.Close()
+	tampered, _ := New(server.URL)
+	if err := tampered.GetTaskResultContent(ctx, task.Id, io.Discard); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("Expected ErrChecksumMismatch, got %v", err)
+	}
+}
+
+func TestClientBadRequest(t *testing.T) {
+	c := newTestClient(t, openapi.NewTasksAPIService())
+	ctx := context.Background()
+
+	if _, err := c.CreateTask(ctx, CreateTaskRequest{}); !errors.Is(err, ErrBadRequest) {
+		t.Errorf("Expected ErrBadRequest for empty name, got %v", err)
+	}
+	if _, err := c.ListTasks(ctx, ListOptions{Statuses: []string{"unknown"}}); !errors.Is(err, ErrBadRequest) {
+		t.Errorf("Expected ErrBadRequest for unknown status, got %v", err)
+	}
+}
+
+func TestClientToken(t *testing.T) {
+	service := openapi.NewTasksAPIService()
+	auth, _ := openapi.NewAuthenticator([]openapi.APIKey{{Principal: "alice", Hash: openapi.HashAPIKey("secret")}})
+	router := openapi.NewRouter(openapi.NewTasksAPIController(service))
+	router.Use(auth.Middleware)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	anonymous, _ := New(server.URL)
+	if _, err := anonymous.ListTasks(context.Background(), ListOptions{}); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Expected ErrUnauthorized, got %v", err)
+	}
+
+	alice, _ := New(server.URL, WithToken("secret"))
+	task, err := alice.CreateTask(context.Background(), CreateTaskRequest{Name: "Alice Task"})
+	if err != nil || task.Owner != "alice" {
+		t.Errorf("CreateTask() with token = %+v, %v", task, err)
+	}
+}
+
+func TestClientTLSConfigKeepsHTTPClient(t *testing.T) {
+	server := httptest.NewTLSServer(openapi.NewRouter(openapi.NewTasksAPIController(openapi.NewTasksAPIService())))
+	defer server.Close()
+	pool := server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+
+	// Переданный http.Client и его транспорт остаются без изменений
+	transport := &http.Transport{MaxIdleConns: 7}
+	httpClient := &http.Client{Transport: transport, Timeout: time.Minute}
+	config := &tls.Config{RootCAs: pool}
+	c, err := New(server.URL, WithHTTPClient(httpClient), WithTLSConfig(config))
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+	if httpClient.Transport != transport || transport.TLSClientConfig == config ||
+		(transport.TLSClientConfig != nil && transport.TLSClientConfig.RootCAs != nil) {
+		t.Error("WithTLSConfig() modified the caller's http.Client")
+	}
+	if c.httpClient.Timeout != time.Minute || c.httpClient.Transport.(*http.Transport).MaxIdleConns != 7 {
+		t.Errorf("Expected client settings to be kept, got %+v", c.httpClient)
+	}
+	if _, err := c.ListTasks(context.Background(), ListOptions{}); err != nil {
+		t.Errorf("ListTasks() over TLS returned error: %v", err)
+	}
+
+	// Порядок опций не важен; клиент без своего транспорта получает новый
+	bare := &http.Client{}
+	c, _ = New(server.URL, WithTLSConfig(&tls.Config{RootCAs: pool}), WithHTTPClient(bare))
+	if bare.Transport != nil {
+		t.Error("WithTLSConfig() set the transport of the caller's http.Client")
+	}
+	if _, err := c.ListTasks(context.Background(), ListOptions{}); err != nil {
+		t.Errorf("ListTasks() over TLS returned error: %v", err)
+	}
+}
+
+func TestClientDoesNotImportServer(t *testing.T) {
+	info, err := build.ImportDir(".", 0)
+	if err != nil {
+		t.Fatalf("ImportDir() returned error: %v", err)
+	}
+	for _, path := range info.Imports {
+		if path == "workmate/api/v1" || path == "workmate/internal" {
+			t.Errorf("SDK should not import the server package %s", path)
+		}
+	}
+}
+
+// completingService - настоящий сервис, у которого результат готов с третьего опроса
+type completingService struct {
+	*openapi.TasksAPIService
+	polls int32
+}
+
+func (s *completingService) GetTaskResult(ctx context.Context, taskId string, ifNoneMatch string) (openapi.ImplResponse, error) {
+	resp, err := s.TasksAPIService.GetTaskResult(ctx, taskId, ifNoneMatch)
+	if resp.Code != http.StatusTooEarly || atomic.AddInt32(&s.polls, 1) < 3 {
+		return resp, err
+	}
+	resp, err = s.TasksAPIService.GetTask(ctx, taskId, "")
+	task := resp.Body.(openapi.TaskResponse)
+	task.Task.Status = pkg.TaskStatusCompleted
+	return openapi.Response(200, task), err
+}
+
+func TestClientWaitForResult(t *testing.T) {
+	service := &completingService{TasksAPIService: openapi.NewTasksAPIService()}
+	c := newTestClient(t, service)
+	ctx := context.Background()
+
+	task, _ := c.CreateTask(ctx, CreateTaskRequest{Name: "Test Task"})
+	result, err := c.WaitForResult(ctx, task.Id, WaitOptions{Interval: time.Millisecond})
+	if err != nil {
+		t.Fatalf("WaitForResult() returned error: %v", err)
+	}
+	if result.Status != pkg.TaskStatusCompleted || service.polls != 3 {
+		t.Errorf("Expected completed task after 3 polls, got %s after %d", result.Status, service.polls)
+	}
+
+	// Несуществующая задача - ошибка сразу, без ожидания
+	if _, err := c.WaitForResult(ctx, "non-existent-id", WaitOptions{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestClientWaitForResultTimeout(t *testing.T) {
+	c := newTestClient(t, openapi.NewTasksAPIService())
+
+	task, _ := c.CreateTask(context.Background(), CreateTaskRequest{Name: "Test Task"})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := c.WaitForResult(ctx, task.Id, WaitOptions{Interval: 5 * time.Millisecond})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+	"workmate/pkg"
+)
+
+// Ошибки, с которыми можно сравнивать результат через errors.Is
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("task not found")
+	ErrNotCompleted = errors.New("task is not completed yet")
+	ErrRateLimited  = errors.New("rate limited")
+
+	ErrNotModified     = errors.New("task is not modified")
+	ErrVersionConflict = errors.New("task was modified concurrently")
+
+	ErrChecksumMismatch = errors.New("result content checksum mismatch")
+)
+
+// APIError - ошибка, которую вернул сервер
+type APIError struct {
+	// StatusCode - HTTP статус ответа
+	StatusCode int
+	// Code - машиночитаемый код ошибки (совпадает с Code типизированных ошибок pkg)
+	Code string
+	// Message - текст ошибки из тела ответа
+	Message string
+	// RequestID - идентификатор запроса для поиска в логах сервера
+	RequestID string
+	// RetryAfter - рекомендованная сервером пауза перед повтором (для 429)
+	RetryAfter time.Duration
+	// Violations - нарушения схемы запроса (для 400 и 422) или ошибки записей импорта (422 и 409)
+	Violations []Violation
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("workmate: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+	for _, v := range e.Violations {
+		msg += fmt.Sprintf("; %s:%s %s", v.In, v.Pointer, v.Message)
+	}
+	return msg
+}
+
+// Is - Сопоставить ошибку с ErrNotFound, ErrNotCompleted и т.д. по HTTP статусу,
+// а с типизированными ошибками pkg (pkg.ErrTaskNotFound и т.д.) - по коду
+func (e *APIError) Is(target error) bool {
+	if typed, ok := target.(*pkg.Error); ok {
+		return e.Code != "" && e.Code == typed.Code
+	}
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity ||
+			e.StatusCode == http.StatusRequestEntityTooLarge
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrNotCompleted:
+		return e.StatusCode == http.StatusTooEarly
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrVersionConflict:
+		return e.StatusCode == http.StatusPreconditionFailed
+	}
+	return false
+}
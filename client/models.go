@@ -0,0 +1,254 @@
+package client
+
+import (
+	"strconv"
+	"time"
+)
+
+// Модели API. Определены в клиенте, а не взяты из api/v1, чтобы SDK не тянул за собой сервер;
+// JSON совпадает со схемами api/contract/v1/workmate.yaml
+
+// Заголовки и форматы API
+const (
+	requestIDHeader    = "X-Request-Id"
+	ndjsonContentType  = "application/x-ndjson"
+	exportFormatNDJSON = "ndjson"
+)
+
+// Task - задача
+type Task struct {
+	// Уникальный идентификатор задачи
+	Id string `json:"id"`
+	// Название задачи
+	Name string `json:"name"`
+	// Текущий статус задачи
+	Status string `json:"status"`
+	// Время создания задачи
+	CreatedAt time.Time `json:"createdAt"`
+	// Время начала выполнения задачи
+	StartedAt time.Time `json:"startedAt,omitempty"`
+	// Время завершения задачи
+	FinishedAt time.Time `json:"finishedAt,omitempty"`
+	// Время приостановки задачи (только для приостановленных задач)
+	PausedAt time.Time `json:"pausedAt,omitempty"`
+	// Время последней контрольной точки исполнителя
+	CheckpointAt time.Time `json:"checkpointAt,omitempty"`
+	// Контрольная точка исполнителя в base64 (только в выгрузке и при импорте)
+	Checkpoint string `json:"checkpoint,omitempty"`
+	// Результат выполнения задачи (только для завершенных задач)
+	Result string `json:"result,omitempty"`
+	// Метаданные выходных данных задачи; содержимое - GetTaskResultContent
+	Output *TaskOutput `json:"output,omitempty"`
+	// Описание ошибки (только для неудачных задач)
+	Error string `json:"error,omitempty"`
+	// Продолжительность выполнения задачи, округленная до секунд
+	Duration string `json:"duration,omitempty"`
+	// Продолжительность выполнения задачи в миллисекундах
+	DurationMs int64 `json:"durationMs,omitempty"`
+	// Владелец задачи
+	Owner string `json:"owner,omitempty"`
+	// Пространство имен задачи
+	Namespace string `json:"namespace,omitempty"`
+	// Версия задачи, растет при каждом изменении (совпадает с ETag)
+	Version int64 `json:"version,omitempty"`
+}
+
+// TaskOutput - метаданные выходных данных задачи
+type TaskOutput struct {
+	// Тип содержимого
+	ContentType string `json:"contentType"`
+	// Размер содержимого в байтах
+	Size int64 `json:"size"`
+	// Контрольная сумма содержимого: sha256 и hex
+	Checksum string `json:"checksum"`
+	// Время записи выходных данных
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// CreateTaskRequest - запрос на создание задачи
+type CreateTaskRequest struct {
+	// Название задачи
+	Name string `json:"name"`
+	// Webhook, на который отправляется задача после перехода в финальный статус
+	CallbackUrl string `json:"callbackUrl,omitempty"`
+	// Финальные статусы, о которых нужно уведомлять (по умолчанию - все)
+	CallbackEvents []string `json:"callbackEvents,omitempty"`
+}
+
+// WebhookDelivery - попытка доставки webhook
+type WebhookDelivery struct {
+	// Номер попытки доставки
+	Attempt int32 `json:"attempt"`
+	// Событие (финальный статус задачи)
+	Event string `json:"event"`
+	// Адрес webhook
+	Url string `json:"url"`
+	// Время попытки
+	At time.Time `json:"at"`
+	// Отправленное тело запроса
+	Payload string `json:"payload"`
+	// HTTP статус ответа получателя
+	StatusCode int32 `json:"statusCode,omitempty"`
+	// Начало тела ответа получателя (до 1 КБ)
+	Response string `json:"response,omitempty"`
+	// Ошибка доставки
+	Error string `json:"error,omitempty"`
+}
+
+// TaskTransition - переход задачи между статусами
+type TaskTransition struct {
+	// Статус до перехода (отсутствует для создания и импорта задачи)
+	From string `json:"from,omitempty"`
+	// Статус после перехода
+	To string `json:"to"`
+	// Время перехода
+	At time.Time `json:"at"`
+	// Причина перехода
+	Reason string `json:"reason,omitempty"`
+}
+
+// Violation - нарушение схемы запроса
+type Violation struct {
+	// Часть запроса, в которой найдено нарушение
+	In string `json:"in,omitempty"`
+	// JSON pointer (RFC 6901) на значение
+	Pointer string `json:"pointer"`
+	// Описание нарушения
+	Message string `json:"message"`
+}
+
+// ImportReport - итог импорта задач
+type ImportReport struct {
+	// Число новых задач
+	Created int32 `json:"created"`
+	// Число замененных задач
+	Overwritten int32 `json:"overwritten"`
+	// Число пропущенных задач (conflict=skip)
+	Skipped int32 `json:"skipped"`
+	// Число задач, поставленных в очередь заново
+	Requeued int32 `json:"requeued"`
+	// Итог по каждой записи в порядке строк
+	Records []ImportRecord `json:"records"`
+}
+
+// ImportRecord - итог импорта одной записи
+type ImportRecord struct {
+	// Номер строки выгрузки, начиная с 0
+	Line int32 `json:"line"`
+	// Идентификатор задачи
+	Id string `json:"id"`
+	// Что сделано с задачей
+	Action string `json:"action"`
+	// Задача поставлена в очередь заново
+	Requeued bool `json:"requeued,omitempty"`
+}
+
+// Namespace - пространство имен задач
+type Namespace struct {
+	// Имя пространства имен
+	Name string `json:"name"`
+	// Число задач в статусах pending и running
+	ActiveTasks int32 `json:"activeTasks"`
+	// Число хранимых задач, включая завершенные
+	StoredTasks int32 `json:"storedTasks"`
+
+	Quota NamespaceQuota `json:"quota"`
+}
+
+// NamespaceQuota - лимиты пространства имен (0 - без лимита)
+type NamespaceQuota struct {
+	// Максимум задач в статусах pending и running
+	MaxActiveTasks int32 `json:"maxActiveTasks,omitempty"`
+	// Максимум хранимых задач, включая завершенные
+	MaxStoredTasks int32 `json:"maxStoredTasks,omitempty"`
+}
+
+// UsageReport - потребление принципалов за окно [From, To)
+type UsageReport struct {
+	From  time.Time        `json:"from"`
+	To    time.Time        `json:"to"`
+	Usage []PrincipalUsage `json:"usage"`
+}
+
+// PrincipalUsage - потребление одного принципала
+type PrincipalUsage struct {
+	// Принципал - владелец задач (пустая строка - анонимный доступ без аутентификации)
+	Principal string `json:"principal"`
+	// Число задач, созданных в окне
+	TasksCreated int32 `json:"tasksCreated"`
+	// Число запусков задач, закончившихся в окне
+	TasksFinished int32 `json:"tasksFinished"`
+	// Секунды выполнения задач, пришедшиеся на окно
+	TaskSeconds float64 `json:"taskSeconds"`
+	// Число выполняющихся задач на момент запроса
+	RunningTasks int32 `json:"runningTasks"`
+	// Число задач, ожидающих выполнения, на момент запроса
+	PendingTasks int32 `json:"pendingTasks"`
+
+	Quota TenantQuota `json:"quota"`
+}
+
+// TenantQuota - лимиты принципала (0 - без лимита)
+type TenantQuota struct {
+	// Максимум одновременно выполняющихся задач; лишние ждут в pending
+	MaxRunningTasks int32 `json:"maxRunningTasks,omitempty"`
+	// Максимум задач, ожидающих выполнения
+	MaxPendingTasks int32 `json:"maxPendingTasks,omitempty"`
+	// Максимум задач, созданных за последние 24 часа
+	MaxTasksPerDay int32 `json:"maxTasksPerDay,omitempty"`
+	// Максимум секунд выполнения задач за период PeriodHours
+	MaxTaskSeconds int64 `json:"maxTaskSeconds,omitempty"`
+	// Период MaxTaskSeconds в часах
+	PeriodHours int32 `json:"periodHours,omitempty"`
+}
+
+// DispatchStatus - состояние запуска задач
+type DispatchStatus struct {
+	// Остановлен ли запуск задач (выполняющиеся задачи продолжаются)
+	Paused bool `json:"paused"`
+	// Время остановки запуска задач
+	PausedAt time.Time `json:"pausedAt,omitempty"`
+	// Число задач, ожидающих запуска
+	PendingTasks int32 `json:"pendingTasks"`
+	// Число выполняющихся задач
+	RunningTasks int32 `json:"runningTasks"`
+	// Число приостановленных задач
+	PausedTasks int32 `json:"pausedTasks"`
+}
+
+// Ответы API с обертками
+type (
+	taskResponse struct {
+		Task Task `json:"task"`
+	}
+	taskListResponse struct {
+		Tasks []Task `json:"tasks"`
+	}
+	taskHistoryResponse struct {
+		History []TaskTransition `json:"history"`
+	}
+	deliveryListResponse struct {
+		Deliveries []WebhookDelivery `json:"deliveries"`
+	}
+	namespaceListResponse struct {
+		Namespaces []Namespace `json:"namespaces"`
+	}
+	namespacePurgeReport struct {
+		Namespace string `json:"namespace"`
+		Deleted   int32  `json:"deleted"`
+	}
+)
+
+// problem - тело ошибки (RFC 7807)
+type problem struct {
+	Title      string      `json:"title"`
+	Detail     string      `json:"detail,omitempty"`
+	Code       string      `json:"code"`
+	RequestId  string      `json:"requestId,omitempty"`
+	Violations []Violation `json:"violations,omitempty"`
+}
+
+// etag - ETag версии задачи
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// WaitOptions - параметры ожидания результата
+type WaitOptions struct {
+	// Interval - начальный интервал опроса (по умолчанию 1s)
+	Interval time.Duration
+	// MaxInterval - максимальный интервал опроса (по умолчанию 30s)
+	MaxInterval time.Duration
+	// Multiplier - во сколько раз увеличивается интервал после каждого опроса (по умолчанию 1.5)
+	Multiplier float64
+}
+
+func (o WaitOptions) withDefaults() WaitOptions {
+	if o.Interval <= 0 {
+		o.Interval = time.Second
+	}
+	if o.MaxInterval <= 0 {
+		o.MaxInterval = 30 * time.Second
+	}
+	if o.MaxInterval < o.Interval {
+		o.MaxInterval = o.Interval
+	}
+	if o.Multiplier < 1 {
+		o.Multiplier = 1.5
+	}
+	return o
+}
+
+// WaitForResult - Опрашивать GetTaskResult, пока задача не завершится или не истечет ctx.
+// Интервал опроса растет от Interval до MaxInterval; при 429 выдерживается Retry-After
+func (c *Client) WaitForResult(ctx context.Context, taskId string, opts WaitOptions) (Task, error) {
+	opts = opts.withDefaults()
+	interval := opts.Interval
+
+	for {
+		task, err := c.GetTaskResult(ctx, taskId)
+		if err == nil {
+			return task, nil
+		}
+
+		wait := interval
+		var apiErr *APIError
+		switch {
+		case errors.Is(err, ErrNotCompleted):
+		case errors.Is(err, ErrRateLimited) && errors.As(err, &apiErr):
+			if apiErr.RetryAfter > wait {
+				wait = apiErr.RetryAfter
+			}
+		default:
+			return Task{}, err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return Task{}, ctx.Err()
+		case <-timer.C:
+		}
+
+		interval = time.Duration(float64(interval) * opts.Multiplier)
+		if interval > opts.MaxInterval {
+			interval = opts.MaxInterval
+		}
+	}
+}
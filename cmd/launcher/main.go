@@ -1,47 +1,269 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"crypto/tls"
+	"encoding/json"
+	"flag"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"workmate/api/contract"
+	"workmate/api/docs"
 	openapi "workmate/api/v1"
+	"workmate/internal"
+	"workmate/pkg"
 )
 
 const (
-	certFile = "cert.pem"
-	keyFile  = "key.pem"
-	port     = ":8080"
+	certFile    = "cert.pem"
+	keyFile     = "key.pem"
+	apiKeysFile = "apikeys.json"
+	clientCA    = "client-ca.pem"
+	policyFile  = "policy.json"
+	rateLimits  = "ratelimits.json"
+	namespaces  = "namespaces.json"
+	quotas      = "quotas.json"
+	webhookKey  = "webhook-secret"
+	results     = "results"
+	port        = ":8080"
+
+	certWatchInterval = 10 * time.Second
+
+	// docsPath - путь Swagger UI при запуске с -docs
+	docsPath = "/docs"
 )
 
 func main() {
+	serveDocs := flag.Bool("docs", false, "serve Swagger UI under "+docsPath)
+	flag.Parse()
+
 	log.Printf("WorkMate Task Manager Server starting...")
 
-	tasksAPIService := openapi.NewTasksAPIService()
+	tlsEnabled := fileExists(certFile) && fileExists(keyFile)
+	mtlsEnabled := tlsEnabled && fileExists(clientCA)
+	authEnabled := mtlsEnabled || fileExists(apiKeysFile)
+
+	var limits *openapi.RateLimitConfig
+	var serviceOpts []internal.ServiceOption
+	if fileExists(rateLimits) {
+		var err error
+		limits, err = openapi.LoadRateLimitConfig(rateLimits)
+		if err != nil {
+			log.Fatalf("Error loading rate limits: %v", err)
+		}
+		if limits.MaxActiveTasks > 0 {
+			serviceOpts = append(serviceOpts, internal.WithMaxActiveTasks(limits.MaxActiveTasks))
+		}
+	}
+
+	if fileExists(namespaces) {
+		config, err := openapi.LoadNamespaceConfig(namespaces)
+		if err == nil {
+			err = config.Validate()
+		}
+		if err != nil {
+			log.Fatalf("Error loading namespace quotas: %v", err)
+		}
+		serviceOpts = append(serviceOpts, internal.WithNamespaceQuotas(config.Default, config.Namespaces))
+		log.Printf("Namespace quotas enabled from %s (%d namespaces)", namespaces, len(config.Namespaces))
+	}
+
+	if fileExists(quotas) {
+		config, err := openapi.LoadQuotaConfig(quotas)
+		if err == nil {
+			err = config.Validate()
+		}
+		if err != nil {
+			log.Fatalf("Error loading principal quotas: %v", err)
+		}
+		serviceOpts = append(serviceOpts, internal.WithTenantQuotas(config.Default, config.Principals))
+		log.Printf("Principal quotas enabled from %s (%d principals)", quotas, len(config.Principals))
+	}
+
+	if fileExists(webhookKey) {
+		secret, err := os.ReadFile(webhookKey)
+		if err != nil {
+			log.Fatalf("Error loading webhook secret: %v", err)
+		}
+		serviceOpts = append(serviceOpts, internal.WithWebhookSecret(bytes.TrimSpace(secret)))
+		log.Printf("Webhook signatures enabled")
+	} else {
+		log.Printf("Warning: %s not found, webhooks will be sent unsigned", webhookKey)
+	}
+
+	// Выходные данные задач - в каталоге рядом с файлами настроек сервера
+	serviceOpts = append(serviceOpts, internal.WithResultStore(pkg.NewFileResultStore(results)))
+	log.Printf("Task output content stored in %s/", results)
+
+	var tasksAPIService openapi.TasksAPIServicer = openapi.NewTasksAPIService(serviceOpts...)
+
+	var policy *openapi.Policy
+	if fileExists(policyFile) {
+		if !authEnabled {
+			log.Fatalf("Error: %s requires authentication (%s or %s)", policyFile, apiKeysFile, clientCA)
+		}
+		var err error
+		policy, err = openapi.LoadPolicy(policyFile)
+		if err != nil {
+			log.Fatalf("Error loading access policy: %v", err)
+		}
+		tasksAPIService = openapi.NewTasksAPIPolicy(tasksAPIService, policy)
+		log.Printf("Role-based access control enabled from %s", policyFile)
+	}
+
 	tasksAPIController := openapi.NewTasksAPIController(tasksAPIService)
+	if policy != nil {
+		if err := policy.Validate(tasksAPIController.Routes()); err != nil {
+			log.Fatalf("Error loading access policy: %v", err)
+		}
+	}
+
+	if limits != nil {
+		if err := limits.Validate(tasksAPIController.Routes()); err != nil {
+			log.Fatalf("Error loading rate limits: %v", err)
+		}
+	}
 
 	router := openapi.NewRouter(tasksAPIController)
 
+	var authOpts []openapi.AuthenticatorOption
+	if mtlsEnabled {
+		authOpts = append(authOpts, openapi.WithClientCertificates())
+	}
+
+	var keys []openapi.APIKey
+	if fileExists(apiKeysFile) {
+		var err error
+		keys, err = openapi.LoadAPIKeys(apiKeysFile)
+		if err != nil {
+			log.Fatalf("Error loading API keys: %v", err)
+		}
+		log.Printf("API key authentication enabled (%d keys)", len(keys))
+	} else {
+		log.Printf("Warning: %s not found, API key authentication disabled", apiKeysFile)
+	}
+
+	var limiter *openapi.RateLimiter
+	if limits != nil {
+		limiter = openapi.NewRateLimiter(limits)
+	}
+
+	if authEnabled {
+		authenticator, err := openapi.NewAuthenticator(keys, authOpts...)
+		if err != nil {
+			log.Fatalf("Error loading API keys: %v", err)
+		}
+		// Отказы в аутентификации лимитируются по IP до проверки ключа: перебор ключей тормозится
+		if limiter != nil {
+			router.Use(limiter.UnauthorizedMiddleware)
+		}
+		router.Use(authenticator.Middleware)
+	}
+
+	// Лимиты запросов применяются после аутентификации, чтобы считать запросы по принципалу
+	if limiter != nil {
+		router.Use(limiter.Middleware)
+		log.Printf("Rate limiting enabled from %s", rateLimits)
+	}
+
+	// Запросы проверяются по схемам спецификации до вызова обработчиков
+	spec, err := contract.LoadV1()
+	if err != nil {
+		log.Fatalf("Error loading API spec: %v", err)
+	}
+	router.Use(openapi.NewRequestValidator(spec).Middleware)
+
+	var certs *openapi.CertReloader
+	if tlsEnabled {
+		var err error
+		certs, err = openapi.NewCertReloader(certFile, keyFile)
+		if err != nil {
+			log.Fatalf("Error loading TLS certificate: %v", err)
+		}
+	}
+
+	// /health не требует аутентификации, поэтому живет вне router
+	handler := http.NewServeMux()
+	handler.HandleFunc("/health", healthHandler(certs))
+	handler.Handle("/", router)
+
+	// Документация открывается без аутентификации; "Try it out" обращается к API
+	// того же сервера и передает ключ из Authorize
+	if *serveDocs {
+		docsHandler, err := docs.NewHandler(docsPath, "/api/v1")
+		if err != nil {
+			log.Fatalf("Error loading API docs: %v", err)
+		}
+		handler.Handle(docsPath, docsHandler)
+		handler.Handle(docsPath+"/", docsHandler)
+		log.Printf("Swagger UI enabled at %s/", docsPath)
+	}
+
 	server := &http.Server{
 		Addr:    port,
-		Handler: router,
+		Handler: handler,
 	}
 
-	if !fileExists(certFile) || !fileExists(keyFile) {
+	if !tlsEnabled {
 		log.Printf("Starting HTTP server on %s", port)
 		log.Fatal(server.ListenAndServe())
 	} else {
 		server.TLSConfig = &tls.Config{
-			MinVersion: tls.VersionTLS12,
-			NextProtos: []string{"h2", "http/1.1"}, // Поддержка HTTP/2
+			MinVersion:     tls.VersionTLS12,
+			NextProtos:     []string{"h2", "http/1.1"}, // Поддержка HTTP/2
+			GetCertificate: certs.GetCertificate,
 		}
+		if mtlsEnabled {
+			clientCAs, err := openapi.LoadClientCAs(clientCA)
+			if err != nil {
+				log.Fatalf("Error loading client CA: %v", err)
+			}
+			openapi.RequireClientCertificates(server.TLSConfig, clientCAs)
+			log.Printf("Mutual TLS enabled, client certificates verified against %s", clientCA)
+		}
+		// Сертификаты перечитываются при изменении файлов или по SIGHUP
+		go certs.Watch(context.Background(), certWatchInterval)
+		go reloadOnSIGHUP(certs)
+
 		log.Printf("Starting HTTPS/HTTP2 server on %s", port)
-		log.Fatal(server.ListenAndServeTLS(certFile, keyFile))
+		log.Fatal(server.ListenAndServeTLS("", ""))
 	}
 
 }
 
+func reloadOnSIGHUP(certs *openapi.CertReloader) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	for range hup {
+		log.Printf("SIGHUP received, reloading TLS certificate")
+		if err := certs.Reload(); err != nil {
+			log.Printf("Error reloading TLS certificate, keeping the previous one: %v", err)
+		}
+	}
+}
+
+func healthHandler(certs *openapi.CertReloader) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		health := map[string]interface{}{"status": "ok", "service": "workmate"}
+		if certs != nil {
+			health["certificateExpiresAt"] = certs.NotAfter().Format(time.RFC3339)
+			if certs.ExpiresSoon() {
+				health["status"] = "warning"
+				health["warning"] = "TLS certificate expires soon"
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(health)
+	}
+}
+
 func fileExists(filename string) bool {
 	info, err := os.Stat(filename)
 	if os.IsNotExist(err) {
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const defaultServer = "http://localhost:8080"
+
+// config - параметры подключения: флаги > переменные окружения > файл конфигурации > умолчания
+type config struct {
+	Server    string `json:"server"`
+	Token     string `json:"token"`
+	CA        string `json:"ca"`
+	Cert      string `json:"cert"`
+	Key       string `json:"key"`
+	Output    string `json:"output"`
+	Namespace string `json:"namespace"`
+}
+
+// env - переменные окружения для полей config
+var env = map[string]func(c *config) *string{
+	"WORKMATE_SERVER":    func(c *config) *string { return &c.Server },
+	"WORKMATE_TOKEN":     func(c *config) *string { return &c.Token },
+	"WORKMATE_CA":        func(c *config) *string { return &c.CA },
+	"WORKMATE_CERT":      func(c *config) *string { return &c.Cert },
+	"WORKMATE_KEY":       func(c *config) *string { return &c.Key },
+	"WORKMATE_OUTPUT":    func(c *config) *string { return &c.Output },
+	"WORKMATE_NAMESPACE": func(c *config) *string { return &c.Namespace },
+}
+
+// defaultConfigPath - ~/.config/workmate/config.json
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "workmate", "config.json")
+}
+
+// loadConfigFile - Прочитать файл конфигурации. Отсутствие файла по умолчанию не ошибка
+func loadConfigFile(path string, explicit bool) (config, error) {
+	var c config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			return c, nil
+		}
+		return c, err
+	}
+	err = json.Unmarshal(data, &c)
+	return c, err
+}
+
+// merge - Перенести непустые поля из override
+func (c *config) merge(override config) {
+	for _, pair := range [][2]*string{
+		{&c.Server, &override.Server},
+		{&c.Token, &override.Token},
+		{&c.CA, &override.CA},
+		{&c.Cert, &override.Cert},
+		{&c.Key, &override.Key},
+		{&c.Output, &override.Output},
+		{&c.Namespace, &override.Namespace},
+	} {
+		if *pair[1] != "" {
+			*pair[0] = *pair[1]
+		}
+	}
+}
+
+// fromEnv - Значения из переменных окружения
+func fromEnv(lookup func(string) (string, bool)) config {
+	var c config
+	for name, field := range env {
+		if value, ok := lookup(name); ok {
+			*field(&c) = value
+		}
+	}
+	return c
+}
+
+// parseGlobalFlags - Разобрать глобальные флаги и собрать итоговую конфигурацию.
+// Возвращает оставшиеся аргументы (команду и ее аргументы)
+func parseGlobalFlags(args []string, lookupEnv func(string) (string, bool)) (config, []string, error) {
+	fs := flag.NewFlagSet("workmatectl", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usageText)
+		fs.PrintDefaults()
+	}
+
+	var flags config
+	configPath := fs.String("config", "", "config file (default "+defaultConfigPath()+")")
+	fs.StringVar(&flags.Server, "server", "", "server URL, env WORKMATE_SERVER (default "+defaultServer+")")
+	fs.StringVar(&flags.Token, "token", "", "API key, env WORKMATE_TOKEN")
+	fs.StringVar(&flags.CA, "ca", "", "CA certificate for TLS, e.g. ca.pem from gen-certs.sh, env WORKMATE_CA")
+	fs.StringVar(&flags.Cert, "cert", "", "client certificate for mTLS, env WORKMATE_CERT")
+	fs.StringVar(&flags.Key, "key", "", "client private key for mTLS, env WORKMATE_KEY")
+	fs.StringVar(&flags.Output, "o", "", "output format: table or json, env WORKMATE_OUTPUT")
+	fs.StringVar(&flags.Namespace, "n", "", "task namespace, env WORKMATE_NAMESPACE (default: the default namespace)")
+	if err := fs.Parse(args); err != nil {
+		return config{}, nil, err
+	}
+
+	path, explicit := *configPath, *configPath != ""
+	if !explicit {
+		if value, ok := lookupEnv("WORKMATE_CONFIG"); ok {
+			path, explicit = value, true
+		} else {
+			path = defaultConfigPath()
+		}
+	}
+
+	c := config{Server: defaultServer, Output: "table"}
+	if path != "" {
+		file, err := loadConfigFile(path, explicit)
+		if err != nil {
+			return config{}, nil, err
+		}
+		c.merge(file)
+	}
+	c.merge(fromEnv(lookupEnv))
+	c.merge(flags)
+
+	c.Output = strings.ToLower(c.Output)
+	if c.Output != "table" && c.Output != "json" {
+		return config{}, nil, errors.New("output format must be table or json")
+	}
+	return c, fs.Args(), nil
+}
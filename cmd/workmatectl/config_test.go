@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseGlobalFlagsPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(path, []byte(`{"server": "https://file:8080", "token": "file-token", "ca": "file-ca.pem"}`), 0600)
+
+	env := map[string]string{
+		"WORKMATE_CONFIG": path,
+		"WORKMATE_TOKEN":  "env-token",
+	}
+	lookup := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	cfg, args, err := parseGlobalFlags([]string{"-server", "https://flag:8080", "list", "-limit", "5"}, lookup)
+	if err != nil {
+		t.Fatalf("parseGlobalFlags() returned error: %v", err)
+	}
+	if cfg.Server != "https://flag:8080" {
+		t.Errorf("Expected server from flag, got '%s'", cfg.Server)
+	}
+	if cfg.Token != "env-token" {
+		t.Errorf("Expected token from env, got '%s'", cfg.Token)
+	}
+	if cfg.CA != "file-ca.pem" {
+		t.Errorf("Expected CA from config file, got '%s'", cfg.CA)
+	}
+	if cfg.Output != "table" {
+		t.Errorf("Expected default output 'table', got '%s'", cfg.Output)
+	}
+	if len(args) != 3 || args[0] != "list" {
+		t.Errorf("Expected remaining args [list -limit 5], got %v", args)
+	}
+
+	// Явно указанный, но отсутствующий файл - ошибка
+	env["WORKMATE_CONFIG"] = filepath.Join(t.TempDir(), "missing.json")
+	if _, _, err := parseGlobalFlags([]string{"list"}, lookup); err == nil {
+		t.Error("parseGlobalFlags() with missing explicit config should return error")
+	}
+}
@@ -0,0 +1,520 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"workmate/client"
+)
+
+const usageText = `Usage: workmatectl [global flags] <command> [flags] [args]
+
+Commands:
+  create <name> [-callback url]   create a task
+  list [-status s1,s2] [-limit n]  list tasks
+  get <taskId>                     show a task
+  history <taskId>                 show status transitions of a task
+  download [-o file] <taskId>      save the output content of a completed task (default stdout)
+  wait <taskId> [-timeout d]       wait for a task to finish and show its result
+  cancel <taskId>                  cancel a pending, running or paused task (it stays listed as cancelled)
+  delete [-if-version n] <taskId>  delete a task (only if unchanged since version n)
+  pause <taskId>                   pause a pending or running task
+  resume <taskId>                  put a paused task back into the queue
+  watch [-interval d] [-status s]  live-refresh the task table
+  export [-format csv] [-columns c1,c2] [-status s] [-limit n]
+                                   stream tasks to stdout as NDJSON or CSV
+  import [-conflict fail|skip|overwrite] [-requeue] <file|->
+                                   load tasks from an NDJSON export (admin)
+  namespaces                       list namespaces with task counts and quotas
+  purge <namespace>                delete all tasks of a namespace (admin)
+  usage [-since d] [-principal p]  show tasks and task-seconds per principal over the last d (default 24h)
+  dispatch [pause|resume]          show, stop or restart dispatch of pending tasks (admin)
+
+Global flags:
+`
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(2)
+		}
+		fmt.Fprintf(os.Stderr, "workmatectl: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string) error {
+	cfg, args, err := parseGlobalFlags(args, os.LookupEnv)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		// Печатает справку и возвращает flag.ErrHelp
+		_, _, err := parseGlobalFlags([]string{"-h"}, os.LookupEnv)
+		return err
+	}
+
+	c, err := newClient(cfg)
+	if err != nil {
+		return err
+	}
+	cli := &cli{client: c, cfg: cfg}
+
+	command, args := args[0], args[1:]
+	switch command {
+	case "create":
+		return cli.create(ctx, args)
+	case "list", "ls":
+		return cli.list(ctx, args)
+	case "get", "inspect":
+		return cli.get(ctx, args)
+	case "history":
+		return cli.history(ctx, args)
+	case "download":
+		return cli.download(ctx, args)
+	case "wait":
+		return cli.wait(ctx, args)
+	case "cancel":
+		return cli.cancel(ctx, args)
+	case "delete", "rm":
+		return cli.delete(ctx, args)
+	case "pause":
+		return cli.pause(ctx, args)
+	case "resume":
+		return cli.resume(ctx, args)
+	case "watch":
+		return cli.watch(ctx, args)
+	case "export":
+		return cli.export(ctx, args)
+	case "import":
+		return cli.importTasks(ctx, args)
+	case "namespaces", "ns":
+		return cli.namespaces(ctx, args)
+	case "purge":
+		return cli.purge(ctx, args)
+	case "usage":
+		return cli.usage(ctx, args)
+	case "dispatch":
+		return cli.dispatch(ctx, args)
+	default:
+		return fmt.Errorf("unknown command %q, run workmatectl -h for help", command)
+	}
+}
+
+func newClient(cfg config) (*client.Client, error) {
+	opts := []client.Option{client.WithUserAgent("workmatectl")}
+	if cfg.Token != "" {
+		opts = append(opts, client.WithToken(cfg.Token))
+	}
+	if cfg.Namespace != "" {
+		opts = append(opts, client.WithNamespace(cfg.Namespace))
+	}
+	if cfg.CA != "" || cfg.Cert != "" || cfg.Key != "" {
+		tlsConfig, err := client.TLSConfig(cfg.CA, cfg.Cert, cfg.Key)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, client.WithTLSConfig(tlsConfig))
+	}
+	return client.New(cfg.Server, opts...)
+}
+
+type cli struct {
+	client *client.Client
+	cfg    config
+}
+
+// taskIdArg - Разобрать флаги команды и единственный аргумент taskId
+func taskIdArg(fs *flag.FlagSet, args []string) (string, error) {
+	if err := fs.Parse(args); err != nil {
+		return "", err
+	}
+	if fs.NArg() != 1 {
+		return "", fmt.Errorf("%s: expected exactly one task id", fs.Name())
+	}
+	return fs.Arg(0), nil
+}
+
+func splitList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+func (c *cli) printTask(task client.Task) error {
+	if c.cfg.Output == "json" {
+		return printJSON(os.Stdout, task)
+	}
+	return printTask(os.Stdout, task, time.Now())
+}
+
+func (c *cli) create(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("create", flag.ContinueOnError)
+	callback := fs.String("callback", "", "webhook URL called when the task finishes")
+	events := fs.String("events", "", "comma-separated final statuses for the webhook (default all)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("create: expected exactly one task name")
+	}
+
+	task, err := c.client.CreateTask(ctx, client.CreateTaskRequest{
+		Name:           fs.Arg(0),
+		CallbackUrl:    *callback,
+		CallbackEvents: splitList(*events),
+	})
+	if err != nil {
+		return err
+	}
+	return c.printTask(task)
+}
+
+func (c *cli) listFlags(name string) (*flag.FlagSet, *client.ListOptions) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	opts := &client.ListOptions{}
+	fs.Func("status", "comma-separated statuses to show", func(v string) error {
+		opts.Statuses = splitList(v)
+		return nil
+	})
+	fs.IntVar(&opts.Limit, "limit", 0, "maximum number of tasks")
+	fs.Func("since", "show tasks created within this duration, e.g. 1h", func(v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		opts.CreatedAfter = time.Now().Add(-d)
+		return nil
+	})
+	return fs, opts
+}
+
+func (c *cli) list(ctx context.Context, args []string) error {
+	fs, opts := c.listFlags("list")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	tasks, err := c.client.ListTasks(ctx, *opts)
+	if err != nil {
+		return err
+	}
+	if c.cfg.Output == "json" {
+		return printJSON(os.Stdout, tasks)
+	}
+	return printTasks(os.Stdout, tasks, time.Now())
+}
+
+func (c *cli) export(ctx context.Context, args []string) error {
+	fs, list := c.listFlags("export")
+	opts := client.ExportOptions{}
+	fs.StringVar(&opts.Format, "format", "ndjson", "output format: ndjson or csv")
+	fs.Func("columns", "comma-separated columns in output order (default all)", func(v string) error {
+		opts.Columns = splitList(v)
+		return nil
+	})
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	opts.ListOptions = *list
+
+	return c.client.ExportTasks(ctx, os.Stdout, opts)
+}
+
+func (c *cli) importTasks(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	opts := client.ImportOptions{}
+	fs.StringVar(&opts.Conflict, "conflict", "fail", "what to do with existing task ids: fail, skip or overwrite")
+	fs.BoolVar(&opts.Requeue, "requeue", false, "restart imported pending and running tasks")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("import: expected exactly one file (- for stdin)")
+	}
+
+	in := os.Stdin
+	if fs.Arg(0) != "-" {
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	report, err := c.client.ImportTasks(ctx, in, opts)
+	if err != nil {
+		return err
+	}
+	if c.cfg.Output == "json" {
+		return printJSON(os.Stdout, report)
+	}
+	return printImportReport(os.Stdout, report)
+}
+
+func (c *cli) get(ctx context.Context, args []string) error {
+	taskId, err := taskIdArg(flag.NewFlagSet("get", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+
+	task, err := c.client.GetTask(ctx, taskId)
+	if err != nil {
+		return err
+	}
+	return c.printTask(task)
+}
+
+func (c *cli) history(ctx context.Context, args []string) error {
+	taskId, err := taskIdArg(flag.NewFlagSet("history", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+
+	history, err := c.client.GetTaskHistory(ctx, taskId)
+	if err != nil {
+		return err
+	}
+	if c.cfg.Output == "json" {
+		return printJSON(os.Stdout, history)
+	}
+	return printHistory(os.Stdout, history)
+}
+
+func (c *cli) download(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("download", flag.ContinueOnError)
+	out := fs.String("o", "", "write content to this file instead of stdout")
+	taskId, err := taskIdArg(fs, args)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		return c.client.GetTaskResultContent(ctx, taskId, os.Stdout)
+	}
+
+	// Недокачанный или не прошедший проверку суммы файл не оставляем
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	err = c.client.GetTaskResultContent(ctx, taskId, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(*out)
+	}
+	return err
+}
+
+func (c *cli) wait(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("wait", flag.ContinueOnError)
+	timeout := fs.Duration("timeout", 0, "give up after this duration (default wait forever)")
+	interval := fs.Duration("interval", 2*time.Second, "initial polling interval")
+	taskId, err := taskIdArg(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	task, err := c.client.WaitForResult(ctx, taskId, client.WaitOptions{Interval: *interval})
+	if err != nil {
+		return err
+	}
+	if err := c.printTask(task); err != nil {
+		return err
+	}
+	if task.Error != "" {
+		return fmt.Errorf("task %s %s", task.Id, task.Status)
+	}
+	return nil
+}
+
+func (c *cli) delete(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("delete", flag.ContinueOnError)
+	version := fs.Int64("if-version", 0, "delete only if the task is still at this version (see get)")
+	taskId, err := taskIdArg(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if *version > 0 {
+		err = c.client.DeleteTaskIfMatch(ctx, taskId, *version)
+	} else {
+		err = c.client.DeleteTask(ctx, taskId)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "task %s deleted\n", taskId)
+	return nil
+}
+
+func (c *cli) cancel(ctx context.Context, args []string) error {
+	taskId, err := taskIdArg(flag.NewFlagSet("cancel", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+
+	task, err := c.client.CancelTask(ctx, taskId)
+	if err != nil {
+		return err
+	}
+	return c.printTask(task)
+}
+
+func (c *cli) pause(ctx context.Context, args []string) error {
+	taskId, err := taskIdArg(flag.NewFlagSet("pause", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+
+	task, err := c.client.PauseTask(ctx, taskId)
+	if err != nil {
+		return err
+	}
+	return c.printTask(task)
+}
+
+func (c *cli) resume(ctx context.Context, args []string) error {
+	taskId, err := taskIdArg(flag.NewFlagSet("resume", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+
+	task, err := c.client.ResumeTask(ctx, taskId)
+	if err != nil {
+		return err
+	}
+	return c.printTask(task)
+}
+
+func (c *cli) watch(ctx context.Context, args []string) error {
+	fs, opts := c.listFlags("watch")
+	interval := fs.Duration("interval", 2*time.Second, "refresh interval")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+
+	for {
+		tasks, err := c.client.ListTasks(ctx, *opts)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		// Очистка экрана и курсор в начало
+		fmt.Print("\033[H\033[2J")
+		fmt.Printf("Every %s: %s    %s\n\n", *interval, c.cfg.Server, time.Now().Format(time.TimeOnly))
+		if err != nil {
+			fmt.Printf("error: %v\n", err)
+		} else if err := printTasks(os.Stdout, tasks, time.Now()); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *cli) namespaces(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("namespaces", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	namespaces, err := c.client.ListNamespaces(ctx)
+	if err != nil {
+		return err
+	}
+	if c.cfg.Output == "json" {
+		return printJSON(os.Stdout, namespaces)
+	}
+	return printNamespaces(os.Stdout, namespaces)
+}
+
+func (c *cli) purge(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("purge", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("purge: expected exactly one namespace")
+	}
+
+	deleted, err := c.client.PurgeNamespace(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "namespace %s purged: %d tasks deleted\n", fs.Arg(0), deleted)
+	return nil
+}
+
+func (c *cli) usage(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("usage", flag.ContinueOnError)
+	since := fs.Duration("since", 24*time.Hour, "window length up to now")
+	principal := fs.String("principal", "", "show only this principal")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *since <= 0 {
+		return errors.New("usage: -since must be positive")
+	}
+
+	report, err := c.client.GetUsage(ctx, client.UsageOptions{From: time.Now().Add(-*since), Principal: *principal})
+	if err != nil {
+		return err
+	}
+	if c.cfg.Output == "json" {
+		return printJSON(os.Stdout, report)
+	}
+	return printUsage(os.Stdout, report)
+}
+
+func (c *cli) dispatch(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("dispatch", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var status client.DispatchStatus
+	var err error
+	switch fs.Arg(0) {
+	case "":
+		status, err = c.client.GetDispatch(ctx)
+	case "pause":
+		status, err = c.client.PauseDispatch(ctx)
+	case "resume":
+		status, err = c.client.ResumeDispatch(ctx)
+	default:
+		return fmt.Errorf("dispatch: unknown action %q, expected pause or resume", fs.Arg(0))
+	}
+	if err != nil {
+		return err
+	}
+	if c.cfg.Output == "json" {
+		return printJSON(os.Stdout, status)
+	}
+	return printDispatch(os.Stdout, status)
+}
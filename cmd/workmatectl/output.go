@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"workmate/client"
+)
+
+// taskDuration - Продолжительность задачи: до завершения, приостановки или до текущего момента
+func taskDuration(task client.Task, now time.Time) time.Duration {
+	if task.StartedAt.IsZero() {
+		return 0
+	}
+	end := task.FinishedAt
+	if end.IsZero() {
+		end = task.PausedAt
+	}
+	if end.IsZero() {
+		end = now
+	}
+	return end.Sub(task.StartedAt).Round(time.Second)
+}
+
+// outcome - Результат или ошибка задачи одной строкой
+func outcome(task client.Task) string {
+	text := task.Result
+	if task.Error != "" {
+		text = "error: " + task.Error
+	}
+	text = strings.ReplaceAll(text, "\n", " ")
+	if len(text) > 60 {
+		text = text[:57] + "..."
+	}
+	return text
+}
+
+// printTasks - Вывести задачи таблицей
+func printTasks(w io.Writer, tasks []client.Task, now time.Time) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tSTATUS\tOWNER\tCREATED\tDURATION\tRESULT")
+	for _, task := range tasks {
+		duration := "-"
+		if d := taskDuration(task, now); !task.StartedAt.IsZero() {
+			duration = d.String()
+		}
+		owner := task.Owner
+		if owner == "" {
+			owner = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			task.Id, task.Name, task.Status, owner, task.CreatedAt.Local().Format(time.DateTime), duration, outcome(task))
+	}
+	return tw.Flush()
+}
+
+// printTask - Вывести одну задачу подробно
+func printTask(w io.Writer, task client.Task, now time.Time) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	row := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(tw, "%s:\t%s\n", name, value)
+		}
+	}
+	formatTime := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Local().Format(time.RFC3339)
+	}
+
+	row("ID", task.Id)
+	row("Name", task.Name)
+	row("Status", task.Status)
+	row("Owner", task.Owner)
+	if task.Version > 0 {
+		row("Version", strconv.FormatInt(task.Version, 10))
+	}
+	row("Created", formatTime(task.CreatedAt))
+	row("Started", formatTime(task.StartedAt))
+	row("Paused", formatTime(task.PausedAt))
+	row("Checkpoint", formatTime(task.CheckpointAt))
+	row("Finished", formatTime(task.FinishedAt))
+	if !task.StartedAt.IsZero() {
+		row("Duration", taskDuration(task, now).String())
+	}
+	row("Result", task.Result)
+	if task.Output != nil {
+		row("Output", fmt.Sprintf("%s, %d bytes, %s", task.Output.ContentType, task.Output.Size, task.Output.Checksum))
+	}
+	row("Error", task.Error)
+	return tw.Flush()
+}
+
+// printHistory - Вывести переходы статуса задачи таблицей
+func printHistory(w io.Writer, history []client.TaskTransition) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "AT\tFROM\tTO\tREASON")
+	for _, t := range history {
+		from := t.From
+		if from == "" {
+			from = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", t.At.Local().Format(time.RFC3339), from, t.To, t.Reason)
+	}
+	return tw.Flush()
+}
+
+// printImportReport - Вывести итог импорта: строка на задачу и сводка
+func printImportReport(w io.Writer, report client.ImportReport) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "LINE	ID	ACTION	REQUEUED")
+	for _, record := range report.Records {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%t\n", record.Line, record.Id, record.Action, record.Requeued)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "created: %d, overwritten: %d, skipped: %d, requeued: %d\n",
+		report.Created, report.Overwritten, report.Skipped, report.Requeued)
+	return err
+}
+
+// printNamespaces - Вывести пространства имен таблицей (0 в лимите - без лимита)
+func printNamespaces(w io.Writer, namespaces []client.Namespace) error {
+	limit := func(n int32) string {
+		if n == 0 {
+			return "-"
+		}
+		return strconv.Itoa(int(n))
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAMESPACE\tACTIVE\tSTORED\tMAX ACTIVE\tMAX STORED")
+	for _, ns := range namespaces {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\n",
+			ns.Name, ns.ActiveTasks, ns.StoredTasks, limit(ns.Quota.MaxActiveTasks), limit(ns.Quota.MaxStoredTasks))
+	}
+	return tw.Flush()
+}
+
+// printUsage - Вывести потребление по принципалам таблицей (анонимный принципал - "-")
+func printUsage(w io.Writer, report client.UsageReport) error {
+	if _, err := fmt.Fprintf(w, "window: %s - %s\n", report.From.Local().Format(time.RFC3339), report.To.Local().Format(time.RFC3339)); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PRINCIPAL\tCREATED\tFINISHED\tTASK-SECONDS\tRUNNING\tPENDING")
+	for _, u := range report.Usage {
+		principal := u.Principal
+		if principal == "" {
+			principal = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.0f\t%d\t%d\n",
+			principal, u.TasksCreated, u.TasksFinished, u.TaskSeconds, u.RunningTasks, u.PendingTasks)
+	}
+	return tw.Flush()
+}
+
+// printDispatch - Вывести состояние запуска задач
+func printDispatch(w io.Writer, status client.DispatchStatus) error {
+	state := "running"
+	if status.Paused {
+		state = "paused since " + status.PausedAt.Local().Format(time.RFC3339)
+	}
+	_, err := fmt.Fprintf(w, "dispatch: %s\npending: %d, running: %d, paused: %d\n",
+		state, status.PendingTasks, status.RunningTasks, status.PausedTasks)
+	return err
+}
+
+// printJSON - Вывести значение в JSON
+func printJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
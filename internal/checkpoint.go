@@ -0,0 +1,39 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"workmate/pkg"
+)
+
+// SaveCheckpoint - Сохранить контрольную точку выполняющейся задачи: непрозрачные данные исполнителя
+// (не больше pkg.MaxCheckpointSize), которые он получит в InternalTask.Checkpoint при следующем
+// запуске задачи - после ResumeTask или повторной постановки в очередь при импорте.
+// ctx - контекст Execute; сохранять можно до возврата из Execute, в том числе после приостановки
+func SaveCheckpoint(ctx context.Context, data []byte) error {
+	rc, err := runFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	return rc.service.saveCheckpoint(rc.taskId, rc.run, data)
+}
+
+// saveCheckpoint - Записать контрольную точку, если запуск run еще не закончен
+func (s *Service) saveCheckpoint(taskId string, run *taskRun, data []byte) error {
+	if len(data) > pkg.MaxCheckpointSize {
+		return fmt.Errorf("%w: checkpoint of %d bytes exceeds %d", pkg.ErrInvalidParameters, len(data), pkg.MaxCheckpointSize)
+	}
+
+	// Под dispatch запуск не закончится и следующий запуск не начнется до записи
+	s.dispatch.Lock()
+	defer s.dispatch.Unlock()
+
+	if !s.isCurrentRun(taskId, run) {
+		return fmt.Errorf("%w: task %s", errRunFinished, taskId)
+	}
+	_, err := s.store.ModifyTask(taskId, func(task *pkg.InternalTask) {
+		task.Checkpoint = append([]byte(nil), data...)
+		task.CheckpointAt = s.clock.Now()
+	})
+	return err
+}
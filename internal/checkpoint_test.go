@@ -0,0 +1,106 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+	"workmate/pkg"
+)
+
+// checkpointExecutor - исполнитель, который передает в started полученную контрольную точку,
+// сохраняет новую и ждет отмены
+type checkpointExecutor struct {
+	started chan []byte
+}
+
+func (e *checkpointExecutor) Execute(ctx context.Context, task pkg.InternalTask) (string, error) {
+	e.started <- task.Checkpoint
+	if err := SaveCheckpoint(ctx, []byte("step-2")); err != nil {
+		return "", err
+	}
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func TestSaveCheckpoint(t *testing.T) {
+	executor := &checkpointExecutor{started: make(chan []byte, 1)}
+	service := NewService(WithExecutor(executor))
+	ctx := context.Background()
+
+	if err := SaveCheckpoint(ctx, []byte("step-1")); err == nil {
+		t.Error("SaveCheckpoint() outside of a task run should return error")
+	}
+
+	// Восстановление из копии: контрольная точка приходит исполнителю при повторном запуске
+	start := service.Now().Add(-time.Hour)
+	record := pkg.InternalTask{
+		Id: "550e8400-e29b-41d4-a716-446655440000", Name: "Restored Task", Status: pkg.TaskStatusRunning,
+		CreatedAt: start, StartedAt: start, Checkpoint: []byte("step-1"), CheckpointAt: start.Add(time.Minute),
+	}
+	if _, err := service.ImportTasks(ctx, []pkg.InternalTask{record}, ImportOptions{Requeue: true}); err != nil {
+		t.Fatalf("ImportTasks() returned error: %v", err)
+	}
+	if checkpoint := <-executor.started; string(checkpoint) != "step-1" {
+		t.Errorf("Expected checkpoint step-1 on requeue, got %q", checkpoint)
+	}
+
+	running := waitForStatus(t, service, record.Id, pkg.TaskStatusRunning)
+	for i := 0; i < 1000 && string(running.Checkpoint) != "step-2"; i++ {
+		time.Sleep(time.Millisecond)
+		running, _ = service.store.GetTask(record.Id)
+	}
+	if string(running.Checkpoint) != "step-2" || running.CheckpointAt.IsZero() {
+		t.Errorf("Expected saved checkpoint step-2, got %q at %s", running.Checkpoint, running.CheckpointAt)
+	}
+}
+
+func TestSimulationResumesFromCheckpoint(t *testing.T) {
+	start := time.Date(2024, 1, 15, 15, 4, 5, 0, time.UTC)
+	clock := pkg.NewFakeClock(start)
+	service := NewService(WithClock(clock), WithRandSeed(42))
+	ctx := context.Background()
+	expected := time.Duration(rand.New(rand.NewSource(42)).Intn(121)+180) * time.Second
+
+	task, _ := service.CreateTask(ctx, "Test Task")
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	if _, err := service.PauseTask(ctx, task.Id); err != nil {
+		t.Fatalf("PauseTask() returned error: %v", err)
+	}
+
+	// Симуляция сохраняет отработанное время, пока запуск не закончен
+	paused, _ := service.store.GetTask(task.Id)
+	for i := 0; i < 1000 && paused.Checkpoint == nil; i++ {
+		time.Sleep(time.Millisecond)
+		paused, _ = service.store.GetTask(task.Id)
+	}
+	if paused.Checkpoint == nil {
+		t.Fatal("Expected simulation to save a checkpoint on pause")
+	}
+
+	// Время в паузе не засчитывается: после возобновления остается expected - 1m
+	clock.Advance(time.Hour)
+	if _, err := service.ResumeTask(ctx, task.Id); err != nil {
+		t.Fatalf("ResumeTask() returned error: %v", err)
+	}
+	clock.BlockUntil(1)
+	clock.Advance(expected - time.Minute - time.Second)
+	if running, _ := service.GetTask(ctx, task.Id); running.Status != pkg.TaskStatusRunning {
+		t.Fatalf("Expected task status '%s', got '%s'", pkg.TaskStatusRunning, running.Status)
+	}
+
+	clock.Advance(time.Second)
+	completed := waitForStatus(t, service, task.Id, pkg.TaskStatusCompleted)
+	if completed.Result != fmt.Sprintf("Task completed successfully after %s", expected) {
+		t.Errorf("Unexpected result '%s'", completed.Result)
+	}
+	if completed.Checkpoint != nil || !completed.CheckpointAt.IsZero() {
+		t.Error("Expected checkpoint to be cleared on completion")
+	}
+	if err := service.saveCheckpoint(task.Id, nil, make([]byte, pkg.MaxCheckpointSize+1)); !errors.Is(err, pkg.ErrInvalidParameters) {
+		t.Errorf("Expected ErrInvalidParameters for oversized checkpoint, got %v", err)
+	}
+}
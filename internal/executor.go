@@ -0,0 +1,168 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"workmate/pkg"
+)
+
+// Executor - исполнитель задач. Execute выполняет задачу и возвращает результат; ошибка -
+// задача завершилась неудачей. При отмене ctx исполнитель должен вернуть управление:
+// context.Cause(ctx) == pkg.ErrTaskPaused - задачу приостановили, иначе ее отменили.
+// Прогресс можно сохранять через SaveCheckpoint(ctx, ...): последняя контрольная точка
+// приходит в task.Checkpoint при следующем запуске задачи
+type Executor interface {
+	Execute(ctx context.Context, task pkg.InternalTask) (result string, err error)
+}
+
+// Suspender - исполнитель, который умеет приостанавливать выполняющиеся задачи без потери
+// прогресса и продолжать их при следующем Execute. Выполняющиеся задачи остальных
+// исполнителей приостановить нельзя: приостанавливаются только ожидающие запуска
+type Suspender interface {
+	Executor
+	// CanSuspend - Можно ли приостановить выполняющуюся задачу
+	CanSuspend(task pkg.InternalTask) bool
+}
+
+// WithExecutor - Исполнитель задач (по умолчанию - симуляция I/O операции на 3-5 минут)
+func WithExecutor(executor Executor) ServiceOption {
+	return func(s *Service) {
+		s.executor = executor
+	}
+}
+
+// simulationExecutor - исполнитель по умолчанию: симулирует длительную I/O операцию.
+// Приостановленная задача продолжает с места остановки
+type simulationExecutor struct {
+	clock    pkg.Clock
+	randIntn func(n int) int
+}
+
+// simulationProgress - контрольная точка симуляции: общая и уже отработанная длительность
+type simulationProgress struct {
+	Duration time.Duration `json:"duration"`
+	Done     time.Duration `json:"done"`
+}
+
+// Execute - Симулировать работу от 3 до 5 минут. При остановке отработанное время сохраняется
+// в контрольной точке, и следующий запуск ждет только оставшееся
+func (e *simulationExecutor) Execute(ctx context.Context, task pkg.InternalTask) (string, error) {
+	var progress simulationProgress
+	if err := json.Unmarshal(task.Checkpoint, &progress); err != nil || progress.Duration <= 0 {
+		progress = simulationProgress{Duration: time.Duration(e.randIntn(121)+180) * time.Second}
+	}
+	started := e.clock.Now()
+
+	select {
+	case <-e.clock.After(progress.Duration - progress.Done):
+		return fmt.Sprintf("Task completed successfully after %s", progress.Duration.Round(time.Second)), nil
+	case <-ctx.Done():
+		progress.Done += e.clock.Now().Sub(started)
+		if data, err := json.Marshal(progress); err == nil {
+			_ = SaveCheckpoint(ctx, data)
+		}
+		return "", ctx.Err()
+	}
+}
+
+// CanSuspend - Симуляцию можно приостановить в любой момент
+func (e *simulationExecutor) CanSuspend(task pkg.InternalTask) bool {
+	return true
+}
+
+// taskRun - выполняющийся запуск задачи
+type taskRun struct {
+	cancel context.CancelCauseFunc
+}
+
+// errRunFinished - запуск задачи уже закончен: сохранять его контрольные точки и результаты поздно
+var errRunFinished = errors.New("task run is finished")
+
+// runKey - ключ контекста Execute, по которому SaveCheckpoint и WriteResult находят запуск задачи
+type runKey struct{}
+
+// runContext - запуск задачи, в который исполнитель сохраняет контрольные точки и результаты
+type runContext struct {
+	service *Service
+	taskId  string
+	run     *taskRun
+}
+
+// withRun - Контекст Execute запуска run
+func (s *Service) withRun(ctx context.Context, taskId string, run *taskRun) context.Context {
+	return context.WithValue(ctx, runKey{}, &runContext{service: s, taskId: taskId, run: run})
+}
+
+// runFromContext - Запуск задачи, которому принадлежит контекст Execute
+func runFromContext(ctx context.Context) (*runContext, error) {
+	rc, ok := ctx.Value(runKey{}).(*runContext)
+	if !ok {
+		return nil, errors.New("context does not belong to a task run")
+	}
+	return rc, nil
+}
+
+// canSuspend - Можно ли приостановить выполняющуюся задачу исполнителем сервиса
+func (s *Service) canSuspend(task pkg.InternalTask) bool {
+	suspender, ok := s.executor.(Suspender)
+	return ok && suspender.CanSuspend(task)
+}
+
+// runTask - Запустить задачу исполнителем, как только это позволяют лимиты, и записать итог.
+// Отмена parent отменяет выполнение. Webhook отправляется после освобождения места запуска:
+// повторные попытки доставки не задерживают очередь и не учитываются как время выполнения
+func (s *Service) runTask(parent context.Context, taskId string) {
+	if task, finished := s.executeTask(parent, taskId); finished {
+		s.notifyCompletion(task)
+	}
+}
+
+// executeTask - Выполнить задачу и перевести ее в финальный статус. finished - задача
+// переведена этим запуском; место запуска освобождается до возврата
+func (s *Service) executeTask(parent context.Context, taskId string) (task pkg.InternalTask, finished bool) {
+	ctx, cancel := context.WithCancelCause(parent)
+	defer cancel(nil)
+
+	// Переводим задачу в running; ошибка - задачу удалили, приостановили или ее уже запустил
+	// другой исполнитель
+	task, run, err := s.startTask(ctx, taskId, cancel)
+	if err != nil {
+		return
+	}
+	defer s.finishRun(task, run)
+
+	result, execErr := s.executor.Execute(s.withRun(ctx, taskId, run), task)
+	if errors.Is(context.Cause(ctx), pkg.ErrTaskPaused) {
+		// PauseTask уже перевел задачу в paused
+		return
+	}
+
+	var finishedTask pkg.InternalTask
+	switch {
+	case execErr == nil:
+		finishedTask, err = s.store.Transition(taskId, pkg.TaskStatusCompleted, "finished", func(task *pkg.InternalTask, at time.Time) {
+			task.FinishedAt = at
+			task.Result = result
+			task.Checkpoint = nil
+			task.CheckpointAt = time.Time{}
+		})
+
+	case ctx.Err() != nil:
+		// Задача была отменена
+		finishedTask, err = s.store.Transition(taskId, pkg.TaskStatusCancelled, ctx.Err().Error(), func(task *pkg.InternalTask, at time.Time) {
+			task.FinishedAt = at
+			task.Error = pkg.ErrTaskCancelled.Error()
+		})
+
+	default:
+		finishedTask, err = s.store.Transition(taskId, pkg.TaskStatusFailed, "failed", func(task *pkg.InternalTask, at time.Time) {
+			task.FinishedAt = at
+			task.Error = execErr.Error()
+		})
+	}
+	// Ошибка - задачу удалили или уже перевели в финальный статус
+	return finishedTask, err == nil
+}
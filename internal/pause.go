@@ -0,0 +1,156 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"workmate/pkg"
+)
+
+// DispatchState - состояние запуска задач
+type DispatchState struct {
+	// Paused - новые запуски остановлены с момента PausedAt (выполняющиеся задачи продолжаются)
+	Paused   bool
+	PausedAt time.Time
+	// Число задач по статусам на момент запроса
+	PendingTasks int
+	RunningTasks int
+	PausedTasks  int
+}
+
+// PauseTask - Приостановить задачу. Ожидающая задача не запускается до ResumeTask; выполняющаяся
+// останавливается, если исполнитель умеет ее приостановить (Suspender), иначе - pkg.ErrNotSuspendable.
+// Исполнитель получает отмену контекста с причиной pkg.ErrTaskPaused
+func (s *Service) PauseTask(ctx context.Context, taskId string) (task pkg.InternalTask, err error) {
+	if _, err = s.getOwnTask(ctx, taskId, true); err != nil {
+		return
+	}
+
+	// Под dispatch задачу не запустят между проверкой и переходом
+	s.dispatch.Lock()
+	defer s.dispatch.Unlock()
+
+	current, err := s.store.GetTask(taskId)
+	if err != nil {
+		return
+	}
+	if current.Status == pkg.TaskStatusRunning && !s.canSuspend(current) {
+		err = fmt.Errorf("%w: task %s", pkg.ErrNotSuspendable, taskId)
+		return
+	}
+	task, err = s.store.Transition(taskId, pkg.TaskStatusPaused, "paused", func(task *pkg.InternalTask, at time.Time) {
+		task.PausedAt = at
+	})
+	if err != nil {
+		return
+	}
+	if run := s.runs[taskId]; run != nil {
+		run.cancel(pkg.ErrTaskPaused)
+	}
+	return
+}
+
+// ResumeTask - Возобновить приостановленную задачу: она возвращается в pending и запускается
+// заново, как только позволят лимиты и закончится приостановленный запуск
+func (s *Service) ResumeTask(ctx context.Context, taskId string) (task pkg.InternalTask, err error) {
+	if _, err = s.getOwnTask(ctx, taskId, true); err != nil {
+		return
+	}
+
+	task, err = s.store.Transition(taskId, pkg.TaskStatusPending, "resumed", func(task *pkg.InternalTask, at time.Time) {
+		task.StartedAt = time.Time{}
+		task.PausedAt = time.Time{}
+	})
+	if err != nil {
+		return
+	}
+	go s.runTask(context.Background(), taskId)
+	return
+}
+
+// CancelTask - Отменить задачу: ожидающая, выполняющаяся или приостановленная задача переходит
+// в cancelled, исполнитель получает отмену контекста с причиной pkg.ErrTaskCancelled.
+// Завершенную задачу отменить нельзя - pkg.ErrIllegalTransition
+func (s *Service) CancelTask(ctx context.Context, taskId string) (task pkg.InternalTask, err error) {
+	if _, err = s.getOwnTask(ctx, taskId, true); err != nil {
+		return
+	}
+
+	// Под dispatch задачу не запустят между переходом и отменой запуска
+	s.dispatch.Lock()
+	task, err = s.store.Transition(taskId, pkg.TaskStatusCancelled, "cancelled", func(task *pkg.InternalTask, at time.Time) {
+		task.FinishedAt = at
+		task.PausedAt = time.Time{}
+		task.Error = pkg.ErrTaskCancelled.Error()
+	})
+	if err == nil {
+		if run := s.runs[taskId]; run != nil {
+			run.cancel(pkg.ErrTaskCancelled)
+		}
+	}
+	s.dispatch.Unlock()
+	if err != nil {
+		return
+	}
+	// Запуск уже не переведет задачу в финальный статус: уведомление отправляется здесь,
+	// в фоне - доставка с повторами может быть долгой
+	go s.notifyCompletion(task)
+	return
+}
+
+// requireAdmin - Проверить, что вызывающий может управлять очередью (без аутентификации - может)
+func requireAdmin(ctx context.Context, operation string) error {
+	if principal, ok := pkg.PrincipalFromContext(ctx); ok && !principal.IsAdmin() {
+		return fmt.Errorf("%w: %s requires admin role", pkg.ErrForbidden, operation)
+	}
+	return nil
+}
+
+// PauseDispatch - Остановить запуск задач: ожидающие задачи остаются в pending до ResumeDispatch,
+// выполняющиеся продолжаются. Повторный вызов не меняет момент остановки
+func (s *Service) PauseDispatch(ctx context.Context) (DispatchState, error) {
+	if err := requireAdmin(ctx, "pausing dispatch"); err != nil {
+		return DispatchState{}, err
+	}
+
+	s.dispatch.Lock()
+	if !s.dispatchPaused {
+		s.dispatchPaused = true
+		s.dispatchPausedAt = s.clock.Now()
+	}
+	s.dispatch.Unlock()
+	return s.DispatchState(ctx)
+}
+
+// ResumeDispatch - Возобновить запуск задач и разбудить ожидающие задачи
+func (s *Service) ResumeDispatch(ctx context.Context) (DispatchState, error) {
+	if err := requireAdmin(ctx, "resuming dispatch"); err != nil {
+		return DispatchState{}, err
+	}
+
+	s.dispatch.Lock()
+	if s.dispatchPaused {
+		s.dispatchPaused = false
+		s.dispatchPausedAt = time.Time{}
+		close(s.released)
+		s.released = make(chan struct{})
+	}
+	s.dispatch.Unlock()
+	return s.DispatchState(ctx)
+}
+
+// DispatchState - Состояние запуска задач и число задач по статусам во всех пространствах имен
+func (s *Service) DispatchState(ctx context.Context) (DispatchState, error) {
+	if err := requireAdmin(ctx, "dispatch state"); err != nil {
+		return DispatchState{}, err
+	}
+
+	s.dispatch.Lock()
+	state := DispatchState{Paused: s.dispatchPaused, PausedAt: s.dispatchPausedAt}
+	s.dispatch.Unlock()
+
+	state.PendingTasks = s.store.CountTasks(pkg.TaskStatusPending)
+	state.RunningTasks = s.store.CountTasks(pkg.TaskStatusRunning)
+	state.PausedTasks = s.store.CountTasks(pkg.TaskStatusPaused)
+	return state, nil
+}
@@ -0,0 +1,149 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+	"workmate/pkg"
+)
+
+// blockingExecutor - исполнитель, который выполняет задачи до отмены и не умеет их приостанавливать
+type blockingExecutor struct{}
+
+func (e *blockingExecutor) Execute(ctx context.Context, task pkg.InternalTask) (string, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+// suspendableExecutor - blockingExecutor, который умеет приостанавливать задачи
+type suspendableExecutor struct {
+	blockingExecutor
+}
+
+func (e *suspendableExecutor) CanSuspend(task pkg.InternalTask) bool {
+	return true
+}
+
+func TestPauseTask(t *testing.T) {
+	clock := pkg.NewFakeClock(time.Date(2024, 1, 15, 15, 4, 5, 0, time.UTC))
+	service := NewService(WithClock(clock), WithExecutor(&blockingExecutor{}))
+	ctx := context.Background()
+
+	// Выполняющуюся задачу нельзя приостановить, если исполнитель этого не умеет
+	running, _ := service.CreateTask(ctx, "Running Task")
+	waitForStatus(t, service, running.Id, pkg.TaskStatusRunning)
+	if _, err := service.PauseTask(ctx, running.Id); !errors.Is(err, pkg.ErrNotSuspendable) {
+		t.Errorf("Expected ErrNotSuspendable, got %v", err)
+	}
+
+	// При остановленном запуске новые задачи ждут в pending
+	state, err := service.PauseDispatch(ctx)
+	if err != nil || !state.Paused || !state.PausedAt.Equal(clock.Now()) || state.RunningTasks != 1 {
+		t.Fatalf("PauseDispatch() = %+v, %v", state, err)
+	}
+	queued, _ := service.CreateTask(ctx, "Queued Task")
+	paused, err := service.PauseTask(ctx, queued.Id)
+	if err != nil || paused.Status != pkg.TaskStatusPaused || !paused.PausedAt.Equal(clock.Now()) {
+		t.Fatalf("PauseTask() = %+v, %v", paused, err)
+	}
+	if _, err := service.PauseTask(ctx, queued.Id); !errors.Is(err, pkg.ErrIllegalTransition) {
+		t.Errorf("Expected ErrIllegalTransition for paused task, got %v", err)
+	}
+
+	resumed, err := service.ResumeTask(ctx, queued.Id)
+	if err != nil || resumed.Status != pkg.TaskStatusPending || !resumed.PausedAt.IsZero() {
+		t.Fatalf("ResumeTask() = %+v, %v", resumed, err)
+	}
+	if _, err := service.ResumeTask(ctx, queued.Id); !errors.Is(err, pkg.ErrIllegalTransition) {
+		t.Errorf("Expected ErrIllegalTransition for pending task, got %v", err)
+	}
+	if task, _ := service.GetTask(ctx, queued.Id); task.Status != pkg.TaskStatusPending {
+		t.Errorf("Expected task to wait while dispatch is paused, got '%s'", task.Status)
+	}
+
+	if state, err := service.ResumeDispatch(ctx); err != nil || state.Paused {
+		t.Fatalf("ResumeDispatch() = %+v, %v", state, err)
+	}
+	waitForStatus(t, service, queued.Id, pkg.TaskStatusRunning)
+
+	submitter := pkg.WithPrincipal(ctx, pkg.Principal{Name: "alice", Roles: []string{pkg.RoleSubmitter}})
+	if _, err := service.PauseDispatch(submitter); !errors.Is(err, pkg.ErrForbidden) {
+		t.Errorf("Expected ErrForbidden for submitter, got %v", err)
+	}
+	if _, err := service.PauseTask(submitter, queued.Id); !errors.Is(err, pkg.ErrTaskNotFound) {
+		t.Errorf("Expected ErrTaskNotFound for task of another owner, got %v", err)
+	}
+}
+
+func TestPauseRunningTask(t *testing.T) {
+	service := NewService(WithExecutor(&suspendableExecutor{}))
+	ctx := context.Background()
+
+	task, _ := service.CreateTask(ctx, "Suspendable Task")
+	waitForStatus(t, service, task.Id, pkg.TaskStatusRunning)
+
+	paused, err := service.PauseTask(ctx, task.Id)
+	if err != nil || paused.Status != pkg.TaskStatusPaused {
+		t.Fatalf("PauseTask() = %+v, %v", paused, err)
+	}
+	if _, err := service.ResumeTask(ctx, task.Id); err != nil {
+		t.Fatalf("ResumeTask() returned error: %v", err)
+	}
+	waitForStatus(t, service, task.Id, pkg.TaskStatusRunning)
+
+	history, _ := service.GetTaskHistory(ctx, task.Id)
+	var statuses []string
+	for _, transition := range history {
+		statuses = append(statuses, transition.From+">"+transition.To)
+	}
+	if fmt.Sprint(statuses) != "[>pending pending>running running>paused paused>pending pending>running]" {
+		t.Errorf("Unexpected history %v", statuses)
+	}
+}
+
+func TestCancelTask(t *testing.T) {
+	service := NewService(WithExecutor(&blockingExecutor{}))
+	ctx := context.Background()
+	alice := pkg.WithPrincipal(ctx, pkg.Principal{Name: "alice", Roles: []string{pkg.RoleSubmitter}})
+	bob := pkg.WithPrincipal(ctx, pkg.Principal{Name: "bob", Roles: []string{pkg.RoleSubmitter}})
+	operator := pkg.WithPrincipal(ctx, pkg.Principal{Name: "ops", Roles: []string{pkg.RoleOperator}})
+
+	// Выполняющаяся задача отменяется вместе с запуском исполнителя
+	running, _ := service.CreateTask(alice, "Running Task")
+	waitForStatus(t, service, running.Id, pkg.TaskStatusRunning)
+	if _, err := service.CancelTask(bob, running.Id); !errors.Is(err, pkg.ErrTaskNotFound) {
+		t.Errorf("Expected ErrTaskNotFound for task of another owner, got %v", err)
+	}
+	cancelled, err := service.CancelTask(alice, running.Id)
+	if err != nil || cancelled.Status != pkg.TaskStatusCancelled || cancelled.FinishedAt.IsZero() {
+		t.Fatalf("CancelTask() = %+v, %v", cancelled, err)
+	}
+	service.dispatch.Lock()
+	run := service.runs[running.Id]
+	service.dispatch.Unlock()
+	for i := 0; i < 1000 && run != nil; i++ {
+		time.Sleep(time.Millisecond)
+		service.dispatch.Lock()
+		run = service.runs[running.Id]
+		service.dispatch.Unlock()
+	}
+	if run != nil {
+		t.Error("Expected executor run to be cancelled")
+	}
+	if task, _ := service.GetTask(ctx, running.Id); task.Status != pkg.TaskStatusCancelled || task.Error != pkg.ErrTaskCancelled.Error() {
+		t.Errorf("Expected cancelled task, got '%s' (%s)", task.Status, task.Error)
+	}
+	if _, err := service.CancelTask(alice, running.Id); !errors.Is(err, pkg.ErrIllegalTransition) {
+		t.Errorf("Expected ErrIllegalTransition for cancelled task, got %v", err)
+	}
+
+	// Оператор отменяет любые задачи, в том числе приостановленные
+	service.PauseDispatch(ctx)
+	queued, _ := service.CreateTask(alice, "Queued Task")
+	service.PauseTask(alice, queued.Id)
+	if cancelled, err := service.CancelTask(operator, queued.Id); err != nil || cancelled.Status != pkg.TaskStatusCancelled || !cancelled.PausedAt.IsZero() {
+		t.Errorf("CancelTask() of paused task = %+v, %v", cancelled, err)
+	}
+}
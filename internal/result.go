@@ -0,0 +1,122 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"os"
+	"path/filepath"
+	"workmate/pkg"
+)
+
+// defaultResultContentType - тип выходных данных, если исполнитель его не указал
+const defaultResultContentType = "application/octet-stream"
+
+// WithResultStore - Хранилище выходных данных задач (по умолчанию - каталог workmate-results
+// во временном каталоге системы)
+func WithResultStore(store pkg.ResultStore) ServiceOption {
+	return func(s *Service) {
+		s.results = store
+	}
+}
+
+// defaultResultStore - Хранилище результатов по умолчанию
+func defaultResultStore() pkg.ResultStore {
+	return pkg.NewFileResultStore(filepath.Join(os.TempDir(), "workmate-results"))
+}
+
+// WriteResult - Сохранить выходные данные выполняющейся задачи в хранилище результатов:
+// для больших и двоичных результатов, которые не помещаются в строку результата Execute.
+// Метаданные (тип, размер, контрольная сумма) попадают в InternalTask.Output, содержимое
+// отдается по /tasks/{taskId}/result/content. Повторная запись заменяет результат.
+// ctx - контекст Execute; записывать можно до возврата из Execute
+func WriteResult(ctx context.Context, contentType string, r io.Reader) (pkg.ResultInfo, error) {
+	rc, err := runFromContext(ctx)
+	if err != nil {
+		return pkg.ResultInfo{}, err
+	}
+	return rc.service.writeResult(rc.taskId, rc.run, contentType, r)
+}
+
+// writeResult - Записать выходные данные запуска run и их метаданные в задачу
+func (s *Service) writeResult(taskId string, run *taskRun, contentType string, r io.Reader) (info pkg.ResultInfo, err error) {
+	if contentType == "" {
+		contentType = defaultResultContentType
+	}
+	if _, _, err = mime.ParseMediaType(contentType); err != nil {
+		err = fmt.Errorf("%w: content type %q: %v", pkg.ErrInvalidParameters, contentType, err)
+		return
+	}
+	if !s.runActive(taskId, run) {
+		err = fmt.Errorf("%w: task %s", errRunFinished, taskId)
+		return
+	}
+
+	// Содержимое пишется без блокировки: запись может быть долгой
+	if info, err = s.results.Put(taskId, contentType, r); err != nil {
+		return
+	}
+	info.CreatedAt = s.clock.Now()
+
+	s.dispatch.Lock()
+	defer s.dispatch.Unlock()
+
+	if !s.isCurrentRun(taskId, run) {
+		// Запуск закончился во время записи: Put уже заменил содержимое, поэтому прежние
+		// метаданные к нему не относятся
+		s.deleteResults(taskId)
+		_, _ = s.store.ModifyTask(taskId, func(task *pkg.InternalTask) {
+			task.Output = nil
+		})
+		err = fmt.Errorf("%w: task %s", errRunFinished, taskId)
+		return
+	}
+	output := info
+	if _, err = s.store.ModifyTask(taskId, func(task *pkg.InternalTask) {
+		task.Output = &output
+	}); err != nil {
+		// Задачу удалили во время записи: содержимое больше некому отдать
+		s.deleteResults(taskId)
+	}
+	return
+}
+
+// runActive - Не закончен ли запуск run задачи
+func (s *Service) runActive(taskId string, run *taskRun) bool {
+	s.dispatch.Lock()
+	defer s.dispatch.Unlock()
+	return s.isCurrentRun(taskId, run)
+}
+
+// isCurrentRun - Выполняется ли сейчас запуск run задачи (вызывается под dispatch)
+func (s *Service) isCurrentRun(taskId string, run *taskRun) bool {
+	return run != nil && s.runs[taskId] == run
+}
+
+// OpenResult - Открыть выходные данные завершенной задачи: задача (метаданные - в Output)
+// и содержимое, которое нужно закрыть. Незавершенная задача - pkg.ErrTaskNotCompleted,
+// задача без выходных данных в хранилище - pkg.ErrResultNotFound
+func (s *Service) OpenResult(ctx context.Context, taskId string) (task pkg.InternalTask, content io.ReadSeekCloser, err error) {
+	task, err = s.GetTaskResult(ctx, taskId)
+	if err != nil {
+		return
+	}
+	if task.Output == nil {
+		err = fmt.Errorf("%w: task %s", pkg.ErrResultNotFound, taskId)
+		return
+	}
+	content, err = s.results.Open(taskId)
+	return
+}
+
+// deleteResults - Удалить выходные данные удаленных задач (ошибки только в лог:
+// задачи уже удалены)
+func (s *Service) deleteResults(taskIds ...string) {
+	for _, taskId := range taskIds {
+		if err := s.results.Delete(taskId); err != nil {
+			log.Printf("results: delete result of task %s: %v", taskId, err)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"workmate/pkg"
+)
+
+// resultExecutor - исполнитель, записывающий выходные данные и передающий ошибку записи в written
+type resultExecutor struct {
+	contentType string
+	written     chan error
+}
+
+func (e *resultExecutor) Execute(ctx context.Context, task pkg.InternalTask) (string, error) {
+	_, err := WriteResult(ctx, e.contentType, strings.NewReader("report"))
+	e.written <- err
+	return "done", err
+}
+
+func TestWriteResult(t *testing.T) {
+	store := pkg.NewFileResultStore(t.TempDir())
+	executor := &resultExecutor{contentType: "text/plain; charset=utf-8", written: make(chan error, 1)}
+	service := NewService(WithExecutor(executor), WithResultStore(store))
+	ctx := context.Background()
+
+	if _, err := WriteResult(ctx, "text/plain", strings.NewReader("x")); err == nil {
+		t.Error("WriteResult() outside of a task run should return error")
+	}
+
+	task, _ := service.CreateTask(ctx, "Report")
+	if err := <-executor.written; err != nil {
+		t.Fatalf("WriteResult() returned error: %v", err)
+	}
+	completed := waitForStatus(t, service, task.Id, pkg.TaskStatusCompleted)
+	if completed.Output == nil || completed.Output.ContentType != "text/plain; charset=utf-8" || completed.Output.Size != 6 ||
+		completed.Output.CreatedAt.IsZero() {
+		t.Fatalf("Unexpected task output %+v", completed.Output)
+	}
+
+	_, content, err := service.OpenResult(ctx, task.Id)
+	if err != nil {
+		t.Fatalf("OpenResult() returned error: %v", err)
+	}
+	data, _ := io.ReadAll(content)
+	content.Close()
+	if string(data) != "report" {
+		t.Errorf("Expected content 'report', got %q", data)
+	}
+
+	// Запуск закончен: запись больше не принимается
+	if _, err := service.writeResult(task.Id, nil, "text/plain", strings.NewReader("late")); !errors.Is(err, errRunFinished) {
+		t.Errorf("Expected errRunFinished after completion, got %v", err)
+	}
+
+	// Некорректный тип содержимого отклоняется до записи
+	executor.contentType = "not a type"
+	failed, _ := service.CreateTask(ctx, "Broken Report")
+	if err := <-executor.written; !errors.Is(err, pkg.ErrInvalidParameters) {
+		t.Errorf("Expected ErrInvalidParameters for invalid content type, got %v", err)
+	}
+	waitForStatus(t, service, failed.Id, pkg.TaskStatusFailed)
+	if _, _, err := service.OpenResult(ctx, failed.Id); !errors.Is(err, pkg.ErrResultNotFound) {
+		t.Errorf("Expected ErrResultNotFound for task without output, got %v", err)
+	}
+
+	if err := service.DeleteTask(ctx, task.Id); err != nil {
+		t.Fatalf("DeleteTask() returned error: %v", err)
+	}
+	if _, err := store.Open(task.Id); !errors.Is(err, pkg.ErrResultNotFound) {
+		t.Errorf("Expected result content to be deleted with the task, got %v", err)
+	}
+}
+
+// lateResultExecutor - исполнитель, записывающий выходные данные только после сигнала release
+type lateResultExecutor struct {
+	started chan string
+	release chan struct{}
+	written chan error
+}
+
+func (e *lateResultExecutor) Execute(ctx context.Context, task pkg.InternalTask) (string, error) {
+	e.started <- task.Id
+	<-e.release
+	_, err := WriteResult(ctx, "text/plain", strings.NewReader("late"))
+	e.written <- err
+	return "done", err
+}
+
+func TestWriteResultOfRemovedTask(t *testing.T) {
+	store := pkg.NewFileResultStore(t.TempDir())
+	executor := &lateResultExecutor{started: make(chan string, 1), release: make(chan struct{}), written: make(chan error, 1)}
+	service := NewService(WithExecutor(executor), WithResultStore(store))
+	ctx := context.Background()
+
+	// Задача удалена во время запуска: записанное содержимое не остается в хранилище
+	task, _ := service.CreateTask(ctx, "Report")
+	<-executor.started
+	if err := service.DeleteTask(ctx, task.Id); err != nil {
+		t.Fatalf("DeleteTask() returned error: %v", err)
+	}
+	executor.release <- struct{}{}
+	if err := <-executor.written; err == nil {
+		t.Error("Expected WriteResult() to fail for a deleted task")
+	}
+	if _, err := store.Open(task.Id); !errors.Is(err, pkg.ErrResultNotFound) {
+		t.Errorf("Expected result content of deleted task to be removed, got %v", err)
+	}
+
+	// Очистка пространства имен удаляет выходные данные удаленных задач
+	if _, err := store.Put(task.Id, "text/plain", strings.NewReader("orphan")); err != nil {
+		t.Fatalf("Put() returned error: %v", err)
+	}
+	record := pkg.InternalTask{Id: task.Id, Name: "Report", Status: pkg.TaskStatusCompleted, CreatedAt: service.Now(),
+		StartedAt: service.Now(), FinishedAt: service.Now(), Result: "done"}
+	if _, err := service.ImportTasks(ctx, []pkg.InternalTask{record}, ImportOptions{}); err != nil {
+		t.Fatalf("ImportTasks() returned error: %v", err)
+	}
+	if count, err := service.PurgeNamespace(ctx, pkg.DefaultNamespace); err != nil || count != 1 {
+		t.Fatalf("PurgeNamespace() = %d, %v", count, err)
+	}
+	if _, err := store.Open(task.Id); !errors.Is(err, pkg.ErrResultNotFound) {
+		t.Errorf("Expected result content of purged task to be removed, got %v", err)
+	}
+
+	// Выходные данные не импортируются: оставшиеся от прежней задачи удаляются
+	record.Output = &pkg.ResultInfo{ContentType: "text/plain", Size: 5}
+	for _, conflict := range []string{pkg.ImportConflictFail, pkg.ImportConflictOverwrite} {
+		store.Put(task.Id, "text/plain", strings.NewReader("stale"))
+		if _, err := service.ImportTasks(ctx, []pkg.InternalTask{record}, ImportOptions{Conflict: conflict}); err != nil {
+			t.Fatalf("ImportTasks(%s) returned error: %v", conflict, err)
+		}
+		if _, err := store.Open(task.Id); !errors.Is(err, pkg.ErrResultNotFound) {
+			t.Errorf("Expected stale result content to be removed on import (%s), got %v", conflict, err)
+		}
+	}
+	if imported, _ := service.GetTask(ctx, task.Id); imported.Output != nil {
+		t.Errorf("Expected imported task without output, got %+v", imported.Output)
+	}
+}
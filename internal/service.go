@@ -2,126 +2,459 @@ package internal
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
+	"sort"
+	"sync"
 	"time"
 	"workmate/pkg"
 )
 
 type Service struct {
 	store *pkg.TaskStore
+
+	// clock и random - источники времени и случайности, подменяемые в тестах
+	clock  pkg.Clock
+	randMu sync.Mutex
+	random *rand.Rand
+
+	// maxActiveTasks - лимит задач в статусах pending+running (0 - без лимита)
+	maxActiveTasks int
+	admission      sync.Mutex
+
+	// Лимиты пространств имен: свои для перечисленных, defaultQuota - для остальных
+	defaultQuota pkg.Quota
+	quotas       map[string]pkg.Quota
+
+	// Лимиты принципалов: свои для перечисленных, defaultTenantQuota - для остальных
+	defaultTenantQuota pkg.TenantQuota
+	tenantQuotas       map[string]pkg.TenantQuota
+	usage              *usageLedger
+
+	// dispatch - проверка лимитов и перевод задачи в running атомарны;
+	// released закрывается, когда завершается запуск, и заменяется новым
+	dispatch sync.Mutex
+	released chan struct{}
+
+	// executor выполняет задачи; runs - отмена выполняющихся запусков по id задачи (под dispatch)
+	executor Executor
+	runs     map[string]*taskRun
+
+	// results - хранилище выходных данных задач (WriteResult)
+	results pkg.ResultStore
+
+	// dispatchPaused - новые запуски остановлены (PauseDispatch) с момента dispatchPausedAt
+	dispatchPaused   bool
+	dispatchPausedAt time.Time
+
+	webhooks *webhookSender
 }
 
-func NewService() *Service {
-	return &Service{
-		store: pkg.NewTaskStore(),
+// ServiceOption - параметр настройки сервиса
+type ServiceOption func(*Service)
+
+// WithMaxActiveTasks - Ограничить общее число задач в статусах pending+running
+func WithMaxActiveTasks(limit int) ServiceOption {
+	return func(s *Service) {
+		s.maxActiveTasks = limit
 	}
 }
 
-// simulateIOTask симулирует длительную I/O операцию
-func (s *Service) simulateIOTask(ctx context.Context, taskId string) {
-	// Получаем задачу из хранилища
-	task, err := s.store.GetTask(taskId)
-	if err != nil {
-		return
+// WithNamespaceQuotas - Лимиты задач пространств имен: quotas - для перечисленных пространств,
+// defaultQuota - для всех остальных
+func WithNamespaceQuotas(defaultQuota pkg.Quota, quotas map[string]pkg.Quota) ServiceOption {
+	return func(s *Service) {
+		s.defaultQuota = defaultQuota
+		s.quotas = quotas
+	}
+}
+
+// WithClock - Источник времени для CreatedAt, продолжительности, таймаутов и планирования
+func WithClock(clock pkg.Clock) ServiceOption {
+	return func(s *Service) {
+		s.clock = clock
 	}
+}
+
+// WithRandSeed - Детерминированная последовательность случайных чисел (длительность симуляции)
+func WithRandSeed(seed int64) ServiceOption {
+	return func(s *Service) {
+		s.random = rand.New(rand.NewSource(seed))
+	}
+}
+
+func NewService(opts ...ServiceOption) *Service {
+	s := &Service{
+		clock:    pkg.RealClock,
+		random:   rand.New(rand.NewSource(time.Now().UnixNano())),
+		webhooks: newWebhookSender(),
+		usage:    newUsageLedger(),
+		released: make(chan struct{}),
+		runs:     map[string]*taskRun{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.executor == nil {
+		s.executor = &simulationExecutor{clock: s.clock, randIntn: s.randIntn}
+	}
+	if s.results == nil {
+		s.results = defaultResultStore()
+	}
+	s.store = pkg.NewTaskStore(pkg.WithClock(s.clock))
+	return s
+}
+
+// randIntn - Случайное число в [0, n); rand.Rand не потокобезопасен
+func (s *Service) randIntn(n int) int {
+	s.randMu.Lock()
+	defer s.randMu.Unlock()
+	return s.random.Intn(n)
+}
+
+// Now - Текущее время по часам сервиса: момент, на который считается продолжительность
+// выполняющихся задач (pkg.InternalTask.Elapsed)
+func (s *Service) Now() time.Time {
+	return s.clock.Now()
+}
 
-	// Обновляем статус на running
-	now := time.Now()
-	task.StartedAt = now
-	task.Status = pkg.TaskStatusRunning
+// canAccess - Проверить, что вызывающий может видеть (modify == false) или изменять задачу.
+// Задачи других пространств имен недоступны никому; без аутентификации доступны все задачи
+// пространства имен запроса
+func canAccess(ctx context.Context, task pkg.InternalTask, modify bool) bool {
+	if task.Namespace != pkg.NamespaceFromContext(ctx) {
+		return false
+	}
+	principal, ok := pkg.PrincipalFromContext(ctx)
+	if !ok || task.Owner == principal.Name {
+		return true
+	}
+	if modify {
+		return principal.CanModifyAll()
+	}
+	return principal.CanReadAll()
+}
 
-	// Сохраняем изменения
-	if err := s.store.UpdateTask(task); err != nil {
+// getOwnTask - Получить задачу, если она доступна вызывающему
+func (s *Service) getOwnTask(ctx context.Context, taskId string, modify bool) (task pkg.InternalTask, err error) {
+	task, err = s.store.GetTask(taskId)
+	if err != nil {
 		return
 	}
+	// Чужие задачи не раскрываем: для вызывающего их не существует
+	if !canAccess(ctx, task, modify) {
+		err = pkg.ErrTaskNotFound
+		return pkg.InternalTask{}, err
+	}
+	return
+}
 
-	// Симулируем работу от 3 до 5 минут
-	duration := time.Duration(rand.Intn(121)+180) * time.Second
+// GetTasks - Получить список задач пространства имен запроса по фильтру (по времени создания).
+// Выборка идет по индексам хранилища: без прав на чужие задачи - только по своему владельцу
+func (s *Service) GetTasks(ctx context.Context, filter pkg.TaskFilter) ([]pkg.InternalTask, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	filter.Namespace = pkg.NamespaceFromContext(ctx)
 
-	select {
-	case <-time.After(duration):
-		// Задача успешно завершена
-		finishedAt := time.Now()
-		task.FinishedAt = finishedAt
-		task.Status = pkg.TaskStatusCompleted
-		task.Result = fmt.Sprintf("Task completed successfully after %s", duration.Round(time.Second))
-		task.Duration = finishedAt.Sub(task.StartedAt).Round(time.Second).String()
-		s.store.UpdateTask(task)
+	if principal, ok := pkg.PrincipalFromContext(ctx); ok && !principal.CanReadAll() {
+		filter.Owner = principal.Name
+	}
+	return s.store.ListTasks(filter), nil
+}
 
-	case <-ctx.Done():
-		// Задача была отменена
-		finishedAt := time.Now()
-		task.FinishedAt = finishedAt
-		task.Status = pkg.TaskStatusFailed
-		task.Error = "Task was cancelled"
-		task.Duration = finishedAt.Sub(task.StartedAt).Round(time.Second).String()
-		s.store.UpdateTask(task)
+// ExportTasks - Потоково обойти задачи пространства имен запроса по фильтру в порядке добавления, не собирая список в память:
+// fn вызывается для каждой доступной вызывающему задачи, ошибка fn или отмена ctx прерывает обход
+func (s *Service) ExportTasks(ctx context.Context, filter pkg.TaskFilter, fn func(task pkg.InternalTask) error) error {
+	if err := filter.Validate(); err != nil {
+		return err
 	}
+
+	var err error
+	count := 0
+	s.store.Scan(func(task pkg.InternalTask) bool {
+		if err = ctx.Err(); err != nil {
+			return false
+		}
+		if !canAccess(ctx, task, false) || !filter.Matches(task) {
+			return true
+		}
+		if err = fn(task); err != nil {
+			return false
+		}
+		count++
+		return filter.Limit <= 0 || count < filter.Limit
+	})
+	return err
+}
+
+// ImportOptions - параметры импорта задач
+type ImportOptions struct {
+	// Conflict - политика для задач с уже существующим id (pkg.ImportConflict*, по умолчанию fail)
+	Conflict string
+	// Requeue - заново поставить в очередь импортированные незавершенные задачи
+	Requeue bool
 }
 
-// GetTasks - Получить список всех задач с обновленной продолжительностью
-func (s *Service) GetTasks(ctx context.Context) ([]pkg.InternalTask, error) {
-	tasks := s.store.GetTasks()
+// ImportResult - итог импорта одной задачи
+type ImportResult struct {
+	Id       string
+	Action   string
+	Requeued bool
+}
+
+// ImportTasks - Загрузить задачи из выгрузки (перенос между экземплярами, восстановление из копии).
+// Задачи загружаются в пространство имен запроса: записи без пространства имен попадают в него,
+// записи другого пространства отклоняются.
+// Сначала проверяются все записи: при ошибках возвращается *pkg.RecordErrors по индексам записей
+// и ничего не загружается; конфликты id при политике fail возвращаются так же.
+// Без Requeue незавершенные задачи загружаются как есть и не выполняются; с Requeue они
+// сбрасываются в pending и запускаются заново. Лимит активных задач при импорте не применяется.
+// Выходные данные (Output) не импортируются, у замененных задач они удаляются.
+// Импорт доступен только администратору: записи задают владельца и заменяют чужие задачи
+func (s *Service) ImportTasks(ctx context.Context, tasks []pkg.InternalTask, opts ImportOptions) ([]ImportResult, error) {
+	if err := requireAdmin(ctx, "importing tasks"); err != nil {
+		return nil, err
+	}
+	if opts.Conflict == "" {
+		opts.Conflict = pkg.ImportConflictFail
+	}
+
+	namespace := pkg.NamespaceFromContext(ctx)
+	invalid := map[int]error{}
+	seen := make(map[string]bool, len(tasks))
+	for i, task := range tasks {
+		if err := task.ValidateRecord(); err != nil {
+			invalid[i] = err
+			continue
+		}
+		if task.Namespace != "" && task.Namespace != namespace {
+			invalid[i] = fmt.Errorf("%w: task %s belongs to namespace %q, not %q", pkg.ErrInvalidTaskRecord, task.Id, task.Namespace, namespace)
+			continue
+		}
+		if seen[task.Id] {
+			invalid[i] = fmt.Errorf("%w: duplicate id %s", pkg.ErrInvalidTaskRecord, task.Id)
+			continue
+		}
+		seen[task.Id] = true
+	}
+	if len(invalid) > 0 {
+		return nil, &pkg.RecordErrors{Err: pkg.ErrInvalidTaskRecord, Records: invalid}
+	}
 
-	// Обновляем duration для запущенных задач
+	tasks = append([]pkg.InternalTask(nil), tasks...)
 	for i := range tasks {
-		if tasks[i].Status == pkg.TaskStatusRunning && !tasks[i].StartedAt.IsZero() {
-			duration := time.Since(tasks[i].StartedAt)
-			tasks[i].Duration = duration.Round(time.Second).String()
-			// Обновляем в хранилище для консистентности
-			s.store.UpdateTask(tasks[i])
+		tasks[i].Namespace = namespace
+		tasks[i].Output = nil
+	}
+	requeue := make([]bool, len(tasks))
+	if opts.Requeue {
+		for i := range tasks {
+			if !pkg.IsTerminalStatus(tasks[i].Status) && tasks[i].Status != pkg.TaskStatusPaused {
+				requeue[i] = true
+				tasks[i].Status = pkg.TaskStatusPending
+				tasks[i].StartedAt = time.Time{}
+			}
 		}
 	}
 
-	return tasks, nil
+	actions, err := s.store.ImportTasks(tasks, opts.Conflict)
+	if errors.Is(err, pkg.ErrTaskExists) {
+		conflicts := map[int]error{}
+		for i, action := range actions {
+			if action == pkg.ImportActionConflict {
+				conflicts[i] = fmt.Errorf("%w: %s", pkg.ErrTaskExists, tasks[i].Id)
+			}
+		}
+		return nil, &pkg.RecordErrors{Err: pkg.ErrTaskExists, Records: conflicts}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]ImportResult, len(tasks))
+	for i, task := range tasks {
+		results[i] = ImportResult{Id: task.Id, Action: actions[i]}
+		if actions[i] != pkg.ImportActionSkipped {
+			// Выходные данные не импортируются: оставшиеся в хранилище относятся к прежней задаче
+			s.deleteResults(task.Id)
+		}
+		if requeue[i] && actions[i] != pkg.ImportActionSkipped {
+			results[i].Requeued = true
+			go s.runTask(context.Background(), task.Id)
+		}
+	}
+	return results, nil
 }
 
-// CreateTask - Создать новую задачу и запустить её выполнение
-func (s *Service) CreateTask(ctx context.Context, taskName string) (task pkg.InternalTask, err error) {
-	task, err = s.store.CreateTask(taskName)
+// CreateTask - Создать новую задачу в пространстве имен запроса и запустить её выполнение
+func (s *Service) CreateTask(ctx context.Context, taskName string, opts ...pkg.TaskOption) (task pkg.InternalTask, err error) {
+	namespace := pkg.NamespaceFromContext(ctx)
+	if principal, ok := pkg.PrincipalFromContext(ctx); ok {
+		opts = append(opts, pkg.WithOwner(principal.Name))
+	}
+	opts = append(opts, pkg.InNamespace(namespace))
+	owner := ownerFromContext(ctx)
+
+	// Проверка лимита и создание должны быть атомарны, иначе параллельные запросы превысят лимит
+	s.admission.Lock()
+	if s.maxActiveTasks > 0 && s.store.CountTasks(pkg.TaskStatusPending, pkg.TaskStatusRunning) >= s.maxActiveTasks {
+		s.admission.Unlock()
+		err = pkg.ErrTooManyTasks
+		return
+	}
+	if err = s.checkQuota(namespace); err != nil {
+		s.admission.Unlock()
+		return
+	}
+	if err = s.checkTenantQuota(owner); err != nil {
+		s.admission.Unlock()
+		return
+	}
+	task, err = s.store.CreateTask(taskName, opts...)
+	if err == nil {
+		s.usage.recordCreated(owner, task.CreatedAt)
+	}
+	s.admission.Unlock()
 	if err != nil {
 		return
 	}
 
 	// Запускаем задачу в фоне
-	go s.simulateIOTask(context.Background(), task.Id)
+	go s.runTask(context.Background(), task.Id)
 
 	return
 }
 
-// GetTask - Получить информацию о задаче с обновленной продолжительностью
-func (s *Service) GetTask(ctx context.Context, taskId string) (task pkg.InternalTask, err error) {
-	task, err = s.store.GetTask(taskId)
-	if err != nil {
-		return
+// quotaFor - Лимиты пространства имен
+func (s *Service) quotaFor(namespace string) pkg.Quota {
+	if quota, ok := s.quotas[namespace]; ok {
+		return quota
 	}
+	return s.defaultQuota
+}
 
-	// Обновляем duration если задача еще выполняется
-	if task.Status == pkg.TaskStatusRunning && !task.StartedAt.IsZero() {
-		duration := time.Since(task.StartedAt)
-		task.Duration = duration.Round(time.Second).String()
-		// Обновляем в хранилище
-		s.store.UpdateTask(task)
+// checkQuota - Проверить, что в пространстве имен можно создать еще одну задачу
+// (вызывается под admission)
+func (s *Service) checkQuota(namespace string) error {
+	quota := s.quotaFor(namespace)
+	if quota.MaxActiveTasks > 0 {
+		if active := s.store.CountNamespaceTasks(namespace, pkg.TaskStatusPending, pkg.TaskStatusRunning); active >= quota.MaxActiveTasks {
+			return fmt.Errorf("%w: %d of %d active tasks in namespace %q", pkg.ErrQuotaExceeded, active, quota.MaxActiveTasks, namespace)
+		}
+	}
+	if quota.MaxStoredTasks > 0 {
+		if stored := s.store.CountNamespaceTasks(namespace); stored >= quota.MaxStoredTasks {
+			return fmt.Errorf("%w: %d of %d stored tasks in namespace %q", pkg.ErrQuotaExceeded, stored, quota.MaxStoredTasks, namespace)
+		}
 	}
+	return nil
+}
 
-	return
+// ListNamespaces - Пространства имен с задачами и с заданными лимитами: число задач и лимиты
+func (s *Service) ListNamespaces(ctx context.Context) []pkg.NamespaceInfo {
+	namespaces := s.store.Namespaces()
+	known := make(map[string]bool, len(namespaces))
+	for i := range namespaces {
+		known[namespaces[i].Name] = true
+		namespaces[i].Quota = s.quotaFor(namespaces[i].Name)
+	}
+	for name, quota := range s.quotas {
+		if !known[name] {
+			namespaces = append(namespaces, pkg.NamespaceInfo{Name: name, Quota: quota})
+		}
+	}
+	sort.Slice(namespaces, func(i, j int) bool { return namespaces[i].Name < namespaces[j].Name })
+	return namespaces
+}
+
+// PurgeNamespace - Удалить все задачи пространства имен. Запуски выполняющихся задач отменяются
+// (причина - pkg.ErrTaskCancelled), их переход в финальный статус не находит задачу.
+// Требует прав на изменение чужих задач.
+// Возвращает число удаленных задач
+func (s *Service) PurgeNamespace(ctx context.Context, namespace string) (int, error) {
+	if err := pkg.ValidateNamespace(namespace); err != nil {
+		return 0, err
+	}
+	if principal, ok := pkg.PrincipalFromContext(ctx); ok && !principal.CanModifyAll() {
+		return 0, fmt.Errorf("%w: purging namespace %q requires operator role", pkg.ErrForbidden, namespace)
+	}
+
+	// Под dispatch задачи пространства не запустятся между отменой запусков и удалением
+	s.dispatch.Lock()
+	for taskId, run := range s.runs {
+		if task, err := s.store.GetTask(taskId); err == nil && task.Namespace == namespace {
+			run.cancel(pkg.ErrTaskCancelled)
+		}
+	}
+	ids := s.store.PurgeNamespace(namespace)
+	s.dispatch.Unlock()
+
+	s.deleteResults(ids...)
+	return len(ids), nil
+}
+
+// GetTask - Получить информацию о задаче. Чтение не изменяет задачу: продолжительность
+// вычисляется при выдаче (pkg.InternalTask.Elapsed)
+func (s *Service) GetTask(ctx context.Context, taskId string) (task pkg.InternalTask, err error) {
+	return s.getOwnTask(ctx, taskId, false)
 }
 
 // DeleteTask - Удалить задачу
 func (s *Service) DeleteTask(ctx context.Context, taskId string) error {
-	return s.store.DeleteTask(taskId)
+	return s.DeleteTaskIfMatch(ctx, taskId, nil)
+}
+
+// DeleteTaskIfMatch - Удалить задачу, если match принимает ее текущую версию (nil - любую),
+// иначе - pkg.ErrVersionConflict. Задача, измененная между проверкой и удалением, не удаляется
+func (s *Service) DeleteTaskIfMatch(ctx context.Context, taskId string, match func(version uint64) bool) error {
+	task, err := s.getOwnTask(ctx, taskId, true)
+	if err != nil {
+		return err
+	}
+	if match == nil {
+		err = s.store.DeleteTask(taskId)
+	} else if !match(task.Version) {
+		return fmt.Errorf("%w: version %d does not match", pkg.ErrVersionConflict, task.Version)
+	} else {
+		err = s.store.CompareAndDelete(taskId, task.Version)
+	}
+	if err != nil {
+		return err
+	}
+	// Выходные данные могли появиться после чтения задачи, поэтому удаляются без проверки Output
+	s.deleteResults(taskId)
+	return nil
+}
+
+// GetTaskHistory - Получить историю переходов задачи между статусами
+func (s *Service) GetTaskHistory(ctx context.Context, taskId string) ([]pkg.TaskTransition, error) {
+	task, err := s.getOwnTask(ctx, taskId, false)
+	if err != nil {
+		return nil, err
+	}
+	return task.History, nil
+}
+
+// GetTaskDeliveries - Получить историю доставки webhook задачи
+func (s *Service) GetTaskDeliveries(ctx context.Context, taskId string) ([]pkg.WebhookDelivery, error) {
+	task, err := s.getOwnTask(ctx, taskId, false)
+	if err != nil {
+		return nil, err
+	}
+	return task.Deliveries, nil
 }
 
 // GetTaskResult - Получить результат задачи (бизнес-логика проверки готовности)
 func (s *Service) GetTaskResult(ctx context.Context, taskId string) (task pkg.InternalTask, err error) {
-	task, err = s.store.GetTask(taskId)
+	task, err = s.getOwnTask(ctx, taskId, false)
 	if err != nil {
 		return
 	}
 
-	if task.Status != pkg.TaskStatusCompleted && task.Status != pkg.TaskStatusFailed {
-		err = fmt.Errorf("%s", pkg.TaskErrorNotCompleted)
+	if !pkg.IsTerminalStatus(task.Status) {
+		err = pkg.ErrTaskNotCompleted
 		return
 	}
 
@@ -2,6 +2,10 @@ package internal
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
 	"testing"
 	"time"
 	"workmate/pkg"
@@ -27,7 +31,7 @@ func TestCreateTask(t *testing.T) {
 	}
 
 	// Проверяем, что задача появилась в хранилище
-	tasks, _ := service.GetTasks(ctx)
+	tasks, _ := service.GetTasks(ctx, pkg.TaskFilter{})
 	if len(tasks) != 1 {
 		t.Errorf("Expected 1 task in store, got %d", len(tasks))
 	}
@@ -70,7 +74,7 @@ func TestGetTasks(t *testing.T) {
 	ctx := context.Background()
 
 	// Проверяем, что изначально список пуст
-	tasks, err := service.GetTasks(ctx)
+	tasks, err := service.GetTasks(ctx, pkg.TaskFilter{})
 	if err != nil {
 		t.Fatalf("GetTasks() returned error: %v", err)
 	}
@@ -83,7 +87,7 @@ func TestGetTasks(t *testing.T) {
 	service.CreateTask(ctx, "Task 2")
 
 	// Проверяем, что все задачи возвращаются
-	tasks, err = service.GetTasks(ctx)
+	tasks, err = service.GetTasks(ctx, pkg.TaskFilter{})
 	if err != nil {
 		t.Fatalf("GetTasks() returned error: %v", err)
 	}
@@ -92,6 +96,139 @@ func TestGetTasks(t *testing.T) {
 	}
 }
 
+func TestExportTasks(t *testing.T) {
+	clock := pkg.NewFakeClock(time.Date(2024, 1, 15, 15, 4, 5, 0, time.UTC))
+	service := NewService(WithClock(clock), WithRandSeed(1))
+	alice := pkg.WithPrincipal(context.Background(), pkg.Principal{Name: "alice", Roles: []string{pkg.RoleSubmitter}})
+	bob := pkg.WithPrincipal(context.Background(), pkg.Principal{Name: "bob", Roles: []string{pkg.RoleSubmitter}})
+
+	first, _ := service.CreateTask(alice, "Task 1")
+	service.CreateTask(bob, "Task 2")
+	clock.BlockUntil(2)
+	third, _ := service.CreateTask(alice, "Task 3")
+	clock.BlockUntil(3)
+	clock.Advance(10 * time.Second)
+
+	export := func(ctx context.Context, filter pkg.TaskFilter) (tasks []pkg.InternalTask) {
+		t.Helper()
+		err := service.ExportTasks(ctx, filter, func(task pkg.InternalTask) error {
+			tasks = append(tasks, task)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("ExportTasks() returned error: %v", err)
+		}
+		return
+	}
+
+	// Только свои задачи, в порядке добавления; продолжительность считается от времени начала
+	tasks := export(alice, pkg.TaskFilter{})
+	if len(tasks) != 2 || tasks[0].Id != first.Id || tasks[1].Id != third.Id {
+		t.Fatalf("Expected own tasks in insertion order, got %+v", tasks)
+	}
+	if elapsed := tasks[0].Elapsed(service.Now()); elapsed != 10*time.Second {
+		t.Errorf("Expected elapsed 10s, got %s", elapsed)
+	}
+
+	// Фильтр и лимит
+	tasks = export(context.Background(), pkg.TaskFilter{Statuses: []string{pkg.TaskStatusRunning}, Limit: 2})
+	if len(tasks) != 2 {
+		t.Errorf("Expected 2 tasks, got %d", len(tasks))
+	}
+
+	// Неизвестный статус отклоняется до обхода, ошибка fn прерывает обход
+	err := service.ExportTasks(alice, pkg.TaskFilter{Statuses: []string{"done"}}, nil)
+	if !errors.Is(err, pkg.ErrUnknownStatus) {
+		t.Errorf("Expected ErrUnknownStatus, got %v", err)
+	}
+	calls := 0
+	stop := errors.New("stop")
+	err = service.ExportTasks(alice, pkg.TaskFilter{}, func(task pkg.InternalTask) error {
+		calls++
+		return stop
+	})
+	if err != stop || calls != 1 {
+		t.Errorf("Expected export to stop after 1 task, got %d calls and %v", calls, err)
+	}
+}
+
+func TestImportTasks(t *testing.T) {
+	start := time.Date(2024, 1, 15, 15, 4, 5, 0, time.UTC)
+	clock := pkg.NewFakeClock(start.Add(time.Hour))
+	service := NewService(WithClock(clock), WithRandSeed(1))
+	ctx := context.Background()
+
+	completed := pkg.InternalTask{
+		Id: "550e8400-e29b-41d4-a716-446655440000", Name: "Completed", Status: pkg.TaskStatusCompleted, Owner: "alice",
+		CreatedAt: start, StartedAt: start.Add(time.Second), FinishedAt: start.Add(time.Minute), Result: "done",
+	}
+	running := pkg.InternalTask{
+		Id: "6ba7b810-9dad-11d1-80b4-00c04fd430c8", Name: "Running", Status: pkg.TaskStatusRunning,
+		CreatedAt: start, StartedAt: start.Add(time.Second),
+	}
+
+	// Ошибки записей возвращаются по индексам, ничего не загружается
+	invalid := completed
+	invalid.Status = "done"
+	_, err := service.ImportTasks(ctx, []pkg.InternalTask{completed, invalid, completed}, ImportOptions{})
+	var records *pkg.RecordErrors
+	if !errors.As(err, &records) || records.Err != pkg.ErrInvalidTaskRecord || len(records.Records) != 2 ||
+		records.Records[1] == nil || records.Records[2] == nil {
+		t.Fatalf("Expected errors for records 1 and 2, got %v", err)
+	}
+	if _, err := service.store.GetTask(completed.Id); err == nil {
+		t.Error("Nothing should be imported when a record is invalid")
+	}
+
+	// Задачи загружаются как есть, незавершенные с Requeue запускаются заново
+	results, err := service.ImportTasks(ctx, []pkg.InternalTask{completed, running}, ImportOptions{Requeue: true})
+	if err != nil {
+		t.Fatalf("ImportTasks() returned error: %v", err)
+	}
+	if results[0].Requeued || !results[1].Requeued || results[1].Action != pkg.ImportActionCreated {
+		t.Errorf("Unexpected import results: %+v", results)
+	}
+	if task, _ := service.store.GetTask(completed.Id); task.Result != "done" || task.Owner != "alice" || !task.FinishedAt.Equal(completed.FinishedAt) {
+		t.Errorf("Expected imported task to be preserved, got %+v", task)
+	}
+	clock.BlockUntil(1)
+	if task, _ := service.store.GetTask(running.Id); task.Status != pkg.TaskStatusRunning || !task.StartedAt.Equal(clock.Now()) {
+		t.Errorf("Expected requeued task to be restarted, got %+v", task)
+	}
+
+	// Повторный импорт с политикой по умолчанию отклоняется целиком
+	_, err = service.ImportTasks(ctx, []pkg.InternalTask{completed}, ImportOptions{})
+	if !errors.As(err, &records) || records.Err != pkg.ErrTaskExists || records.Records[0] == nil {
+		t.Errorf("Expected conflict for record 0, got %v", err)
+	}
+}
+
+func TestImportTasksRequiresAdmin(t *testing.T) {
+	service := NewService(WithExecutor(&blockingExecutor{}))
+	alice := pkg.WithPrincipal(context.Background(), pkg.Principal{Name: "alice"})
+	bob := pkg.WithPrincipal(context.Background(), pkg.Principal{Name: "bob"})
+
+	task, _ := service.CreateTask(alice, "Alice Task")
+	original := waitForStatus(t, service, task.Id, pkg.TaskStatusRunning)
+
+	// Не администратор не может заменить чужую задачу и назначить владельца
+	record := original
+	record.Name, record.Owner, record.Result = "Bob Task", "bob", "forged"
+	results, err := service.ImportTasks(bob, []pkg.InternalTask{record}, ImportOptions{Conflict: pkg.ImportConflictOverwrite})
+	if !errors.Is(err, pkg.ErrForbidden) || results != nil {
+		t.Fatalf("Expected ErrForbidden for non-admin import, got %+v, %v", results, err)
+	}
+	if stored, _ := service.store.GetTask(task.Id); stored.Owner != "alice" || stored.Name != "Alice Task" ||
+		stored.Result != original.Result || stored.Version != original.Version {
+		t.Errorf("Expected task to stay unchanged, got %+v", stored)
+	}
+
+	admin := pkg.WithPrincipal(context.Background(), pkg.Principal{Name: "ops", Scopes: []string{pkg.ScopeAdmin}})
+	if _, err := service.ImportTasks(admin, []pkg.InternalTask{record}, ImportOptions{Conflict: pkg.ImportConflictOverwrite}); err != nil {
+		t.Errorf("ImportTasks() as admin returned error: %v", err)
+	}
+}
+
 func TestDeleteTask(t *testing.T) {
 	service := NewService()
 	ctx := context.Background()
@@ -118,6 +255,51 @@ func TestDeleteTask(t *testing.T) {
 	}
 }
 
+func TestGetTaskIsReadOnly(t *testing.T) {
+	clock := pkg.NewFakeClock(time.Date(2024, 1, 15, 15, 4, 5, 0, time.UTC))
+	service := NewService(WithClock(clock))
+	ctx := context.Background()
+
+	task, _ := service.CreateTask(ctx, "Test Task")
+	running := waitForStatus(t, service, task.Id, pkg.TaskStatusRunning)
+
+	// Чтение не изменяет задачу: версия (ETag) стабильна, продолжительность растет со временем
+	clock.Advance(time.Second)
+	read, err := service.GetTask(ctx, task.Id)
+	if err != nil || read.Version != running.Version || read.Elapsed(service.Now()) != time.Second {
+		t.Fatalf("GetTask() = %+v, %v", read, err)
+	}
+	service.GetTasks(ctx, pkg.TaskFilter{})
+
+	// Завершение после чтения не перезаписывается
+	service.store.Transition(task.Id, pkg.TaskStatusCompleted, "test", func(task *pkg.InternalTask, at time.Time) {
+		task.FinishedAt = at
+	})
+	clock.Advance(time.Minute)
+	current, _ := service.GetTask(ctx, task.Id)
+	if current.Status != pkg.TaskStatusCompleted || current.Elapsed(service.Now()) != time.Second {
+		t.Errorf("Expected completed task with elapsed 1s, got %+v", current)
+	}
+}
+
+func TestDeleteTaskIfMatch(t *testing.T) {
+	service := NewService()
+	ctx := context.Background()
+
+	task, _ := service.CreateTask(ctx, "Test Task")
+	current := waitForStatus(t, service, task.Id, pkg.TaskStatusRunning)
+
+	version := func(expected uint64) func(uint64) bool {
+		return func(v uint64) bool { return v == expected }
+	}
+	if err := service.DeleteTaskIfMatch(ctx, task.Id, version(task.Version)); !errors.Is(err, pkg.ErrVersionConflict) {
+		t.Errorf("Expected ErrVersionConflict, got %v", err)
+	}
+	if err := service.DeleteTaskIfMatch(ctx, task.Id, version(current.Version)); err != nil {
+		t.Errorf("DeleteTaskIfMatch() returned error: %v", err)
+	}
+}
+
 func TestGetTaskResult(t *testing.T) {
 	service := NewService()
 	ctx := context.Background()
@@ -132,9 +314,10 @@ func TestGetTaskResult(t *testing.T) {
 	}
 
 	// Имитируем завершение задачи
-	task.Status = pkg.TaskStatusCompleted
-	task.Result = "Test result"
-	service.store.UpdateTask(task)
+	waitForStatus(t, service, task.Id, pkg.TaskStatusRunning)
+	service.store.Transition(task.Id, pkg.TaskStatusCompleted, "test", func(task *pkg.InternalTask, at time.Time) {
+		task.Result = "Test result"
+	})
 
 	// Получаем результат завершенной задачи
 	completedTask, err := service.GetTaskResult(ctx, task.Id)
@@ -161,12 +344,345 @@ func TestSimulateIOTask(t *testing.T) {
 	}
 
 	// Запускаем симуляцию задачи
-	service.simulateIOTask(ctx, task.Id)
+	service.runTask(ctx, task.Id)
 
-	// Проверяем, что задача перешла в статус running или failed
+	// Проверяем, что задача перешла в статус running (ее уже запустил CreateTask) или cancelled
 	retrievedTask, _ = service.GetTask(ctx, task.Id)
-	if retrievedTask.Status != pkg.TaskStatusRunning && retrievedTask.Status != pkg.TaskStatusFailed {
+	if retrievedTask.Status != pkg.TaskStatusRunning && retrievedTask.Status != pkg.TaskStatusCancelled {
 		t.Errorf("Expected task status '%s' or '%s', got '%s'",
-			pkg.TaskStatusRunning, pkg.TaskStatusFailed, retrievedTask.Status)
+			pkg.TaskStatusRunning, pkg.TaskStatusCancelled, retrievedTask.Status)
+	}
+}
+
+func TestMaxActiveTasks(t *testing.T) {
+	service := NewService(WithMaxActiveTasks(2))
+	ctx := context.Background()
+
+	service.CreateTask(ctx, "Task 1")
+	service.CreateTask(ctx, "Task 2")
+
+	// Третья задача превышает лимит активных задач
+	_, err := service.CreateTask(ctx, "Task 3")
+	if err == nil || err.Error() != pkg.TaskErrorTooManyTasks {
+		t.Errorf("Expected error '%s', got '%v'", pkg.TaskErrorTooManyTasks, err)
+	}
+
+	// Удаление освобождает место
+	tasks, _ := service.GetTasks(ctx, pkg.TaskFilter{})
+	service.DeleteTask(ctx, tasks[0].Id)
+	if _, err := service.CreateTask(ctx, "Task 4"); err != nil {
+		t.Errorf("CreateTask() after freeing a slot returned error: %v", err)
+	}
+}
+
+func TestNamespaces(t *testing.T) {
+	service := NewService(WithNamespaceQuotas(pkg.Quota{}, map[string]pkg.Quota{
+		"team-a": {MaxActiveTasks: 1, MaxStoredTasks: 2},
+	}))
+	ctx := context.Background()
+	teamA := pkg.WithNamespace(ctx, "team-a")
+
+	defaultTask, _ := service.CreateTask(ctx, "Default Task")
+	teamTask, err := service.CreateTask(teamA, "Team Task")
+	if err != nil {
+		t.Fatalf("CreateTask() returned error: %v", err)
+	}
+	if teamTask.Namespace != "team-a" {
+		t.Errorf("Expected task in namespace team-a, got %q", teamTask.Namespace)
+	}
+
+	// id из другого пространства имен не видны
+	if _, err := service.GetTask(ctx, teamTask.Id); !errors.Is(err, pkg.ErrTaskNotFound) {
+		t.Errorf("Expected ErrTaskNotFound for task of another namespace, got %v", err)
+	}
+	if err := service.DeleteTask(teamA, defaultTask.Id); !errors.Is(err, pkg.ErrTaskNotFound) {
+		t.Errorf("Expected ErrTaskNotFound on delete from another namespace, got %v", err)
+	}
+	if tasks, _ := service.GetTasks(teamA, pkg.TaskFilter{}); len(tasks) != 1 || tasks[0].Id != teamTask.Id {
+		t.Errorf("Expected only the team-a task, got %d tasks", len(tasks))
+	}
+
+	// Лимит активных задач пространства
+	if _, err := service.CreateTask(teamA, "Team Task 2"); !errors.Is(err, pkg.ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded for active tasks, got %v", err)
+	}
+	if _, err := service.store.Transition(teamTask.Id, pkg.TaskStatusCancelled, "test", nil); err != nil {
+		t.Fatalf("Transition() returned error: %v", err)
+	}
+	third, err := service.CreateTask(teamA, "Team Task 3")
+	if err != nil {
+		t.Fatalf("CreateTask() after the task finished returned error: %v", err)
+	}
+	// Лимит хранимых задач учитывает и завершенные
+	service.store.Transition(third.Id, pkg.TaskStatusCancelled, "test", nil)
+	if _, err := service.CreateTask(teamA, "Team Task 4"); !errors.Is(err, pkg.ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded for stored tasks, got %v", err)
+	}
+
+	namespaces := service.ListNamespaces(ctx)
+	if len(namespaces) != 2 || namespaces[1].Name != "team-a" || namespaces[1].StoredTasks != 2 || namespaces[1].Quota.MaxStoredTasks != 2 {
+		t.Errorf("Unexpected namespaces: %+v", namespaces)
+	}
+
+	submitter := pkg.WithPrincipal(ctx, pkg.Principal{Name: "alice", Roles: []string{pkg.RoleSubmitter}})
+	if _, err := service.PurgeNamespace(submitter, "team-a"); !errors.Is(err, pkg.ErrForbidden) {
+		t.Errorf("Expected ErrForbidden for submitter, got %v", err)
+	}
+	if count, err := service.PurgeNamespace(ctx, "team-a"); err != nil || count != 2 {
+		t.Errorf("Expected 2 purged tasks, got %d, %v", count, err)
+	}
+	if _, err := service.GetTask(ctx, defaultTask.Id); err != nil {
+		t.Errorf("Expected default task to survive purge, got %v", err)
+	}
+}
+
+func TestPurgeNamespaceCancelsRuns(t *testing.T) {
+	service := NewService(WithExecutor(&blockingExecutor{}))
+	ctx := context.Background()
+	teamA := pkg.WithNamespace(ctx, "team-a")
+
+	task, _ := service.CreateTask(teamA, "Team Task")
+	other, _ := service.CreateTask(ctx, "Default Task")
+	waitForStatus(t, service, task.Id, pkg.TaskStatusRunning)
+	waitForStatus(t, service, other.Id, pkg.TaskStatusRunning)
+
+	if count, err := service.PurgeNamespace(ctx, "team-a"); err != nil || count != 1 {
+		t.Fatalf("PurgeNamespace() = %d, %v", count, err)
+	}
+	hasRun := func(taskId string) bool {
+		service.dispatch.Lock()
+		defer service.dispatch.Unlock()
+		return service.runs[taskId] != nil
+	}
+
+	// Исполнитель задачи получает отмену и запуск заканчивается; задачи других пространств выполняются
+	for i := 0; i < 1000 && hasRun(task.Id); i++ {
+		time.Sleep(time.Millisecond)
+	}
+	if hasRun(task.Id) {
+		t.Error("Expected run of purged task to be cancelled")
+	}
+	if !hasRun(other.Id) {
+		t.Error("Expected task of another namespace to keep running")
+	}
+}
+
+// Вспомогательная функция: дождаться, пока фоновая горутина переведет задачу в статус
+func waitForStatus(t *testing.T, service *Service, taskId, status string) pkg.InternalTask {
+	t.Helper()
+	for i := 0; i < 1000; i++ {
+		task, err := service.store.GetTask(taskId)
+		if err == nil && task.Status == status {
+			return task
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("Task %s did not reach status '%s'", taskId, status)
+	return pkg.InternalTask{}
+}
+
+func TestSimulateIOTaskCompletes(t *testing.T) {
+	start := time.Date(2024, 1, 15, 15, 4, 5, 0, time.UTC)
+	clock := pkg.NewFakeClock(start)
+	service := NewService(WithClock(clock), WithRandSeed(42))
+	ctx := context.Background()
+
+	// Длительность симуляции детерминирована зерном
+	expected := time.Duration(rand.New(rand.NewSource(42)).Intn(121)+180) * time.Second
+
+	task, _ := service.CreateTask(ctx, "Test Task")
+	if !task.CreatedAt.Equal(start) {
+		t.Errorf("Expected CreatedAt %s, got %s", start, task.CreatedAt)
+	}
+
+	// Задача запущена и ждет таймер
+	clock.BlockUntil(1)
+	clock.Advance(expected - time.Second)
+	running, _ := service.GetTask(ctx, task.Id)
+	if running.Status != pkg.TaskStatusRunning {
+		t.Fatalf("Expected task status '%s', got '%s'", pkg.TaskStatusRunning, running.Status)
+	}
+	if elapsed := running.Elapsed(service.Now()); elapsed != expected-time.Second {
+		t.Errorf("Expected elapsed %s, got %s", expected-time.Second, elapsed)
+	}
+
+	// Через оставшуюся секунду задача завершается
+	clock.Advance(time.Second)
+	completed := waitForStatus(t, service, task.Id, pkg.TaskStatusCompleted)
+	if !completed.FinishedAt.Equal(start.Add(expected)) {
+		t.Errorf("Expected FinishedAt %s, got %s", start.Add(expected), completed.FinishedAt)
+	}
+	if completed.Result != fmt.Sprintf("Task completed successfully after %s", expected) {
+		t.Errorf("Unexpected result '%s'", completed.Result)
+	}
+
+	result, err := service.GetTaskResult(ctx, task.Id)
+	if err != nil || result.Elapsed(time.Time{}) != expected {
+		t.Errorf("GetTaskResult() = %+v, %v", result, err)
+	}
+}
+
+func TestGetTaskHistory(t *testing.T) {
+	clock := pkg.NewFakeClock(time.Date(2024, 1, 15, 15, 4, 5, 0, time.UTC))
+	service := NewService(WithClock(clock), WithRandSeed(1))
+	alice := pkg.WithPrincipal(context.Background(), pkg.Principal{Name: "alice", Roles: []string{pkg.RoleSubmitter}})
+	bob := pkg.WithPrincipal(context.Background(), pkg.Principal{Name: "bob", Roles: []string{pkg.RoleSubmitter}})
+
+	task, _ := service.CreateTask(alice, "Test Task")
+	clock.BlockUntil(1)
+
+	// Второй исполнитель не может запустить уже запущенную задачу
+	service.runTask(context.Background(), task.Id)
+
+	clock.Advance(5 * time.Minute)
+	waitForStatus(t, service, task.Id, pkg.TaskStatusCompleted)
+
+	history, err := service.GetTaskHistory(alice, task.Id)
+	if err != nil {
+		t.Fatalf("GetTaskHistory() returned error: %v", err)
+	}
+	var statuses []string
+	for _, transition := range history {
+		statuses = append(statuses, transition.From+">"+transition.To)
+	}
+	if fmt.Sprint(statuses) != "[>pending pending>running running>completed]" {
+		t.Errorf("Unexpected history %v", statuses)
+	}
+
+	if _, err := service.GetTaskHistory(bob, task.Id); !errors.Is(err, pkg.ErrTaskNotFound) {
+		t.Errorf("Expected ErrTaskNotFound for another owner, got %v", err)
+	}
+}
+
+// BenchmarkGetTasksUnderPolling - Список из 100k задач (половина выполняется), пока
+// параллельные клиенты опрашивают отдельные задачи: чтения не берут блокировку на запись
+func BenchmarkGetTasksUnderPolling(b *testing.B) {
+	clock := pkg.NewFakeClock(time.Date(2024, 1, 15, 15, 4, 5, 0, time.UTC))
+	service := NewService(WithClock(clock))
+	ctx := context.Background()
+
+	ids := make([]string, 100000)
+	for i := range ids {
+		task, _ := service.store.CreateTask(fmt.Sprintf("Task %d", i))
+		if i%2 == 0 {
+			service.store.Transition(task.Id, pkg.TaskStatusRunning, "started", func(task *pkg.InternalTask, at time.Time) {
+				task.StartedAt = at
+			})
+		}
+		ids[i] = task.Id
+	}
+	clock.Advance(time.Minute)
+
+	pollCtx, stop := context.WithCancel(ctx)
+	var pollers sync.WaitGroup
+	for p := 0; p < 8; p++ {
+		pollers.Add(1)
+		go func(p int) {
+			defer pollers.Done()
+			for i := p; pollCtx.Err() == nil; i += 8 {
+				service.GetTask(ctx, ids[i%len(ids)])
+			}
+		}(p)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := service.GetTasks(ctx, pkg.TaskFilter{Statuses: []string{pkg.TaskStatusRunning}, Limit: 100}); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+	stop()
+	pollers.Wait()
+}
+
+func TestTenantQuotas(t *testing.T) {
+	start := time.Date(2024, 1, 15, 15, 4, 5, 0, time.UTC)
+	clock := pkg.NewFakeClock(start)
+	service := NewService(WithClock(clock), WithRandSeed(42), WithTenantQuotas(pkg.TenantQuota{}, map[string]pkg.TenantQuota{
+		"alice": {MaxRunningTasks: 1, MaxPendingTasks: 1},
+		"bob":   {MaxTasksPerDay: 1, MaxTaskSeconds: 100},
+	}))
+	alice := pkg.WithPrincipal(context.Background(), pkg.Principal{Name: "alice", Roles: []string{pkg.RoleSubmitter}})
+	bob := pkg.WithPrincipal(context.Background(), pkg.Principal{Name: "bob", Roles: []string{pkg.RoleSubmitter}})
+
+	// Длительность первой задачи детерминирована зерном
+	first := time.Duration(rand.New(rand.NewSource(42)).Intn(121)+180) * time.Second
+
+	running, _ := service.CreateTask(alice, "Alice Task 1")
+	clock.BlockUntil(1)
+
+	// Второй задаче не хватает слота выполнения: она ждет в pending
+	queued, err := service.CreateTask(alice, "Alice Task 2")
+	if err != nil {
+		t.Fatalf("CreateTask() returned error: %v", err)
+	}
+	if task, _ := service.GetTask(alice, queued.Id); task.Status != pkg.TaskStatusPending {
+		t.Errorf("Expected queued task to stay pending, got '%s'", task.Status)
+	}
+	if _, err := service.CreateTask(alice, "Alice Task 3"); !errors.Is(err, pkg.ErrPrincipalQuotaExceeded) {
+		t.Errorf("Expected ErrPrincipalQuotaExceeded for pending tasks, got %v", err)
+	}
+
+	// Лимит задач в сутки и секунд выполнения за период
+	service.CreateTask(bob, "Bob Task 1")
+	clock.BlockUntil(2)
+	if _, err := service.CreateTask(bob, "Bob Task 2"); !errors.Is(err, pkg.ErrPrincipalQuotaExceeded) {
+		t.Errorf("Expected ErrPrincipalQuotaExceeded for tasks per day, got %v", err)
+	}
+	clock.Advance(100 * time.Second)
+	if err := service.checkTenantQuota("bob"); !errors.Is(err, pkg.ErrPrincipalQuotaExceeded) {
+		t.Errorf("Expected ErrPrincipalQuotaExceeded for task-seconds, got %v", err)
+	}
+	report, err := service.Usage(bob, time.Time{}, time.Time{}, "")
+	usage := report.Usage
+	if err != nil || len(usage) != 1 || usage[0].Principal != "bob" {
+		t.Fatalf("Expected only own usage, got %+v, %v", usage, err)
+	}
+	if usage[0].TaskSeconds != 100 || usage[0].RunningTasks != 1 || usage[0].Quota.MaxTaskSeconds != 100 {
+		t.Errorf("Unexpected usage of bob: %+v", usage[0])
+	}
+	if _, err := service.Usage(bob, time.Time{}, time.Time{}, "alice"); !errors.Is(err, pkg.ErrForbidden) {
+		t.Errorf("Expected ErrForbidden for usage of another principal, got %v", err)
+	}
+
+	// Завершение первой задачи освобождает слот: ждущая задача запускается
+	clock.Advance(first - 100*time.Second)
+	waitForStatus(t, service, running.Id, pkg.TaskStatusCompleted)
+	waitForStatus(t, service, queued.Id, pkg.TaskStatusRunning)
+
+	to := clock.Now().Add(time.Second)
+	report, err = service.Usage(context.Background(), start, to, "")
+	usage = report.Usage
+	if err != nil || len(usage) != 2 || usage[0].Principal != "alice" {
+		t.Fatalf("Expected usage of alice and bob, got %+v, %v", usage, err)
+	}
+	if usage[0].TasksCreated != 2 || usage[0].TasksFinished != 1 || usage[0].RunningTasks != 1 || usage[0].PendingTasks != 0 {
+		t.Errorf("Unexpected usage of alice: %+v", usage[0])
+	}
+	if usage[0].TaskSeconds != first.Seconds() {
+		t.Errorf("Expected %v task-seconds of alice, got %v", first.Seconds(), usage[0].TaskSeconds)
+	}
+
+	if _, err := service.Usage(context.Background(), to, start, ""); !errors.Is(err, pkg.ErrInvalidParameters) {
+		t.Errorf("Expected ErrInvalidParameters for inverted window, got %v", err)
+	}
+}
+
+func TestAnonymousTenantQuota(t *testing.T) {
+	service := NewService(WithTenantQuotas(pkg.TenantQuota{MaxPendingTasks: 1}, nil))
+	admin := pkg.WithPrincipal(context.Background(), pkg.Principal{Name: "ops", Scopes: []string{pkg.ScopeAdmin}})
+	alice := pkg.WithPrincipal(context.Background(), pkg.Principal{Name: "alice", Roles: []string{pkg.RoleSubmitter}})
+	service.PauseDispatch(admin)
+
+	// Ожидающие задачи принципалов не учитываются анонимному вызывающему
+	if _, err := service.CreateTask(alice, "Alice Task"); err != nil {
+		t.Fatalf("CreateTask() returned error: %v", err)
+	}
+	if _, err := service.CreateTask(context.Background(), "Anonymous Task"); err != nil {
+		t.Fatalf("CreateTask() without principal returned error: %v", err)
+	}
+	if _, err := service.CreateTask(context.Background(), "Anonymous Task 2"); !errors.Is(err, pkg.ErrPrincipalQuotaExceeded) {
+		t.Errorf("Expected ErrPrincipalQuotaExceeded for anonymous pending tasks, got %v", err)
 	}
 }
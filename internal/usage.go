@@ -0,0 +1,353 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+	"workmate/pkg"
+)
+
+const (
+	// usageRetention - сколько хранится учет потребления: самое раннее начало окна отчета
+	usageRetention = 31 * 24 * time.Hour
+	// dispatchRetryInterval - через сколько повторить запуск задачи, если у владельца
+	// исчерпан лимит секунд выполнения за период
+	dispatchRetryInterval = time.Minute
+)
+
+// runInterval - один запуск задачи: от перехода в running до завершения
+type runInterval struct {
+	start, end time.Time
+}
+
+// principalUsage - учет одного принципала: моменты создания задач и законченные запуски
+// (оба списка упорядочены по времени добавления)
+type principalUsage struct {
+	created []time.Time
+	runs    []runInterval
+}
+
+// usageLedger - учет потребления по принципалам для лимитов и отчета /usage.
+// Записи старше usageRetention отбрасываются при добавлении новых
+type usageLedger struct {
+	mu         sync.Mutex
+	principals map[string]*principalUsage
+}
+
+func newUsageLedger() *usageLedger {
+	return &usageLedger{principals: map[string]*principalUsage{}}
+}
+
+// get - Учет принципала, создается при первом обращении (вызывается под mu)
+func (l *usageLedger) get(principal string) *principalUsage {
+	usage, ok := l.principals[principal]
+	if !ok {
+		usage = &principalUsage{}
+		l.principals[principal] = usage
+	}
+	return usage
+}
+
+// prune - Отбросить записи, закончившиеся до cutoff (вызывается под mu)
+func (u *principalUsage) prune(cutoff time.Time) {
+	i := 0
+	for i < len(u.created) && u.created[i].Before(cutoff) {
+		i++
+	}
+	u.created = u.created[i:]
+
+	i = 0
+	for i < len(u.runs) && u.runs[i].end.Before(cutoff) {
+		i++
+	}
+	u.runs = u.runs[i:]
+}
+
+// recordCreated - Учесть созданную задачу
+func (l *usageLedger) recordCreated(principal string, at time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	usage := l.get(principal)
+	usage.prune(at.Add(-usageRetention))
+	usage.created = append(usage.created, at)
+}
+
+// recordRun - Учесть законченный запуск задачи
+func (l *usageLedger) recordRun(principal string, start, end time.Time) {
+	if start.IsZero() || end.Before(start) {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	usage := l.get(principal)
+	usage.prune(end.Add(-usageRetention))
+	usage.runs = append(usage.runs, runInterval{start: start, end: end})
+}
+
+// created - Число задач принципала, созданных в [from, to)
+func (l *usageLedger) created(principal string, from, to time.Time) (count int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	usage, ok := l.principals[principal]
+	if !ok {
+		return 0
+	}
+	for _, at := range usage.created {
+		if !at.Before(from) && at.Before(to) {
+			count++
+		}
+	}
+	return
+}
+
+// runs - Число запусков принципала, закончившихся в [from, to), и секунды выполнения
+// законченных запусков, пришедшиеся на [from, to)
+func (l *usageLedger) runs(principal string, from, to time.Time) (finished int, seconds float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	usage, ok := l.principals[principal]
+	if !ok {
+		return 0, 0
+	}
+	for _, run := range usage.runs {
+		if !run.end.Before(from) && run.end.Before(to) {
+			finished++
+		}
+		seconds += overlap(run.start, run.end, from, to).Seconds()
+	}
+	return
+}
+
+// names - Принципалы, по которым есть учет, по имени
+func (l *usageLedger) names() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	names := make([]string, 0, len(l.principals))
+	for name := range l.principals {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// overlap - Длительность пересечения интервалов [start, end) и [from, to)
+func overlap(start, end, from, to time.Time) time.Duration {
+	if start.Before(from) {
+		start = from
+	}
+	if end.After(to) {
+		end = to
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// WithTenantQuotas - Лимиты принципалов (владельцев задач): quotas - для перечисленных,
+// defaultQuota - для всех остальных, включая анонимного вызывающего без аутентификации
+func WithTenantQuotas(defaultQuota pkg.TenantQuota, quotas map[string]pkg.TenantQuota) ServiceOption {
+	return func(s *Service) {
+		s.defaultTenantQuota = defaultQuota
+		s.tenantQuotas = quotas
+	}
+}
+
+// ownerFromContext - Принципал, которому учитываются задачи вызывающего
+// ("" - анонимный вызывающий без аутентификации)
+func ownerFromContext(ctx context.Context) string {
+	principal, _ := pkg.PrincipalFromContext(ctx)
+	return principal.Name
+}
+
+// tenantQuotaFor - Лимиты принципала
+func (s *Service) tenantQuotaFor(principal string) pkg.TenantQuota {
+	if quota, ok := s.tenantQuotas[principal]; ok {
+		return quota
+	}
+	return s.defaultTenantQuota
+}
+
+// countOwnerTasks - Число задач принципала в статусе (по индексу владельцев хранилища).
+// Анонимному вызывающему ("") учитываются только задачи без владельца, а не задачи всех
+// принципалов, как при TaskFilter без Owner
+func (s *Service) countOwnerTasks(principal, status string) int {
+	return s.store.CountOwnerTasks(principal, status)
+}
+
+// taskSeconds - Секунды выполнения задач принципала в [from, to): законченные запуски
+// из учета и выполняющиеся сейчас задачи. Возвращает и число законченных в окне запусков
+func (s *Service) taskSeconds(principal string, from, to time.Time) (finished int, seconds float64) {
+	finished, seconds = s.usage.runs(principal, from, to)
+	now := s.clock.Now()
+	for _, started := range s.store.OwnerStartTimes(principal, pkg.TaskStatusRunning) {
+		seconds += overlap(started, now, from, to).Seconds()
+	}
+	return
+}
+
+// checkTenantQuota - Проверить, что принципал может создать еще одну задачу
+// (вызывается под admission)
+func (s *Service) checkTenantQuota(principal string) error {
+	quota := s.tenantQuotaFor(principal)
+	now := s.clock.Now()
+	if quota.MaxPendingTasks > 0 {
+		if pending := s.countOwnerTasks(principal, pkg.TaskStatusPending); pending >= quota.MaxPendingTasks {
+			return fmt.Errorf("%w: %d of %d pending tasks of %q", pkg.ErrPrincipalQuotaExceeded, pending, quota.MaxPendingTasks, principal)
+		}
+	}
+	if quota.MaxTasksPerDay > 0 {
+		if created := s.usage.created(principal, now.Add(-pkg.QuotaDay), now.Add(time.Nanosecond)); created >= quota.MaxTasksPerDay {
+			return fmt.Errorf("%w: %d of %d tasks per day of %q", pkg.ErrPrincipalQuotaExceeded, created, quota.MaxTasksPerDay, principal)
+		}
+	}
+	if quota.MaxTaskSeconds > 0 {
+		if _, used := s.taskSeconds(principal, now.Add(-quota.Period()), now); used >= float64(quota.MaxTaskSeconds) {
+			return fmt.Errorf("%w: %.0f of %d task-seconds per %s of %q", pkg.ErrPrincipalQuotaExceeded, used, quota.MaxTaskSeconds, quota.Period(), principal)
+		}
+	}
+	return nil
+}
+
+// dispatchBlocked - Почему задачу принципала нельзя запустить сейчас: retry == true -
+// исчерпаны секунды выполнения (ждать освобождения нечего, проверка повторяется по таймеру),
+// иначе - заняты все слоты выполнения. blocked == false - запускать можно (вызывается под dispatch)
+func (s *Service) dispatchBlocked(principal string) (blocked, retry bool) {
+	quota := s.tenantQuotaFor(principal)
+	if quota.MaxRunningTasks > 0 && s.countOwnerTasks(principal, pkg.TaskStatusRunning) >= quota.MaxRunningTasks {
+		return true, false
+	}
+	if quota.MaxTaskSeconds > 0 {
+		now := s.clock.Now()
+		if _, used := s.taskSeconds(principal, now.Add(-quota.Period()), now); used >= float64(quota.MaxTaskSeconds) {
+			return true, true
+		}
+	}
+	return false, false
+}
+
+// startTask - Перевести задачу в running, как только это позволяют лимиты владельца, запуски
+// не остановлены (PauseDispatch) и закончен предыдущий запуск задачи; до тех пор задача
+// остается в pending. cancel регистрируется для приостановки запуска (PauseTask)
+func (s *Service) startTask(ctx context.Context, taskId string, cancel context.CancelCauseFunc) (task pkg.InternalTask, run *taskRun, err error) {
+	for {
+		s.dispatch.Lock()
+		task, err = s.store.GetTask(taskId)
+		if err != nil {
+			s.dispatch.Unlock()
+			return
+		}
+		blocked, retry := false, false
+		if task.Status == pkg.TaskStatusPending {
+			if s.dispatchPaused || s.runs[taskId] != nil {
+				blocked = true
+			} else {
+				blocked, retry = s.dispatchBlocked(task.Owner)
+			}
+		}
+		if !blocked {
+			task, err = s.store.Transition(taskId, pkg.TaskStatusRunning, "started", func(task *pkg.InternalTask, at time.Time) {
+				task.StartedAt = at
+			})
+			if err == nil {
+				run = &taskRun{cancel: cancel}
+				s.runs[taskId] = run
+			}
+			s.dispatch.Unlock()
+			return
+		}
+		released := s.released
+		s.dispatch.Unlock()
+
+		var timeout <-chan time.Time
+		if retry {
+			timeout = s.clock.After(dispatchRetryInterval)
+		}
+		select {
+		case <-released:
+		case <-timeout:
+		case <-ctx.Done():
+			return pkg.InternalTask{}, nil, ctx.Err()
+		}
+	}
+}
+
+// finishRun - Учесть законченный запуск задачи и разбудить задачи, ждущие слота выполнения
+func (s *Service) finishRun(task pkg.InternalTask, run *taskRun) {
+	s.usage.recordRun(task.Owner, task.StartedAt, s.clock.Now())
+
+	s.dispatch.Lock()
+	if s.runs[task.Id] == run {
+		delete(s.runs, task.Id)
+	}
+	close(s.released)
+	s.released = make(chan struct{})
+	s.dispatch.Unlock()
+}
+
+// Usage - Потребление принципалов за окно [from, to): по умолчанию to - сейчас, from - сутки
+// до to. Без прав на чужие задачи доступно только свое потребление; principal != "" -
+// только указанный принципал
+func (s *Service) Usage(ctx context.Context, from, to time.Time, principal string) (report pkg.UsageReport, err error) {
+	now := s.clock.Now()
+	if to.IsZero() {
+		to = now
+	}
+	if from.IsZero() {
+		from = to.Add(-pkg.QuotaDay)
+	}
+	if !from.Before(to) {
+		err = fmt.Errorf("%w: from must be before to", pkg.ErrInvalidParameters)
+		return
+	}
+	if from.Before(now.Add(-usageRetention)) {
+		err = fmt.Errorf("%w: usage is kept for %s", pkg.ErrInvalidParameters, usageRetention)
+		return
+	}
+
+	var names []string
+	if caller, ok := pkg.PrincipalFromContext(ctx); ok && !caller.CanReadAll() {
+		if principal != "" && principal != caller.Name {
+			err = fmt.Errorf("%w: usage of %q requires viewer role", pkg.ErrForbidden, principal)
+			return
+		}
+		names = []string{caller.Name}
+	} else if principal != "" {
+		names = []string{principal}
+	} else {
+		names = s.usage.names()
+		known := make(map[string]bool, len(names))
+		for _, name := range names {
+			known[name] = true
+		}
+		for name := range s.tenantQuotas {
+			if !known[name] {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+	}
+
+	report = pkg.UsageReport{From: from, To: to, Usage: make([]pkg.Usage, len(names))}
+	for i, name := range names {
+		finished, seconds := s.taskSeconds(name, from, to)
+		report.Usage[i] = pkg.Usage{
+			Principal:     name,
+			TasksCreated:  s.usage.created(name, from, to),
+			TasksFinished: finished,
+			TaskSeconds:   seconds,
+			RunningTasks:  s.countOwnerTasks(name, pkg.TaskStatusRunning),
+			PendingTasks:  s.countOwnerTasks(name, pkg.TaskStatusPending),
+			Quota:         s.tenantQuotaFor(name),
+		}
+	}
+	return report, nil
+}
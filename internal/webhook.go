@@ -0,0 +1,151 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+	"workmate/pkg"
+)
+
+const (
+	// SignatureHeader - заголовок с HMAC-SHA256 подписью тела webhook: "sha256=<hex>"
+	SignatureHeader = "X-WorkMate-Signature"
+	// EventHeader - заголовок с событием (финальным статусом задачи)
+	EventHeader = "X-WorkMate-Event"
+
+	webhookMaxAttempts   = 5
+	webhookTimeout       = 10 * time.Second
+	webhookResponseLimit = 1024
+)
+
+// WebhookEncoder - сериализация задачи в тело webhook (формат задает API слой)
+type WebhookEncoder func(task pkg.InternalTask) ([]byte, error)
+
+// webhookSender доставляет уведомления о завершении задач с повторами и экспоненциальной задержкой
+type webhookSender struct {
+	client  *http.Client
+	secret  []byte
+	encode  WebhookEncoder
+	backoff time.Duration
+}
+
+func newWebhookSender() *webhookSender {
+	return &webhookSender{
+		client:  &http.Client{Timeout: webhookTimeout},
+		encode:  func(task pkg.InternalTask) ([]byte, error) { return json.Marshal(task) },
+		backoff: time.Second,
+	}
+}
+
+// WithWebhookSecret - Подписывать тело webhook HMAC-SHA256 с этим секретом
+func WithWebhookSecret(secret []byte) ServiceOption {
+	return func(s *Service) {
+		s.webhooks.secret = secret
+	}
+}
+
+// WithWebhookEncoder - Задать формат тела webhook
+func WithWebhookEncoder(encode WebhookEncoder) ServiceOption {
+	return func(s *Service) {
+		s.webhooks.encode = encode
+	}
+}
+
+// WithWebhookBackoff - Задать начальную задержку между повторами доставки (удваивается с каждой попыткой)
+func WithWebhookBackoff(backoff time.Duration) ServiceOption {
+	return func(s *Service) {
+		s.webhooks.backoff = backoff
+	}
+}
+
+// Sign - Посчитать подпись тела webhook в формате заголовка SignatureHeader
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// notifyCompletion - Отправить webhook о финальном статусе задачи, если он запрошен.
+// Каждая попытка записывается в задачу
+func (s *Service) notifyCompletion(task pkg.InternalTask) {
+	event := task.Status
+	if !task.WantsCallback(event) {
+		return
+	}
+
+	body, err := s.webhooks.encode(task)
+	if err != nil {
+		log.Printf("webhook: encode task %s: %v", task.Id, err)
+		return
+	}
+
+	backoff := s.webhooks.backoff
+	for attempt := 1; attempt <= webhookMaxAttempts; attempt++ {
+		delivery := s.webhooks.deliver(task.CallbackURL, event, body, s.clock.Now())
+		delivery.Attempt = attempt
+
+		_, err := s.store.ModifyTask(task.Id, func(t *pkg.InternalTask) {
+			t.Deliveries = append(t.Deliveries[:len(t.Deliveries):len(t.Deliveries)], delivery)
+		})
+		if err != nil {
+			// Задачу удалили - доставлять больше некому
+			return
+		}
+		if delivery.Error == "" && delivery.StatusCode >= 200 && delivery.StatusCode < 300 {
+			return
+		}
+
+		if attempt < webhookMaxAttempts {
+			<-s.clock.After(backoff)
+			backoff *= 2
+		}
+	}
+	log.Printf("webhook: giving up delivering %s for task %s to %s", event, task.Id, task.CallbackURL)
+}
+
+// deliver - Одна попытка доставки
+func (w *webhookSender) deliver(url, event string, body []byte, at time.Time) pkg.WebhookDelivery {
+	delivery := pkg.WebhookDelivery{
+		Event:   event,
+		URL:     url,
+		At:      at,
+		Payload: string(body),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+	req.Header.Set(EventHeader, event)
+	if len(w.secret) > 0 {
+		req.Header.Set(SignatureHeader, Sign(w.secret, body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	defer resp.Body.Close()
+
+	response, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseLimit))
+	delivery.StatusCode = resp.StatusCode
+	delivery.Response = string(response)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		delivery.Error = fmt.Sprintf("unexpected status %d", resp.StatusCode)
+	}
+	return delivery
+}
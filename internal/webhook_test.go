@@ -0,0 +1,136 @@
+package internal
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+	"workmate/pkg"
+)
+
+func TestWebhookDelivery(t *testing.T) {
+	secret := []byte("test-secret")
+	var calls int32
+	var gotSignature, gotEvent string
+	var gotBody []byte
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Первая попытка неудачна, вторая - успешна
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		gotSignature = r.Header.Get(SignatureHeader)
+		gotEvent = r.Header.Get(EventHeader)
+		gotBody, _ = io.ReadAll(r.Body)
+		w.Write([]byte("ok"))
+	}))
+	defer receiver.Close()
+
+	service := NewService(WithWebhookSecret(secret), WithWebhookBackoff(time.Millisecond))
+	task, err := service.store.CreateTask("Test Task", pkg.WithCallback(receiver.URL, nil))
+	if err != nil {
+		t.Fatalf("CreateTask() returned error: %v", err)
+	}
+
+	// Отмененная задача сразу переходит в cancelled и отправляет webhook
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	service.runTask(ctx, task.Id)
+
+	if calls != 2 {
+		t.Fatalf("Expected 2 delivery attempts, got %d", calls)
+	}
+	if gotEvent != pkg.TaskStatusCancelled {
+		t.Errorf("Expected event '%s', got '%s'", pkg.TaskStatusCancelled, gotEvent)
+	}
+	if gotSignature != Sign(secret, gotBody) {
+		t.Errorf("Invalid signature '%s'", gotSignature)
+	}
+
+	deliveries, err := service.GetTaskDeliveries(context.Background(), task.Id)
+	if err != nil {
+		t.Fatalf("GetTaskDeliveries() returned error: %v", err)
+	}
+	if len(deliveries) != 2 {
+		t.Fatalf("Expected 2 recorded deliveries, got %d", len(deliveries))
+	}
+	if deliveries[0].StatusCode != http.StatusServiceUnavailable || deliveries[0].Error == "" {
+		t.Errorf("Unexpected first delivery: %+v", deliveries[0])
+	}
+	if deliveries[1].Attempt != 2 || deliveries[1].StatusCode != http.StatusOK || deliveries[1].Response != "ok" {
+		t.Errorf("Unexpected second delivery: %+v", deliveries[1])
+	}
+}
+
+func TestWebhookEventFilter(t *testing.T) {
+	var calls int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer receiver.Close()
+
+	service := NewService()
+	task, _ := service.store.CreateTask("Test Task", pkg.WithCallback(receiver.URL, []string{pkg.TaskStatusCompleted}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	service.runTask(ctx, task.Id)
+
+	if calls != 0 {
+		t.Errorf("Webhook filtered to 'completed' should not be sent for cancelled task, got %d calls", calls)
+	}
+}
+
+func TestCreateTaskCallbackValidation(t *testing.T) {
+	service := NewService()
+	ctx := context.Background()
+
+	if _, err := service.CreateTask(ctx, "Task", pkg.WithCallback("ftp://example.com", nil)); err == nil {
+		t.Error("CreateTask() with non-http callback should return error")
+	}
+	if _, err := service.CreateTask(ctx, "Task", pkg.WithCallback("https://example.com", []string{"running"})); err == nil {
+		t.Error("CreateTask() with non-terminal callback event should return error")
+	}
+}
+
+// doneExecutor - исполнитель, сразу завершающий задачу
+type doneExecutor struct{}
+
+func (doneExecutor) Execute(ctx context.Context, task pkg.InternalTask) (string, error) {
+	return "done", nil
+}
+
+func TestWebhookRetriesReleaseRunSlot(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	clock := pkg.NewFakeClock(time.Date(2024, 1, 15, 15, 4, 5, 0, time.UTC))
+	service := NewService(WithClock(clock), WithExecutor(doneExecutor{}), WithWebhookBackoff(time.Minute),
+		WithTenantQuotas(pkg.TenantQuota{}, map[string]pkg.TenantQuota{"alice": {MaxRunningTasks: 1}}))
+	alice := pkg.WithPrincipal(context.Background(), pkg.Principal{Name: "alice", Roles: []string{pkg.RoleSubmitter}})
+
+	first, err := service.CreateTask(alice, "First", pkg.WithCallback(receiver.URL, nil))
+	if err != nil {
+		t.Fatalf("CreateTask() returned error: %v", err)
+	}
+	second, _ := service.CreateTask(alice, "Second")
+
+	// Доставка webhook первой задачи ждет повторной попытки, а слот выполнения уже свободен
+	waitForStatus(t, service, first.Id, pkg.TaskStatusCompleted)
+	clock.BlockUntil(1)
+	waitForStatus(t, service, second.Id, pkg.TaskStatusCompleted)
+
+	if deliveries, _ := service.GetTaskDeliveries(context.Background(), first.Id); len(deliveries) != 1 {
+		t.Errorf("Expected the webhook to wait for a retry, got %d deliveries", len(deliveries))
+	}
+	report, _ := service.Usage(context.Background(), time.Time{}, clock.Now().Add(time.Second), "alice")
+	if usage := report.Usage; len(usage) != 1 || usage[0].TasksFinished != 2 || usage[0].TaskSeconds != 0 {
+		t.Errorf("Expected webhook retries not to be billed as task time, got %+v", usage)
+	}
+}
@@ -0,0 +1,43 @@
+package pkg
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2024, 1, 15, 15, 4, 5, 0, time.UTC)
+	clock := NewFakeClock(start)
+
+	short := clock.After(time.Minute)
+	long := clock.After(time.Hour)
+
+	// Время стоит, пока часы не сдвинут
+	clock.BlockUntil(2)
+	select {
+	case <-short:
+		t.Fatal("After() fired before Advance()")
+	default:
+	}
+
+	clock.Advance(time.Minute)
+	select {
+	case at := <-short:
+		if !at.Equal(start.Add(time.Minute)) {
+			t.Errorf("Expected %s, got %s", start.Add(time.Minute), at)
+		}
+	default:
+		t.Fatal("After(1m) did not fire after Advance(1m)")
+	}
+	select {
+	case <-long:
+		t.Fatal("After(1h) fired after Advance(1m)")
+	default:
+	}
+
+	clock.Advance(time.Hour)
+	<-long
+	if !clock.Now().Equal(start.Add(time.Hour + time.Minute)) {
+		t.Errorf("Unexpected Now() %s", clock.Now())
+	}
+}
@@ -1,6 +1,12 @@
 package pkg
 
-import "time"
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
 
 // TaskStatus - статус задачи
 const (
@@ -8,13 +14,54 @@ const (
 	TaskStatusRunning   = "running"
 	TaskStatusCompleted = "completed"
 	TaskStatusFailed    = "failed"
+	TaskStatusCancelled = "cancelled"
+	TaskStatusPaused    = "paused"
+)
+
+// transitions - допустимые переходы между статусами; из финальных статусов переходов нет
+var transitions = map[string][]string{
+	TaskStatusPending: {TaskStatusRunning, TaskStatusFailed, TaskStatusCancelled, TaskStatusPaused},
+	TaskStatusRunning: {TaskStatusCompleted, TaskStatusFailed, TaskStatusCancelled, TaskStatusPaused},
+	TaskStatusPaused:  {TaskStatusPending, TaskStatusCancelled},
+}
+
+// TaskError - тексты ошибок задач (типизированные ошибки - в errors.go)
+const (
+	TaskErrorNameRequired  = "Task name is required"
+	TaskErrorNotFound      = "Task not found"
+	TaskErrorNotCompleted  = "Task is not completed yet"
+	TaskErrorTooManyTasks  = "Too many active tasks, try again later"
+	TaskErrorCallbackURL   = "Callback URL must be an absolute http(s) URL"
+	TaskErrorCallbackEvent = "Unknown callback event"
+	TaskErrorUnknownStatus = "Unknown task status"
+	TaskErrorExists        = "Task with this id already exists"
+	TaskErrorInvalidRecord = "Invalid task record"
+	TaskErrorTransition    = "Illegal task status transition"
+	TaskErrorVersion       = "Task was modified concurrently"
+	TaskErrorNamespace     = "Invalid namespace name"
+	TaskErrorQuota         = "Namespace task quota exceeded"
+	TaskErrorTenantQuota   = "Principal task quota exceeded"
+	TaskErrorPaused        = "Task was paused"
+	TaskErrorNotSuspended  = "Executor cannot pause running tasks"
+	TaskErrorNoResult      = "Task has no stored result content"
+)
+
+// MaxCheckpointSize - наибольший размер контрольной точки исполнителя (InternalTask.Checkpoint)
+const MaxCheckpointSize = 64 << 10
+
+// ImportConflict - что делать при импорте задачи, id которой уже есть в хранилище
+const (
+	ImportConflictFail      = "fail"      // ничего не импортировать
+	ImportConflictSkip      = "skip"      // оставить существующую задачу
+	ImportConflictOverwrite = "overwrite" // заменить существующую задачу
 )
 
-// TaskError - ошибка задачи
+// ImportAction - итог импорта одной задачи
 const (
-	TaskErrorNameRequired = "Task name is required"
-	TaskErrorNotFound     = "Task not found"
-	TaskErrorNotCompleted = "Task is not completed yet"
+	ImportActionCreated     = "created"
+	ImportActionOverwritten = "overwritten"
+	ImportActionSkipped     = "skipped"
+	ImportActionConflict    = "conflict" // задача не импортирована из-за ImportConflictFail
 )
 
 // InternalTask - внутренняя сущность задачи
@@ -25,7 +72,239 @@ type InternalTask struct {
 	CreatedAt  time.Time `json:"createdAt"`
 	StartedAt  time.Time `json:"startedAt,omitempty"`
 	FinishedAt time.Time `json:"finishedAt,omitempty"`
+	PausedAt   time.Time `json:"pausedAt,omitempty"`
 	Result     string    `json:"result,omitempty"`
 	Error      string    `json:"error,omitempty"`
-	Duration   string    `json:"duration,omitempty"`
+	Owner      string    `json:"owner,omitempty"`
+
+	// Выходные данные исполнителя в хранилище результатов (ResultStore); nil - только Result
+	Output *ResultInfo `json:"output,omitempty"`
+
+	// Пространство имен задачи (DefaultNamespace, если не задано); задачи разных пространств
+	// не видны друг другу
+	Namespace string `json:"namespace,omitempty"`
+
+	// Контрольная точка исполнителя: непрозрачные данные, которые исполнитель сохраняет во время
+	// выполнения и получает при следующем запуске задачи (возобновление, повторная постановка
+	// в очередь при импорте). Сбрасывается при успешном завершении
+	Checkpoint   []byte    `json:"checkpoint,omitempty"`
+	CheckpointAt time.Time `json:"checkpointAt,omitempty"`
+
+	// Версия задачи: растет при каждом изменении в хранилище, начиная с 1
+	Version uint64 `json:"version"`
+
+	// История переходов между статусами, начиная с создания; ведется хранилищем
+	History []TaskTransition `json:"history,omitempty"`
+
+	// Webhook, вызываемый при переходе задачи в финальный статус
+	CallbackURL    string            `json:"callbackUrl,omitempty"`
+	CallbackEvents []string          `json:"callbackEvents,omitempty"`
+	Deliveries     []WebhookDelivery `json:"deliveries,omitempty"`
+}
+
+// Elapsed - Продолжительность последнего запуска задачи: до завершения, до приостановки или,
+// пока задача выполняется, до момента now. Вычисляется из времени начала, завершения
+// и приостановки, в хранилище не хранится
+func (t InternalTask) Elapsed(now time.Time) time.Duration {
+	if t.StartedAt.IsZero() {
+		return 0
+	}
+	if !t.FinishedAt.IsZero() {
+		return t.FinishedAt.Sub(t.StartedAt)
+	}
+	if !t.PausedAt.IsZero() {
+		return t.PausedAt.Sub(t.StartedAt)
+	}
+	return now.Sub(t.StartedAt)
+}
+
+// TaskTransition - переход задачи между статусами
+type TaskTransition struct {
+	From   string    `json:"from,omitempty"` // пусто для создания и импорта задачи
+	To     string    `json:"to"`
+	At     time.Time `json:"at"`
+	Reason string    `json:"reason,omitempty"`
+}
+
+// WebhookDelivery - попытка доставки webhook о завершении задачи.
+// Слайсы задачи не изменяются на месте: новая попытка добавляется в копию слайса
+type WebhookDelivery struct {
+	Attempt    int       `json:"attempt"`
+	Event      string    `json:"event"`
+	URL        string    `json:"url"`
+	At         time.Time `json:"at"`
+	Payload    string    `json:"payload"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	Response   string    `json:"response,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// IsKnownStatus - Является ли строка известным статусом задачи
+func IsKnownStatus(status string) bool {
+	switch status {
+	case TaskStatusPending, TaskStatusRunning, TaskStatusCompleted, TaskStatusFailed, TaskStatusCancelled, TaskStatusPaused:
+		return true
+	}
+	return false
+}
+
+// IsTerminalStatus - Является ли статус финальным (задача больше не изменится)
+func IsTerminalStatus(status string) bool {
+	return status == TaskStatusCompleted || status == TaskStatusFailed || status == TaskStatusCancelled
+}
+
+// CanTransition - Допустим ли переход задачи из статуса from в статус to
+func CanTransition(from, to string) bool {
+	for _, status := range transitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// validateCallback - Проверить адрес и фильтр событий webhook
+func (t InternalTask) validateCallback() error {
+	if t.CallbackURL == "" {
+		if len(t.CallbackEvents) > 0 {
+			return ErrInvalidCallbackURL
+		}
+		return nil
+	}
+	u, err := url.Parse(t.CallbackURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidCallbackURL
+	}
+	for _, event := range t.CallbackEvents {
+		if !IsTerminalStatus(event) {
+			return fmt.Errorf("%w: %s", ErrUnknownCallbackEvent, event)
+		}
+	}
+	return nil
+}
+
+// ValidateRecord - Проверить задачу, загружаемую из выгрузки: обязательные поля,
+// известный статус и согласованность времени со статусом
+func (t InternalTask) ValidateRecord() error {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: "+format, append([]interface{}{ErrInvalidTaskRecord}, args...)...)
+	}
+	switch {
+	case t.Id == "":
+		return invalid("id is required")
+	case uuid.Validate(t.Id) != nil:
+		return invalid("id %q is not a uuid", t.Id)
+	case t.Name == "":
+		return invalid("name is required")
+	case !IsKnownStatus(t.Status):
+		return invalid("unknown status %q", t.Status)
+	case t.Namespace != "" && !namespacePattern.MatchString(t.Namespace):
+		return invalid("invalid namespace %q", t.Namespace)
+	case t.CreatedAt.IsZero():
+		return invalid("createdAt is required")
+	case t.Status == TaskStatusPending && !t.StartedAt.IsZero():
+		return invalid("pending task must not have startedAt")
+	case t.Status != TaskStatusPending && t.Status != TaskStatusPaused && t.StartedAt.IsZero():
+		return invalid("%s task requires startedAt", t.Status)
+	case t.StartedAt.Before(t.CreatedAt) && !t.StartedAt.IsZero():
+		return invalid("startedAt is before createdAt")
+	case IsTerminalStatus(t.Status) && t.FinishedAt.IsZero():
+		return invalid("%s task requires finishedAt", t.Status)
+	case !IsTerminalStatus(t.Status) && !t.FinishedAt.IsZero():
+		return invalid("%s task must not have finishedAt", t.Status)
+	case !t.FinishedAt.IsZero() && t.FinishedAt.Before(t.StartedAt):
+		return invalid("finishedAt is before startedAt")
+	case t.Status == TaskStatusPaused && t.PausedAt.IsZero():
+		return invalid("paused task requires pausedAt")
+	case t.Status != TaskStatusPaused && !t.PausedAt.IsZero():
+		return invalid("%s task must not have pausedAt", t.Status)
+	case len(t.Checkpoint) > MaxCheckpointSize:
+		return invalid("checkpoint of %d bytes exceeds %d", len(t.Checkpoint), MaxCheckpointSize)
+	case len(t.Checkpoint) > 0 && t.CheckpointAt.IsZero():
+		return invalid("checkpoint requires checkpointAt")
+	}
+	return t.validateCallback()
+}
+
+// WantsCallback - Нужно ли уведомлять webhook о событии (пустой фильтр - все финальные статусы)
+func (t InternalTask) WantsCallback(event string) bool {
+	if t.CallbackURL == "" {
+		return false
+	}
+	if len(t.CallbackEvents) == 0 {
+		return IsTerminalStatus(event)
+	}
+	for _, e := range t.CallbackEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// TaskFilter - фильтр списка задач (нулевые поля не ограничивают выборку)
+type TaskFilter struct {
+	Namespace     string
+	Statuses      []string
+	Owner         string
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	Limit         int
+}
+
+// Validate - Проверить, что фильтр ссылается только на известные статусы
+func (f TaskFilter) Validate() error {
+	for _, status := range f.Statuses {
+		if !IsKnownStatus(status) {
+			return fmt.Errorf("%w: %s", ErrUnknownStatus, status)
+		}
+	}
+	return nil
+}
+
+// Matches - Подходит ли задача под фильтр (без учета Limit)
+func (f TaskFilter) Matches(task InternalTask) bool {
+	if f.Namespace != "" && task.Namespace != f.Namespace {
+		return false
+	}
+	if len(f.Statuses) > 0 {
+		matched := false
+		for _, status := range f.Statuses {
+			if task.Status == status {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if f.Owner != "" && task.Owner != f.Owner {
+		return false
+	}
+	if !f.CreatedAfter.IsZero() && !task.CreatedAt.After(f.CreatedAfter) {
+		return false
+	}
+	if !f.CreatedBefore.IsZero() && !task.CreatedAt.Before(f.CreatedBefore) {
+		return false
+	}
+	return true
+}
+
+// TaskOption - дополнительный параметр создаваемой задачи
+type TaskOption func(*InternalTask)
+
+// WithOwner - Задать владельца задачи
+func WithOwner(owner string) TaskOption {
+	return func(t *InternalTask) {
+		t.Owner = owner
+	}
+}
+
+// WithCallback - Задать webhook и события (финальные статусы), о которых нужно уведомлять
+func WithCallback(url string, events []string) TaskOption {
+	return func(t *InternalTask) {
+		t.CallbackURL = url
+		t.CallbackEvents = events
+	}
 }
@@ -0,0 +1,84 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error - ошибка с устойчивым машиночитаемым кодом. Сравнивается через errors.Is,
+// подробности добавляются оберткой: fmt.Errorf("%w: %s", ErrUnknownStatus, status)
+type Error struct {
+	// Code - код ошибки, на который могут опираться клиенты
+	Code string
+	// Message - текст ошибки
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Ошибки хранилища и бизнес-логики задач
+var (
+	ErrNameRequired         = &Error{Code: "task_name_required", Message: TaskErrorNameRequired}
+	ErrTaskNotFound         = &Error{Code: "task_not_found", Message: TaskErrorNotFound}
+	ErrTaskNotCompleted     = &Error{Code: "task_not_completed", Message: TaskErrorNotCompleted}
+	ErrTooManyTasks         = &Error{Code: "too_many_active_tasks", Message: TaskErrorTooManyTasks}
+	ErrInvalidCallbackURL   = &Error{Code: "invalid_callback_url", Message: TaskErrorCallbackURL}
+	ErrUnknownCallbackEvent = &Error{Code: "unknown_callback_event", Message: TaskErrorCallbackEvent}
+	ErrUnknownStatus        = &Error{Code: "unknown_task_status", Message: TaskErrorUnknownStatus}
+	ErrTaskExists           = &Error{Code: "task_already_exists", Message: TaskErrorExists}
+	ErrInvalidTaskRecord    = &Error{Code: "invalid_task_record", Message: TaskErrorInvalidRecord}
+	ErrIllegalTransition    = &Error{Code: "illegal_status_transition", Message: TaskErrorTransition}
+	ErrVersionConflict      = &Error{Code: "version_conflict", Message: TaskErrorVersion}
+	ErrInvalidNamespace     = &Error{Code: "invalid_namespace", Message: TaskErrorNamespace}
+	ErrQuotaExceeded        = &Error{Code: "quota_exceeded", Message: TaskErrorQuota}
+	// ErrPrincipalQuotaExceeded - исчерпан лимит принципала (pkg.TenantQuota), а не пространства имен
+	ErrPrincipalQuotaExceeded = &Error{Code: "principal_quota_exceeded", Message: TaskErrorTenantQuota}
+	ErrResultNotFound         = &Error{Code: "task_result_not_found", Message: TaskErrorNoResult}
+)
+
+// Ошибки исполнителя задач
+var (
+	ErrTaskCancelled = &Error{Code: "task_cancelled", Message: "Task was cancelled"}
+	// ErrTaskPaused - причина отмены контекста исполнителя при приостановке задачи
+	ErrTaskPaused = &Error{Code: "task_paused", Message: TaskErrorPaused}
+	// ErrNotSuspendable - исполнитель не умеет приостанавливать выполняющиеся задачи
+	ErrNotSuspendable = &Error{Code: "task_not_suspendable", Message: TaskErrorNotSuspended}
+)
+
+// Ошибки обработки запросов API
+var (
+	ErrMalformedRequest  = &Error{Code: "malformed_request", Message: "Malformed request"}
+	ErrInvalidParameters = &Error{Code: "invalid_parameters", Message: "Invalid request parameters"}
+	ErrInvalidBody       = &Error{Code: "invalid_body", Message: "Request body does not match the schema"}
+	ErrPayloadTooLarge   = &Error{Code: "payload_too_large", Message: "Request body is too large"}
+	ErrUnauthorized      = &Error{Code: "unauthorized", Message: "Authentication required"}
+	ErrForbidden         = &Error{Code: "forbidden", Message: "Operation is not permitted"}
+	ErrRateLimited       = &Error{Code: "rate_limited", Message: "Rate limit exceeded, try again later"}
+	ErrInternal          = &Error{Code: "internal_error", Message: "Internal server error"}
+)
+
+// RecordErrors - ошибки отдельных записей пакетной операции по индексу записи;
+// Err - типизированная ошибка всего пакета
+type RecordErrors struct {
+	Err     *Error
+	Records map[int]error
+}
+
+func (e *RecordErrors) Error() string {
+	return fmt.Sprintf("%s: %d of the records", e.Err.Message, len(e.Records))
+}
+
+func (e *RecordErrors) Unwrap() error {
+	return e.Err
+}
+
+// AsError - Типизированная ошибка из цепочки err; для прочих ошибок - ErrInternal
+func AsError(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return ErrInternal
+}
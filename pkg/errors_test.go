@@ -0,0 +1,29 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestAsError(t *testing.T) {
+	wrapped := fmt.Errorf("%w: %s", ErrUnknownStatus, "done")
+	if !errors.Is(wrapped, ErrUnknownStatus) || AsError(wrapped) != ErrUnknownStatus {
+		t.Errorf("Expected wrapped error to match ErrUnknownStatus, got %v", AsError(wrapped))
+	}
+	if wrapped.Error() != TaskErrorUnknownStatus+": done" {
+		t.Errorf("Unexpected message '%s'", wrapped.Error())
+	}
+	if AsError(errors.New("boom")) != ErrInternal {
+		t.Error("Expected ErrInternal for untyped error")
+	}
+
+	// Ошибки хранилища типизированы
+	store := NewTaskStore()
+	if _, err := store.GetTask("missing"); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("Expected ErrTaskNotFound, got %v", err)
+	}
+	if _, err := store.CreateTask("task", WithCallback("", []string{TaskStatusCompleted})); !errors.Is(err, ErrInvalidCallbackURL) {
+		t.Errorf("Expected ErrInvalidCallbackURL, got %v", err)
+	}
+}
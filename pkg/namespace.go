@@ -0,0 +1,67 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+)
+
+// DefaultNamespace - пространство имен маршрутов /api/v1/tasks и вызовов без пространства имен
+const DefaultNamespace = "default"
+
+// namespacePattern - имя пространства имен: строчные латинские буквы, цифры и дефис, до 63 символов
+var namespacePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// ValidateNamespace - Проверить имя пространства имен
+func ValidateNamespace(namespace string) error {
+	if !namespacePattern.MatchString(namespace) {
+		return fmt.Errorf("%w: %q", ErrInvalidNamespace, namespace)
+	}
+	return nil
+}
+
+// Quota - лимиты пространства имен (0 - без лимита)
+type Quota struct {
+	// MaxActiveTasks - задач в статусах pending+running
+	MaxActiveTasks int `json:"maxActiveTasks,omitempty"`
+	// MaxStoredTasks - всех хранимых задач, включая завершенные
+	MaxStoredTasks int `json:"maxStoredTasks,omitempty"`
+}
+
+// Validate - Проверить, что лимиты неотрицательны
+func (q Quota) Validate() error {
+	if q.MaxActiveTasks < 0 || q.MaxStoredTasks < 0 {
+		return fmt.Errorf("%w: quota limits must be >= 0", ErrInvalidParameters)
+	}
+	return nil
+}
+
+// NamespaceInfo - пространство имен: число задач и лимиты
+type NamespaceInfo struct {
+	Name        string `json:"name"`
+	ActiveTasks int    `json:"activeTasks"`
+	StoredTasks int    `json:"storedTasks"`
+	Quota       Quota  `json:"quota"`
+}
+
+// InNamespace - Создать задачу в пространстве имен (по умолчанию DefaultNamespace)
+func InNamespace(namespace string) TaskOption {
+	return func(t *InternalTask) {
+		t.Namespace = namespace
+	}
+}
+
+type namespaceKey struct{}
+
+// WithNamespace - Положить пространство имен запроса в контекст
+func WithNamespace(ctx context.Context, namespace string) context.Context {
+	return context.WithValue(ctx, namespaceKey{}, namespace)
+}
+
+// NamespaceFromContext - Пространство имен запроса; DefaultNamespace, если не задано
+func NamespaceFromContext(ctx context.Context) string {
+	if namespace, ok := ctx.Value(namespaceKey{}).(string); ok && namespace != "" {
+		return namespace
+	}
+	return DefaultNamespace
+}
@@ -0,0 +1,112 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateNamespace(t *testing.T) {
+	for _, namespace := range []string{"default", "team-a", "a", "x1"} {
+		if err := ValidateNamespace(namespace); err != nil {
+			t.Errorf("ValidateNamespace(%q) returned error: %v", namespace, err)
+		}
+	}
+	for _, namespace := range []string{"", "Team", "-a", "a-", "a_b", "a/b"} {
+		if err := ValidateNamespace(namespace); !errors.Is(err, ErrInvalidNamespace) {
+			t.Errorf("ValidateNamespace(%q): expected ErrInvalidNamespace, got %v", namespace, err)
+		}
+	}
+
+	if namespace := NamespaceFromContext(context.Background()); namespace != DefaultNamespace {
+		t.Errorf("Expected default namespace without context value, got %q", namespace)
+	}
+	if namespace := NamespaceFromContext(WithNamespace(context.Background(), "team-a")); namespace != "team-a" {
+		t.Errorf("Expected namespace from context, got %q", namespace)
+	}
+}
+
+func TestNamespaceIsolation(t *testing.T) {
+	store := NewTaskStore(WithShards(4))
+
+	defaultTask, _ := store.CreateTask("Default Task")
+	if defaultTask.Namespace != DefaultNamespace {
+		t.Errorf("Expected task in default namespace, got %q", defaultTask.Namespace)
+	}
+	teamTask, err := store.CreateTask("Team Task", InNamespace("team-a"))
+	if err != nil {
+		t.Fatalf("CreateTask() returned error: %v", err)
+	}
+	store.CreateTask("Team Task 2", InNamespace("team-a"))
+	if _, err := store.CreateTask("Bad Task", InNamespace("Team A")); !errors.Is(err, ErrInvalidNamespace) {
+		t.Errorf("Expected ErrInvalidNamespace, got %v", err)
+	}
+
+	tasks := store.ListTasks(TaskFilter{Namespace: "team-a"})
+	if len(tasks) != 2 || tasks[0].Id != teamTask.Id {
+		t.Errorf("Expected 2 tasks of team-a in creation order, got %d", len(tasks))
+	}
+	if tasks := store.ListTasks(TaskFilter{Namespace: DefaultNamespace}); len(tasks) != 1 || tasks[0].Id != defaultTask.Id {
+		t.Errorf("Expected only the default task, got %d tasks", len(tasks))
+	}
+
+	// Пространство имен не меняется при обновлении
+	updated, _ := store.ModifyTask(teamTask.Id, func(task *InternalTask) { task.Namespace = DefaultNamespace })
+	if updated.Namespace != "team-a" {
+		t.Errorf("Expected namespace to be kept on update, got %q", updated.Namespace)
+	}
+
+	if count := store.CountNamespaceTasks("team-a", TaskStatusPending, TaskStatusRunning); count != 2 {
+		t.Errorf("Expected 2 active tasks in team-a, got %d", count)
+	}
+	store.Transition(teamTask.Id, TaskStatusCancelled, "test", nil)
+	if count := store.CountNamespaceTasks("team-a", TaskStatusPending, TaskStatusRunning); count != 1 {
+		t.Errorf("Expected 1 active task in team-a, got %d", count)
+	}
+	if count := store.CountNamespaceTasks("team-a"); count != 2 {
+		t.Errorf("Expected 2 stored tasks in team-a, got %d", count)
+	}
+
+	namespaces := store.Namespaces()
+	if len(namespaces) != 2 || namespaces[0].Name != DefaultNamespace || namespaces[1].Name != "team-a" {
+		t.Fatalf("Unexpected namespaces: %+v", namespaces)
+	}
+	if namespaces[1].ActiveTasks != 1 || namespaces[1].StoredTasks != 2 {
+		t.Errorf("Unexpected team-a counts: %+v", namespaces[1])
+	}
+
+	if ids := store.PurgeNamespace("team-a"); len(ids) != 2 {
+		t.Errorf("Expected 2 purged tasks, got %v", ids)
+	}
+	if _, err := store.GetTask(teamTask.Id); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("Expected purged task to be gone, got %v", err)
+	}
+	if tasks := store.GetTasks(); len(tasks) != 1 {
+		t.Errorf("Expected default task to survive purge, got %d tasks", len(tasks))
+	}
+}
+
+func TestImportTasksForeignNamespace(t *testing.T) {
+	store := NewTaskStore()
+	existing, _ := store.CreateTask("Team Task", InNamespace("team-a"))
+
+	// Задачу другого пространства нельзя перезаписать даже с политикой overwrite
+	foreign := InternalTask{Id: existing.Id, Name: "Hijacked", Status: TaskStatusPending, CreatedAt: time.Now()}
+	actions, err := store.ImportTasks([]InternalTask{foreign}, ImportConflictOverwrite)
+	if !errors.Is(err, ErrTaskExists) || actions[0] != ImportActionConflict {
+		t.Errorf("Expected conflict for task of another namespace, got %v, %v", actions, err)
+	}
+	if strings.Contains(err.Error(), "namespace") {
+		t.Errorf("Conflict error should not reveal another namespace: %v", err)
+	}
+	if task, _ := store.GetTask(existing.Id); task.Name != existing.Name || task.Namespace != "team-a" {
+		t.Errorf("Expected task of team-a to be kept, got %+v", task)
+	}
+
+	foreign.Namespace = "team-a"
+	if actions, err := store.ImportTasks([]InternalTask{foreign}, ImportConflictOverwrite); err != nil || actions[0] != ImportActionOverwritten {
+		t.Errorf("Expected overwrite within the same namespace, got %v, %v", actions, err)
+	}
+}
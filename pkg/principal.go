@@ -0,0 +1,72 @@
+package pkg
+
+import "context"
+
+// Scope - права доступа ключа/клиента
+const (
+	ScopeAdmin = "admin"
+)
+
+// Role - роль вызывающего в политике доступа
+const (
+	RoleViewer    = "viewer"    // просмотр всех задач и результатов
+	RoleSubmitter = "submitter" // создание и отмена своих задач
+	RoleOperator  = "operator"  // отмена, приостановка и удаление любых задач
+	RoleAdmin     = "admin"     // все операции, включая административные
+)
+
+// Principal - аутентифицированный вызывающий (владелец задач)
+type Principal struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes,omitempty"`
+	Roles  []string `json:"roles,omitempty"`
+}
+
+// HasScope - Проверить наличие права доступа
+func (p Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRole - Проверить наличие роли
+func (p Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAdmin - Имеет ли вызывающий полный доступ (scope или роль admin)
+func (p Principal) IsAdmin() bool {
+	return p.HasScope(ScopeAdmin) || p.HasRole(RoleAdmin)
+}
+
+// CanReadAll - Может ли вызывающий видеть чужие задачи
+func (p Principal) CanReadAll() bool {
+	return p.IsAdmin() || p.HasRole(RoleOperator) || p.HasRole(RoleViewer)
+}
+
+// CanModifyAll - Может ли вызывающий изменять и удалять чужие задачи
+func (p Principal) CanModifyAll() bool {
+	return p.IsAdmin() || p.HasRole(RoleOperator)
+}
+
+type principalKey struct{}
+
+// WithPrincipal - Положить вызывающего в контекст запроса
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext - Получить вызывающего из контекста.
+// ok == false, если аутентификация не настроена (анонимный доступ)
+func PrincipalFromContext(ctx context.Context) (principal Principal, ok bool) {
+	principal, ok = ctx.Value(principalKey{}).(Principal)
+	return
+}
@@ -0,0 +1,129 @@
+package pkg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ResultChecksumPrefix - алгоритм контрольной суммы ResultInfo.Checksum
+const ResultChecksumPrefix = "sha256:"
+
+// ResultInfo - метаданные выходных данных задачи в хранилище результатов
+type ResultInfo struct {
+	ContentType string `json:"contentType"`
+	Size        int64  `json:"size"`
+	// Checksum - контрольная сумма содержимого: "sha256:" и hex
+	Checksum  string    `json:"checksum"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ChecksumBytes - Контрольная сумма без префикса алгоритма (nil для некорректной суммы)
+func (r ResultInfo) ChecksumBytes() []byte {
+	if len(r.Checksum) <= len(ResultChecksumPrefix) || r.Checksum[:len(ResultChecksumPrefix)] != ResultChecksumPrefix {
+		return nil
+	}
+	sum, err := hex.DecodeString(r.Checksum[len(ResultChecksumPrefix):])
+	if err != nil {
+		return nil
+	}
+	return sum
+}
+
+// ResultStore - хранилище выходных данных задач: больших и двоичных результатов исполнителей,
+// которые не помещаются в InternalTask.Result. Метаданные хранятся в InternalTask.Output
+type ResultStore interface {
+	// Put - Сохранить содержимое r как результат задачи, заменив прежний. CreatedAt не заполняется
+	Put(taskId, contentType string, r io.Reader) (ResultInfo, error)
+	// Open - Открыть результат задачи для чтения; нет результата - ErrResultNotFound
+	Open(taskId string) (io.ReadSeekCloser, error)
+	// Delete - Удалить результат задачи (нет результата - не ошибка)
+	Delete(taskId string) error
+}
+
+// FileResultStore - хранилище результатов в каталоге: файл на задачу, имя файла - id задачи.
+// Каталог создается при первой записи
+type FileResultStore struct {
+	dir string
+}
+
+// NewFileResultStore - Хранилище результатов в каталоге dir
+func NewFileResultStore(dir string) *FileResultStore {
+	return &FileResultStore{dir: dir}
+}
+
+// path - Файл результата задачи; id проверяется, чтобы путь не вышел за пределы каталога
+func (s *FileResultStore) path(taskId string) (string, error) {
+	if err := uuid.Validate(taskId); err != nil {
+		return "", fmt.Errorf("%w: result of task %q", ErrResultNotFound, taskId)
+	}
+	return filepath.Join(s.dir, taskId), nil
+}
+
+// Put - Записать результат во временный файл и заменить им прежний: читатели видят
+// либо старое, либо новое содержимое целиком
+func (s *FileResultStore) Put(taskId, contentType string, r io.Reader) (info ResultInfo, err error) {
+	path, err := s.path(taskId)
+	if err != nil {
+		return
+	}
+	if err = os.MkdirAll(s.dir, 0o750); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(s.dir, ".tmp-"+taskId+"-*")
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), r)
+	if err != nil {
+		return
+	}
+	if err = tmp.Close(); err != nil {
+		return
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return
+	}
+	return ResultInfo{ContentType: contentType, Size: size, Checksum: ResultChecksumPrefix + hex.EncodeToString(hash.Sum(nil))}, nil
+}
+
+// Open - Открыть файл результата
+func (s *FileResultStore) Open(taskId string) (io.ReadSeekCloser, error) {
+	path, err := s.path(taskId)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: result of task %s", ErrResultNotFound, taskId)
+	}
+	return f, err
+}
+
+// Delete - Удалить файл результата
+func (s *FileResultStore) Delete(taskId string) error {
+	path, err := s.path(taskId)
+	if err != nil {
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package pkg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestFileResultStore(t *testing.T) {
+	store := NewFileResultStore(t.TempDir() + "/results")
+	taskId := "550e8400-e29b-41d4-a716-446655440000"
+
+	if _, err := store.Open(taskId); !errors.Is(err, ErrResultNotFound) {
+		t.Errorf("Expected ErrResultNotFound before Put, got %v", err)
+	}
+
+	info, err := store.Put(taskId, "text/csv", strings.NewReader("id,value\n1,2\n"))
+	if err != nil {
+		t.Fatalf("Put() returned error: %v", err)
+	}
+	sum := sha256.Sum256([]byte("id,value\n1,2\n"))
+	if info.ContentType != "text/csv" || info.Size != 13 || info.Checksum != "sha256:"+hex.EncodeToString(sum[:]) {
+		t.Errorf("Unexpected result info %+v", info)
+	}
+	if string(info.ChecksumBytes()) != string(sum[:]) {
+		t.Errorf("ChecksumBytes() = %x, expected %x", info.ChecksumBytes(), sum)
+	}
+
+	// Повторная запись заменяет результат целиком
+	if _, err := store.Put(taskId, "text/plain", strings.NewReader("v2")); err != nil {
+		t.Fatalf("Put() returned error: %v", err)
+	}
+	content, err := store.Open(taskId)
+	if err != nil {
+		t.Fatalf("Open() returned error: %v", err)
+	}
+	data, _ := io.ReadAll(content)
+	content.Close()
+	if string(data) != "v2" {
+		t.Errorf("Expected replaced content, got %q", data)
+	}
+
+	if err := store.Delete(taskId); err != nil {
+		t.Errorf("Delete() returned error: %v", err)
+	}
+	if _, err := store.Open(taskId); !errors.Is(err, ErrResultNotFound) {
+		t.Errorf("Expected ErrResultNotFound after Delete, got %v", err)
+	}
+	if err := store.Delete(taskId); err != nil {
+		t.Errorf("Delete() of missing result returned error: %v", err)
+	}
+
+	// id задачи не может выйти за пределы каталога
+	if _, err := store.Put("../escape", "text/plain", strings.NewReader("x")); !errors.Is(err, ErrResultNotFound) {
+		t.Errorf("Expected ErrResultNotFound for invalid task id, got %v", err)
+	}
+}
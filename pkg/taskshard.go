@@ -0,0 +1,316 @@
+package pkg
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// taskSet - множество id задач во вторичном индексе
+type taskSet map[string]struct{}
+
+// createdEntry - позиция задачи в индексе по времени создания; при равном времени
+// задачи упорядочены по порядку добавления (seq)
+type createdEntry struct {
+	at  time.Time
+	seq uint64
+	id  string
+}
+
+func (e createdEntry) before(other createdEntry) bool {
+	if !e.at.Equal(other.at) {
+		return e.at.Before(other.at)
+	}
+	return e.seq < other.seq
+}
+
+// listedTask - задача с порядковым номером для слияния выборок шардов. Сохраненная задача
+// не меняется на месте (каждое изменение кладет в шард новую копию), поэтому указатель
+// можно держать и после снятия блокировки
+type listedTask struct {
+	task *InternalTask
+	seq  uint64
+}
+
+// shard - часть задач хранилища под своей блокировкой со вторичными индексами
+// по статусу, владельцу, пространству имен и времени создания. Индексы обновляются вместе с задачей
+// под той же блокировкой, поэтому всегда согласованы с ней
+type shard struct {
+	mu    sync.RWMutex
+	tasks map[string]*InternalTask
+	seqs  map[string]uint64
+
+	byStatus  map[string]taskSet
+	byOwner   map[string]taskSet
+	byCreated []createdEntry
+
+	// Задачи пространств имен и их число по статусам - для лимитов без обхода задач
+	byNamespace map[string]taskSet
+	nsStatus    map[string]map[string]int
+
+	// Число задач владельцев по статусам - для лимитов принципалов без обхода задач
+	ownerStatus map[string]map[string]int
+}
+
+func newShard() *shard {
+	return &shard{
+		tasks:    make(map[string]*InternalTask),
+		seqs:     make(map[string]uint64),
+		byStatus: make(map[string]taskSet),
+		byOwner:  make(map[string]taskSet),
+
+		byNamespace: make(map[string]taskSet),
+		nsStatus:    make(map[string]map[string]int),
+		ownerStatus: make(map[string]map[string]int),
+	}
+}
+
+// shardIndex - Номер шарда для id задачи (FNV-1a); mask - число шардов минус один
+func shardIndex(id string, mask uint32) uint32 {
+	h := uint32(2166136261)
+	for i := 0; i < len(id); i++ {
+		h ^= uint32(id[i])
+		h *= 16777619
+	}
+	return h & mask
+}
+
+// insert - Добавить задачу с порядковым номером seq (вызывается под блокировкой)
+func (sh *shard) insert(task *InternalTask, seq uint64) {
+	sh.tasks[task.Id] = task
+	sh.seqs[task.Id] = seq
+	sh.index(task, seq)
+}
+
+// remove - Удалить задачу из шарда и индексов (вызывается под блокировкой)
+func (sh *shard) remove(id string) {
+	task, exists := sh.tasks[id]
+	if !exists {
+		return
+	}
+	sh.unindex(task, sh.seqs[id])
+	delete(sh.tasks, id)
+	delete(sh.seqs, id)
+}
+
+// put - Заменить задачу новой версией, обновив индексы по измененным полям (вызывается под блокировкой)
+func (sh *shard) put(current, task *InternalTask) {
+	if current.Status != task.Status || current.Owner != task.Owner || current.Namespace != task.Namespace ||
+		!current.CreatedAt.Equal(task.CreatedAt) {
+		seq := sh.seqs[task.Id]
+		sh.unindex(current, seq)
+		sh.index(task, seq)
+	}
+	sh.tasks[task.Id] = task
+}
+
+func (sh *shard) index(task *InternalTask, seq uint64) {
+	addToSet(sh.byStatus, task.Status, task.Id)
+	addToSet(sh.byOwner, task.Owner, task.Id)
+	addToSet(sh.byNamespace, task.Namespace, task.Id)
+	addToCount(sh.nsStatus, task.Namespace, task.Status)
+	addToCount(sh.ownerStatus, task.Owner, task.Status)
+
+	entry := createdEntry{at: task.CreatedAt, seq: seq, id: task.Id}
+	i := sort.Search(len(sh.byCreated), func(i int) bool { return entry.before(sh.byCreated[i]) })
+	sh.byCreated = append(sh.byCreated, createdEntry{})
+	copy(sh.byCreated[i+1:], sh.byCreated[i:])
+	sh.byCreated[i] = entry
+}
+
+func (sh *shard) unindex(task *InternalTask, seq uint64) {
+	removeFromSet(sh.byStatus, task.Status, task.Id)
+	removeFromSet(sh.byOwner, task.Owner, task.Id)
+	removeFromSet(sh.byNamespace, task.Namespace, task.Id)
+	removeFromCount(sh.nsStatus, task.Namespace, task.Status)
+	removeFromCount(sh.ownerStatus, task.Owner, task.Status)
+
+	entry := createdEntry{at: task.CreatedAt, seq: seq}
+	i := sort.Search(len(sh.byCreated), func(i int) bool { return !sh.byCreated[i].before(entry) })
+	if i < len(sh.byCreated) && sh.byCreated[i].id == task.Id {
+		sh.byCreated = append(sh.byCreated[:i], sh.byCreated[i+1:]...)
+	}
+}
+
+func addToSet(index map[string]taskSet, key, id string) {
+	set, ok := index[key]
+	if !ok {
+		set = make(taskSet)
+		index[key] = set
+	}
+	set[id] = struct{}{}
+}
+
+func removeFromSet(index map[string]taskSet, key, id string) {
+	if set, ok := index[key]; ok {
+		delete(set, id)
+		if len(set) == 0 {
+			delete(index, key)
+		}
+	}
+}
+
+func addToCount(index map[string]map[string]int, key, status string) {
+	counts, ok := index[key]
+	if !ok {
+		counts = make(map[string]int)
+		index[key] = counts
+	}
+	counts[status]++
+}
+
+func removeFromCount(index map[string]map[string]int, key, status string) {
+	if counts := index[key]; counts != nil {
+		if counts[status]--; counts[status] <= 0 {
+			delete(counts, status)
+		}
+		if len(counts) == 0 {
+			delete(index, key)
+		}
+	}
+}
+
+// count - Число задач в статусах (вызывается под блокировкой)
+func (sh *shard) count(statuses []string) (count int) {
+	for _, status := range statuses {
+		count += len(sh.byStatus[status])
+	}
+	return
+}
+
+// countNamespace - Число задач пространства имен в статусах; без статусов - все задачи
+// пространства (вызывается под блокировкой)
+func (sh *shard) countNamespace(namespace string, statuses []string) (count int) {
+	if len(statuses) == 0 {
+		return len(sh.byNamespace[namespace])
+	}
+	counts := sh.nsStatus[namespace]
+	for _, status := range statuses {
+		count += counts[status]
+	}
+	return
+}
+
+// countOwner - Число задач владельца в статусах; "" - задачи без владельца
+// (вызывается под блокировкой)
+func (sh *shard) countOwner(owner string, statuses []string) (count int) {
+	counts := sh.ownerStatus[owner]
+	for _, status := range statuses {
+		count += counts[status]
+	}
+	return
+}
+
+// ownerStartTimes - Время запуска задач владельца в статусах: обходится меньший из индексов
+// по владельцу и по статусам (вызывается под блокировкой)
+func (sh *shard) ownerStartTimes(owner string, statuses []string, times []time.Time) []time.Time {
+	if sh.countOwner(owner, statuses) == 0 {
+		return times
+	}
+	if owned := sh.byOwner[owner]; len(owned) < sh.count(statuses) {
+		filter := TaskFilter{Statuses: statuses}
+		for id := range owned {
+			if task := sh.tasks[id]; filter.Matches(*task) {
+				times = append(times, task.StartedAt)
+			}
+		}
+		return times
+	}
+	for _, status := range statuses {
+		for id := range sh.byStatus[status] {
+			if task := sh.tasks[id]; task.Owner == owner {
+				times = append(times, task.StartedAt)
+			}
+		}
+	}
+	return times
+}
+
+// list - Задачи шарда под фильтр в порядке создания, не больше filter.Limit.
+// Кандидаты берутся из самого узкого индекса: по статусам, владельцу, пространству имен или диапазону
+// времени создания, либо, если с лимитом так дешевле, диапазон обходится по порядку;
+// остальные условия проверяются для каждого кандидата (вызывается под блокировкой)
+func (sh *shard) list(filter TaskFilter, statuses []string) []listedTask {
+	lo, hi := 0, len(sh.byCreated)
+	if !filter.CreatedAfter.IsZero() {
+		lo = sort.Search(len(sh.byCreated), func(i int) bool { return sh.byCreated[i].at.After(filter.CreatedAfter) })
+	}
+	if !filter.CreatedBefore.IsZero() {
+		hi = sort.Search(len(sh.byCreated), func(i int) bool { return !sh.byCreated[i].at.Before(filter.CreatedBefore) })
+	}
+	if lo >= hi {
+		return nil
+	}
+
+	var candidates []taskSet
+	size := hi - lo
+	if len(statuses) > 0 {
+		if n := sh.count(statuses); n < size {
+			size = n
+			for _, status := range statuses {
+				if set := sh.byStatus[status]; len(set) > 0 {
+					candidates = append(candidates, set)
+				}
+			}
+		}
+	}
+	if filter.Owner != "" {
+		if set := sh.byOwner[filter.Owner]; len(set) < size {
+			size = len(set)
+			candidates = []taskSet{set}
+		}
+	}
+	if filter.Namespace != "" {
+		if set := sh.byNamespace[filter.Namespace]; len(set) < size {
+			size = len(set)
+			candidates = []taskSet{set}
+		}
+	}
+	if size == 0 {
+		return nil
+	}
+	// С лимитом обход диапазона по порядку останавливается примерно через
+	// Limit * (hi-lo) / size задач - это дешевле, чем отсортировать всех кандидатов индекса
+	if filter.Limit > 0 && filter.Limit*(hi-lo) < size*size {
+		candidates = nil
+	}
+
+	var result []listedTask
+	if candidates == nil {
+		// Диапазон индекса по времени создания уже упорядочен: можно остановиться на лимите
+		for _, entry := range sh.byCreated[lo:hi] {
+			task := sh.tasks[entry.id]
+			if filter.Matches(*task) {
+				result = append(result, listedTask{task: task, seq: entry.seq})
+				if filter.Limit > 0 && len(result) == filter.Limit {
+					break
+				}
+			}
+		}
+		return result
+	}
+
+	for _, set := range candidates {
+		for id := range set {
+			task := sh.tasks[id]
+			if filter.Matches(*task) {
+				result = append(result, listedTask{task: task, seq: sh.seqs[id]})
+			}
+		}
+	}
+	sortListed(result)
+	if filter.Limit > 0 && len(result) > filter.Limit {
+		result = result[:filter.Limit]
+	}
+	return result
+}
+
+// before - Идет ли задача раньше other: по времени создания, затем по порядку добавления
+func (t listedTask) before(other listedTask) bool {
+	return createdEntry{at: t.task.CreatedAt, seq: t.seq}.before(createdEntry{at: other.task.CreatedAt, seq: other.seq})
+}
+
+// sortListed - Упорядочить задачи по времени создания, затем по порядку добавления
+func sortListed(tasks []listedTask) {
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].before(tasks[j]) })
+}
@@ -2,42 +2,240 @@ package pkg
 
 import (
 	"fmt"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-// TaskStore хранит задачи в памяти
+// scanBatchSize - сколько задач Scan копирует за одну блокировку
+const scanBatchSize = 256
+
+// defaultShards - число шардов хранилища по умолчанию
+const defaultShards = 32
+
+// orderEntry - позиция задачи в порядке добавления
+type orderEntry struct {
+	seq uint64
+	id  string
+}
+
+// TaskStore хранит задачи в памяти, разбитыми по шардам: у каждого шарда своя блокировка
+// и индексы по статусу, владельцу, пространству имен и времени создания, поэтому фильтрованные списки и счетчики
+// не сканируют все задачи. Блокировки берутся в порядке: шарды по возрастанию номера, затем orderMu
 type TaskStore struct {
-	mu    sync.RWMutex
-	tasks map[string]*InternalTask
+	shards []*shard
+	mask   uint32
+	clock  Clock
+
+	// Порядок добавления для потокового обхода: удаленные задачи вычищаются из order лениво,
+	// актуальная позиция задачи - seqs[id]
+	orderMu sync.RWMutex
+	order   []orderEntry
+	seqs    map[string]uint64
+	nextSeq uint64
+	removed int
+
+	// Подписки на изменения (Watch): список заменяется целиком под watchMu,
+	// публикация читает его без блокировки
+	watchMu  sync.Mutex
+	watchers atomic.Pointer[[]*watcher]
+	eventSeq atomic.Uint64
+}
+
+// StoreOption - параметр настройки хранилища
+type StoreOption func(*TaskStore)
+
+// WithClock - Источник времени для CreatedAt
+func WithClock(clock Clock) StoreOption {
+	return func(s *TaskStore) {
+		s.clock = clock
+	}
+}
+
+// WithShards - Число шардов (округляется вверх до степени двойки); по умолчанию defaultShards
+func WithShards(n int) StoreOption {
+	return func(s *TaskStore) {
+		shards := 1
+		for shards < n {
+			shards <<= 1
+		}
+		s.shards = make([]*shard, shards)
+	}
 }
 
 // NewTaskStore создает новое хранилище задач
-func NewTaskStore() *TaskStore {
-	return &TaskStore{
-		tasks: make(map[string]*InternalTask),
+func NewTaskStore(opts ...StoreOption) *TaskStore {
+	s := &TaskStore{
+		shards: make([]*shard, defaultShards),
+		seqs:   make(map[string]uint64),
+		clock:  RealClock,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	for i := range s.shards {
+		s.shards[i] = newShard()
 	}
+	s.mask = uint32(len(s.shards) - 1)
+	return s
+}
+
+// shardFor - Шард, в котором хранится задача
+func (s *TaskStore) shardFor(taskId string) *shard {
+	return s.shards[shardIndex(taskId, s.mask)]
 }
 
 // GetTasks - Получить список всех задач
 func (s *TaskStore) GetTasks() (tasks []InternalTask) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	for _, sh := range s.shards {
+		sh.mu.RLock()
+		for _, task := range sh.tasks {
+			tasks = append(tasks, *task)
+		}
+		sh.mu.RUnlock()
+	}
+	if tasks == nil {
+		tasks = []InternalTask{}
+	}
+	return
+}
+
+// ListTasks - Задачи под фильтр в порядке создания (при равном времени - в порядке добавления),
+// не больше filter.Limit. Каждый шард выбирает задачи по самому узкому из своих индексов,
+// выборки шардов сливаются. Шарды блокируются по очереди, поэтому список - не единый снимок
+// хранилища: задачи, измененные во время выборки, могут попасть в него в любой из версий
+func (s *TaskStore) ListTasks(filter TaskFilter) []InternalTask {
+	statuses := uniqueStatuses(filter.Statuses)
+
+	parts := make([][]listedTask, 0, len(s.shards))
+	total := 0
+	for _, sh := range s.shards {
+		sh.mu.RLock()
+		part := sh.list(filter, statuses)
+		sh.mu.RUnlock()
+		if len(part) > 0 {
+			parts = append(parts, part)
+			total += len(part)
+		}
+	}
+	if filter.Limit > 0 && total > filter.Limit {
+		total = filter.Limit
+	}
+
+	// Выборки шардов уже упорядочены: сливаем их, пока не наберется лимит
+	tasks := make([]InternalTask, 0, total)
+	for len(tasks) < total {
+		next := 0
+		for i := 1; i < len(parts); i++ {
+			if parts[i][0].before(parts[next][0]) {
+				next = i
+			}
+		}
+		tasks = append(tasks, *parts[next][0].task)
+		if parts[next] = parts[next][1:]; len(parts[next]) == 0 {
+			parts = append(parts[:next], parts[next+1:]...)
+		}
+	}
+	return tasks
+}
+
+// CountTasks - Посчитать задачи в указанных статусах (по индексу, без обхода задач)
+func (s *TaskStore) CountTasks(statuses ...string) (count int) {
+	statuses = uniqueStatuses(statuses)
+	for _, sh := range s.shards {
+		sh.mu.RLock()
+		count += sh.count(statuses)
+		sh.mu.RUnlock()
+	}
+	return
+}
 
-	tasks = make([]InternalTask, 0, len(s.tasks))
-	for _, task := range s.tasks {
-		tasks = append(tasks, *task)
+// CountNamespaceTasks - Посчитать задачи пространства имен в указанных статусах
+// (без статусов - все задачи пространства) по индексу, без обхода задач
+func (s *TaskStore) CountNamespaceTasks(namespace string, statuses ...string) (count int) {
+	statuses = uniqueStatuses(statuses)
+	for _, sh := range s.shards {
+		sh.mu.RLock()
+		count += sh.countNamespace(namespace, statuses)
+		sh.mu.RUnlock()
 	}
+	return
+}
 
+// CountOwnerTasks - Посчитать задачи владельца в указанных статусах по индексу, без обхода задач.
+// owner == "" - только задачи без владельца
+func (s *TaskStore) CountOwnerTasks(owner string, statuses ...string) (count int) {
+	statuses = uniqueStatuses(statuses)
+	for _, sh := range s.shards {
+		sh.mu.RLock()
+		count += sh.countOwner(owner, statuses)
+		sh.mu.RUnlock()
+	}
 	return
 }
 
+// OwnerStartTimes - Время запуска (StartedAt) задач владельца в указанных статусах, без копирования
+// задач. owner == "" - только задачи без владельца
+func (s *TaskStore) OwnerStartTimes(owner string, statuses ...string) (times []time.Time) {
+	statuses = uniqueStatuses(statuses)
+	for _, sh := range s.shards {
+		sh.mu.RLock()
+		times = sh.ownerStartTimes(owner, statuses, times)
+		sh.mu.RUnlock()
+	}
+	return
+}
+
+// Namespaces - Пространства имен, в которых есть задачи, по имени (без лимитов)
+func (s *TaskStore) Namespaces() []NamespaceInfo {
+	byName := map[string]*NamespaceInfo{}
+	for _, sh := range s.shards {
+		sh.mu.RLock()
+		for namespace, tasks := range sh.byNamespace {
+			info, ok := byName[namespace]
+			if !ok {
+				info = &NamespaceInfo{Name: namespace}
+				byName[namespace] = info
+			}
+			info.StoredTasks += len(tasks)
+			info.ActiveTasks += sh.countNamespace(namespace, []string{TaskStatusPending, TaskStatusRunning})
+		}
+		sh.mu.RUnlock()
+	}
+
+	namespaces := make([]NamespaceInfo, 0, len(byName))
+	for _, info := range byName {
+		namespaces = append(namespaces, *info)
+	}
+	sort.Slice(namespaces, func(i, j int) bool { return namespaces[i].Name < namespaces[j].Name })
+	return namespaces
+}
+
+// uniqueStatuses - Статусы без повторов, чтобы не считать задачи дважды
+func uniqueStatuses(statuses []string) []string {
+	unique := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		duplicate := false
+		for _, seen := range unique {
+			if seen == status {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			unique = append(unique, status)
+		}
+	}
+	return unique
+}
+
 // CreateTask - Создать новую задачу (только сохранение в хранилище)
-func (s *TaskStore) CreateTask(taskName string) (task InternalTask, err error) {
+func (s *TaskStore) CreateTask(taskName string, opts ...TaskOption) (task InternalTask, err error) {
 	if taskName == "" {
-		err = fmt.Errorf("%s", TaskErrorNameRequired)
+		err = ErrNameRequired
 		return
 	}
 
@@ -45,12 +243,26 @@ func (s *TaskStore) CreateTask(taskName string) (task InternalTask, err error) {
 		Id:        uuid.New().String(),
 		Name:      taskName,
 		Status:    TaskStatusPending,
-		CreatedAt: time.Now(),
+		CreatedAt: s.clock.Now(),
+		Namespace: DefaultNamespace,
+		Version:   1,
+	}
+	for _, opt := range opts {
+		opt(&newTask)
+	}
+	if err = ValidateNamespace(newTask.Namespace); err != nil {
+		return
+	}
+	if err = newTask.validateCallback(); err != nil {
+		return
 	}
+	newTask.History = []TaskTransition{{To: TaskStatusPending, At: newTask.CreatedAt, Reason: "created"}}
 
-	s.mu.Lock()
-	s.tasks[newTask.Id] = &newTask
-	s.mu.Unlock()
+	sh := s.shardFor(newTask.Id)
+	sh.mu.Lock()
+	sh.insert(&newTask, s.appendOrder(newTask.Id))
+	s.publish(TaskEventCreated, nil, &newTask)
+	sh.mu.Unlock()
 	task = newTask
 
 	return
@@ -58,40 +270,322 @@ func (s *TaskStore) CreateTask(taskName string) (task InternalTask, err error) {
 
 // GetTask - Получить задачу по ID
 func (s *TaskStore) GetTask(taskId string) (task InternalTask, err error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	sh := s.shardFor(taskId)
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
 
-	targetTask, exists := s.tasks[taskId]
+	targetTask, exists := sh.tasks[taskId]
 	if !exists {
-		err = fmt.Errorf("%s", TaskErrorNotFound)
+		err = ErrTaskNotFound
 		return
 	}
 	task = *targetTask
 	return
 }
 
-// UpdateTask - Обновить задачу
+// UpdateTask - Обновить задачу безусловно, поверх любых параллельных изменений.
+// Статус так не меняется (только через Transition), пространство имен и история переходов сохраняются
 func (s *TaskStore) UpdateTask(task InternalTask) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	sh := s.shardFor(task.Id)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
 
-	if _, exists := s.tasks[task.Id]; !exists {
-		return fmt.Errorf("%s", TaskErrorNotFound)
+	current, exists := sh.tasks[task.Id]
+	if !exists {
+		return ErrTaskNotFound
 	}
+	_, err := s.replace(sh, current, task)
+	return err
+}
 
-	s.tasks[task.Id] = &task
-	return nil
+// CompareAndSwap - Обновить задачу, только если ее версия в хранилище все еще task.Version
+// (задача не менялась с момента чтения), иначе - ErrVersionConflict без изменения задачи.
+// Возвращает сохраненную задачу с новой версией
+func (s *TaskStore) CompareAndSwap(task InternalTask) (InternalTask, error) {
+	sh := s.shardFor(task.Id)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	current, exists := sh.tasks[task.Id]
+	if !exists {
+		return InternalTask{}, ErrTaskNotFound
+	}
+	if current.Version != task.Version {
+		return *current, fmt.Errorf("%w: version %d, expected %d", ErrVersionConflict, current.Version, task.Version)
+	}
+	return s.replace(sh, current, task)
+}
+
+// replace - Заменить задачу новой версией: статус и пространство имен не меняются,
+// история сохраняется (вызывается под блокировкой шарда)
+func (s *TaskStore) replace(sh *shard, current *InternalTask, task InternalTask) (InternalTask, error) {
+	if task.Status != current.Status {
+		return *current, fmt.Errorf("%w: %s -> %s outside of Transition", ErrIllegalTransition, current.Status, task.Status)
+	}
+	task.Namespace = current.Namespace
+	task.History = current.History
+	task.Version = current.Version + 1
+	sh.put(current, &task)
+	s.publish(TaskEventUpdated, current, &task)
+	return task, nil
+}
+
+// ModifyTask - Атомарно изменить задачу: fn получает текущую версию под блокировкой.
+// Изменение статуса отклоняется (только через Transition), пространство имен и история переходов сохраняются
+func (s *TaskStore) ModifyTask(taskId string, fn func(task *InternalTask)) (task InternalTask, err error) {
+	sh := s.shardFor(taskId)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	current, exists := sh.tasks[taskId]
+	if !exists {
+		err = ErrTaskNotFound
+		return
+	}
+
+	task = *current
+	fn(&task)
+	task.Id = taskId
+	return s.replace(sh, current, task)
+}
+
+// Transition - Атомарно перевести задачу в статус to и записать переход в историю.
+// Недопустимый переход (см. CanTransition) отклоняется ErrIllegalTransition без изменения задачи.
+// fn (может быть nil) дополняет задачу под той же блокировкой - время, результат, ошибка;
+// at - время перехода
+func (s *TaskStore) Transition(taskId, to, reason string, fn func(task *InternalTask, at time.Time)) (task InternalTask, err error) {
+	sh := s.shardFor(taskId)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	current, exists := sh.tasks[taskId]
+	if !exists {
+		err = ErrTaskNotFound
+		return
+	}
+	if !CanTransition(current.Status, to) {
+		err = fmt.Errorf("%w: %s -> %s", ErrIllegalTransition, current.Status, to)
+		return *current, err
+	}
+
+	at := s.clock.Now()
+	task = *current
+	task.Status = to
+	if fn != nil {
+		fn(&task, at)
+	}
+	task.Status = to
+	task.Version = current.Version + 1
+	// Слайс истории не изменяется на месте: копии задачи у читателей его разделяют
+	task.History = append(current.History[:len(current.History):len(current.History)],
+		TaskTransition{From: current.Status, To: to, At: at, Reason: reason})
+	sh.put(current, &task)
+	s.publish(TaskEventUpdated, current, &task)
+	return
 }
 
 // DeleteTask - Удалить задачу
 func (s *TaskStore) DeleteTask(taskId string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	return s.CompareAndDelete(taskId, 0)
+}
 
-	if _, exists := s.tasks[taskId]; !exists {
-		return fmt.Errorf("%s", TaskErrorNotFound)
+// CompareAndDelete - Удалить задачу, только если ее версия в хранилище равна version
+// (0 - любая версия), иначе - ErrVersionConflict
+func (s *TaskStore) CompareAndDelete(taskId string, version uint64) error {
+	sh := s.shardFor(taskId)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	current, exists := sh.tasks[taskId]
+	if !exists {
+		return ErrTaskNotFound
+	}
+	if version != 0 && current.Version != version {
+		return fmt.Errorf("%w: version %d, expected %d", ErrVersionConflict, current.Version, version)
 	}
 
-	delete(s.tasks, taskId)
+	sh.remove(taskId)
+	s.removeOrder(taskId)
+	s.publish(TaskEventDeleted, current, nil)
 	return nil
 }
+
+// ImportTasks - Атомарно загрузить задачи как есть (id, время, статусы и результаты сохраняются,
+// история переходов начинается с импорта, задачи без пространства имен попадают в DefaultNamespace).
+// Задачи с уже существующим id обрабатываются по политике conflict: при ImportConflictFail
+// и хотя бы одном конфликте ничего не загружается, а конфликтующие задачи отмечаются
+// ImportActionConflict. Задача другого пространства имен с тем же id не пропускается
+// и не перезаписывается ни при какой политике - это всегда конфликт.
+// Возвращает действие для каждой задачи
+func (s *TaskStore) ImportTasks(tasks []InternalTask, conflict string) (actions []string, err error) {
+	switch conflict {
+	case ImportConflictFail, ImportConflictSkip, ImportConflictOverwrite:
+	default:
+		return nil, fmt.Errorf("%w: unknown conflict policy %q", ErrInvalidParameters, conflict)
+	}
+
+	for _, sh := range s.shards {
+		sh.mu.Lock()
+		defer sh.mu.Unlock()
+	}
+
+	tasks = append([]InternalTask(nil), tasks...)
+	actions = make([]string, len(tasks))
+	foreign := make([]bool, len(tasks))
+	conflicts, foreignConflicts := 0, 0
+	for i := range tasks {
+		if tasks[i].Namespace == "" {
+			tasks[i].Namespace = DefaultNamespace
+		}
+		if current, exists := s.shardFor(tasks[i].Id).tasks[tasks[i].Id]; exists {
+			conflicts++
+			actions[i] = ImportActionConflict
+			if current.Namespace != tasks[i].Namespace {
+				foreign[i] = true
+				foreignConflicts++
+			}
+		}
+	}
+	if conflict == ImportConflictFail && conflicts > 0 {
+		return actions, fmt.Errorf("%w: %d of %d tasks", ErrTaskExists, conflicts, len(tasks))
+	}
+	if foreignConflicts > 0 {
+		// Политика skip/overwrite разрешила бы остальные конфликты: отмечаем только чужие задачи.
+		// Для вызывающего это обычный конфликт id: о задачах других пространств он не узнает
+		for i := range actions {
+			if !foreign[i] {
+				actions[i] = ""
+			}
+		}
+		return actions, fmt.Errorf("%w: %d of %d tasks", ErrTaskExists, foreignConflicts, len(tasks))
+	}
+
+	now := s.clock.Now()
+	for i := range tasks {
+		task := tasks[i]
+		task.History = []TaskTransition{{To: task.Status, At: now, Reason: "imported"}}
+		task.Version = 1
+		sh := s.shardFor(task.Id)
+		switch {
+		case actions[i] == "" && sh.tasks[task.Id] == nil:
+			actions[i] = ImportActionCreated
+			sh.insert(&task, s.appendOrder(task.Id))
+			s.publish(TaskEventCreated, nil, &task)
+			continue
+		case actions[i] == "":
+			// Повтор id внутри пачки: последняя копия заменяет предыдущую
+			actions[i] = ImportActionCreated
+		case conflict == ImportConflictSkip:
+			actions[i] = ImportActionSkipped
+			continue
+		default:
+			actions[i] = ImportActionOverwritten
+			// Версия перезаписанной задачи продолжает расти: ETag старой копии не совпадет
+			task.Version = sh.tasks[task.Id].Version + 1
+		}
+		current := sh.tasks[task.Id]
+		sh.put(current, &task)
+		s.publish(TaskEventUpdated, current, &task)
+	}
+	return actions, nil
+}
+
+// PurgeNamespace - Атомарно удалить все задачи пространства имен. Возвращает id удаленных задач
+func (s *TaskStore) PurgeNamespace(namespace string) (ids []string) {
+	for _, sh := range s.shards {
+		sh.mu.Lock()
+		defer sh.mu.Unlock()
+	}
+
+	for _, sh := range s.shards {
+		for id := range sh.byNamespace[namespace] {
+			current := sh.tasks[id]
+			sh.remove(id)
+			s.removeOrder(id)
+			s.publish(TaskEventDeleted, current, nil)
+			ids = append(ids, id)
+		}
+	}
+	return
+}
+
+// Scan - Обойти задачи в порядке добавления, не собирая их в память целиком.
+// Задачи копируются пачками по scanBatchSize под короткой блокировкой, fn вызывается без нее:
+// задачи, добавленные во время обхода, тоже будут пройдены, удаленные - пропущены.
+// fn возвращает false, чтобы остановить обход
+func (s *TaskStore) Scan(fn func(task InternalTask) bool) {
+	var after uint64
+	entries := make([]orderEntry, 0, scanBatchSize)
+	batch := make([]InternalTask, 0, scanBatchSize)
+	for {
+		entries = entries[:0]
+		s.orderMu.RLock()
+		// Позицию ищем по seq, а не по индексу: order мог быть уплотнен между пачками
+		i := sort.Search(len(s.order), func(i int) bool { return s.order[i].seq > after })
+		for ; i < len(s.order) && len(entries) < scanBatchSize; i++ {
+			entry := s.order[i]
+			after = entry.seq
+			if s.seqs[entry.id] == entry.seq {
+				entries = append(entries, entry)
+			}
+		}
+		more := i < len(s.order)
+		s.orderMu.RUnlock()
+
+		// Задача могла быть удалена (или удалена и импортирована заново) после чтения порядка
+		batch = batch[:0]
+		for _, entry := range entries {
+			sh := s.shardFor(entry.id)
+			sh.mu.RLock()
+			if task, exists := sh.tasks[entry.id]; exists && sh.seqs[entry.id] == entry.seq {
+				batch = append(batch, *task)
+			}
+			sh.mu.RUnlock()
+		}
+
+		for _, task := range batch {
+			if !fn(task) {
+				return
+			}
+		}
+		if !more {
+			return
+		}
+	}
+}
+
+// appendOrder - Добавить задачу в конец порядка обхода и вернуть ее позицию
+// (вызывается под блокировкой шарда задачи)
+func (s *TaskStore) appendOrder(taskId string) uint64 {
+	s.orderMu.Lock()
+	defer s.orderMu.Unlock()
+
+	s.nextSeq++
+	s.order = append(s.order, orderEntry{seq: s.nextSeq, id: taskId})
+	s.seqs[taskId] = s.nextSeq
+	return s.nextSeq
+}
+
+// removeOrder - Убрать задачу из порядка обхода (вызывается под блокировкой шарда задачи)
+func (s *TaskStore) removeOrder(taskId string) {
+	s.orderMu.Lock()
+	defer s.orderMu.Unlock()
+
+	delete(s.seqs, taskId)
+	s.removed++
+	if s.removed > len(s.order)/2 {
+		s.compactOrder()
+	}
+}
+
+// compactOrder - Убрать из порядка обхода удаленные задачи (вызывается под orderMu)
+func (s *TaskStore) compactOrder() {
+	order := make([]orderEntry, 0, len(s.seqs))
+	for _, entry := range s.order {
+		if s.seqs[entry.id] == entry.seq {
+			order = append(order, entry)
+		}
+	}
+	s.order = order
+	s.removed = 0
+}
@@ -0,0 +1,205 @@
+package pkg
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// benchTasks - сколько задач загружено в хранилище перед замером
+const benchTasks = 100_000
+
+// legacyStore - прежняя реализация хранилища для сравнения: одна RWMutex над одной map,
+// списки и счетчики - полный обход с копированием
+type legacyStore struct {
+	mu    sync.RWMutex
+	tasks map[string]*InternalTask
+}
+
+func newLegacyStore() *legacyStore {
+	return &legacyStore{tasks: make(map[string]*InternalTask)}
+}
+
+func (s *legacyStore) put(task InternalTask) {
+	s.mu.Lock()
+	s.tasks[task.Id] = &task
+	s.mu.Unlock()
+}
+
+func (s *legacyStore) GetTask(taskId string) (InternalTask, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	task, exists := s.tasks[taskId]
+	if !exists {
+		return InternalTask{}, ErrTaskNotFound
+	}
+	return *task, nil
+}
+
+func (s *legacyStore) ModifyTask(taskId string, fn func(task *InternalTask)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if current, exists := s.tasks[taskId]; exists {
+		task := *current
+		fn(&task)
+		task.Version++
+		s.tasks[taskId] = &task
+	}
+}
+
+func (s *legacyStore) ListTasks(filter TaskFilter) []InternalTask {
+	s.mu.RLock()
+	all := make([]InternalTask, 0, len(s.tasks))
+	for _, task := range s.tasks {
+		all = append(all, *task)
+	}
+	s.mu.RUnlock()
+
+	tasks := all[:0]
+	for _, task := range all {
+		if filter.Matches(task) {
+			tasks = append(tasks, task)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].CreatedAt.Before(tasks[j].CreatedAt) })
+	if filter.Limit > 0 && len(tasks) > filter.Limit {
+		tasks = tasks[:filter.Limit]
+	}
+	return tasks
+}
+
+func (s *legacyStore) CountTasks(statuses ...string) (count int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, task := range s.tasks {
+		for _, status := range statuses {
+			if task.Status == status {
+				count++
+				break
+			}
+		}
+	}
+	return
+}
+
+// benchStore - общие операции обоих хранилищ для смешанной нагрузки
+type benchStore interface {
+	GetTask(taskId string) (InternalTask, error)
+	ListTasks(filter TaskFilter) []InternalTask
+	CountTasks(statuses ...string) int
+	// write - запись: запустить ожидающую задачу или изменить поля без смены статуса
+	write(taskId string, start bool)
+}
+
+type shardedBench struct{ *TaskStore }
+
+func (s shardedBench) write(taskId string, start bool) {
+	if start {
+		s.Transition(taskId, TaskStatusRunning, "started", nil)
+		return
+	}
+	s.ModifyTask(taskId, func(task *InternalTask) { task.Result = "updated" })
+}
+
+type legacyBench struct{ *legacyStore }
+
+func (s legacyBench) write(taskId string, start bool) {
+	s.ModifyTask(taskId, func(task *InternalTask) {
+		if start && task.Status == TaskStatusPending {
+			task.Status = TaskStatusRunning
+			return
+		}
+		task.Result = "updated"
+	})
+}
+
+// benchTaskSet - Задачи для загрузки: половина выполняется, владельцы и время создания различаются
+func benchTaskSet() []InternalTask {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tasks := make([]InternalTask, benchTasks)
+	for i := range tasks {
+		status := TaskStatusPending
+		if i%2 == 0 {
+			status = TaskStatusRunning
+		}
+		tasks[i] = InternalTask{
+			Id:        fmt.Sprintf("task-%06d", i),
+			Name:      "Task",
+			Status:    status,
+			Owner:     fmt.Sprintf("user-%d", i%100),
+			CreatedAt: start.Add(time.Duration(i) * time.Millisecond),
+		}
+	}
+	return tasks
+}
+
+// runMixed - Смешанная нагрузка из параллельных горутин: 70% чтений задачи, 10% фильтрованных
+// списков (limit 100), 10% счетчиков и 10% записей
+func runMixed(b *testing.B, store benchStore, tasks []InternalTask) {
+	var seed int64
+	filter := TaskFilter{Statuses: []string{TaskStatusRunning}, Limit: 100}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		rnd := rand.New(rand.NewSource(atomic.AddInt64(&seed, 1)))
+		for pb.Next() {
+			id := tasks[rnd.Intn(len(tasks))].Id
+			switch op := rnd.Intn(10); {
+			case op < 7:
+				store.GetTask(id)
+			case op == 7:
+				store.ListTasks(filter)
+			case op == 8:
+				store.CountTasks(TaskStatusPending, TaskStatusRunning)
+			default:
+				store.write(id, rnd.Intn(2) == 0)
+			}
+		}
+	})
+}
+
+func BenchmarkTaskStoreMixed(b *testing.B) {
+	tasks := benchTaskSet()
+
+	b.Run("sharded", func(b *testing.B) {
+		store := NewTaskStore()
+		store.ImportTasks(tasks, ImportConflictFail)
+		runMixed(b, shardedBench{store}, tasks)
+	})
+	b.Run("legacy", func(b *testing.B) {
+		store := newLegacyStore()
+		for _, task := range tasks {
+			store.put(task)
+		}
+		runMixed(b, legacyBench{store}, tasks)
+	})
+}
+
+func BenchmarkTaskStoreListByOwner(b *testing.B) {
+	tasks := benchTaskSet()
+	filter := TaskFilter{Owner: "user-7", Statuses: []string{TaskStatusRunning}, Limit: 100}
+
+	b.Run("sharded", func(b *testing.B) {
+		store := NewTaskStore()
+		store.ImportTasks(tasks, ImportConflictFail)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			store.ListTasks(filter)
+		}
+	})
+	b.Run("legacy", func(b *testing.B) {
+		store := newLegacyStore()
+		for _, task := range tasks {
+			store.put(task)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			store.ListTasks(filter)
+		}
+	})
+}
@@ -1,6 +1,9 @@
 package pkg
 
 import (
+	"errors"
+	"sort"
+	"sync"
 	"testing"
 	"time"
 )
@@ -71,8 +74,8 @@ func TestUpdateTask(t *testing.T) {
 	task, _ := store.CreateTask("Test Task")
 
 	// Изменяем задачу
-	task.Status = TaskStatusRunning
-	task.StartedAt = time.Now()
+	task.Name = "Renamed Task"
+	task.History = nil
 
 	// Тест на обновление существующей задачи
 	err := store.UpdateTask(task)
@@ -80,13 +83,25 @@ func TestUpdateTask(t *testing.T) {
 		t.Fatalf("UpdateTask() returned error: %v", err)
 	}
 
-	// Проверяем, что задача обновилась
+	// Проверяем, что задача обновилась, а история сохранилась
 	updatedTask, _ := store.GetTask(task.Id)
-	if updatedTask.Status != TaskStatusRunning {
-		t.Errorf("Expected task status '%s', got '%s'", TaskStatusRunning, updatedTask.Status)
+	if updatedTask.Name != "Renamed Task" {
+		t.Errorf("Expected task name 'Renamed Task', got '%s'", updatedTask.Name)
 	}
-	if updatedTask.StartedAt.IsZero() {
-		t.Error("Task StartedAt is zero after update")
+	if len(updatedTask.History) != 1 {
+		t.Errorf("Expected history to be kept, got %+v", updatedTask.History)
+	}
+
+	// Статус меняется только через Transition
+	task.Status = TaskStatusCompleted
+	if err := store.UpdateTask(task); !errors.Is(err, ErrIllegalTransition) {
+		t.Errorf("Expected ErrIllegalTransition, got %v", err)
+	}
+	if _, err := store.ModifyTask(task.Id, func(t *InternalTask) { t.Status = TaskStatusRunning }); !errors.Is(err, ErrIllegalTransition) {
+		t.Errorf("Expected ErrIllegalTransition from ModifyTask, got %v", err)
+	}
+	if current, _ := store.GetTask(task.Id); current.Status != TaskStatusPending {
+		t.Errorf("Expected status to stay '%s', got '%s'", TaskStatusPending, current.Status)
 	}
 
 	// Тест на обновление несуществующей задачи
@@ -100,6 +115,143 @@ func TestUpdateTask(t *testing.T) {
 	}
 }
 
+func TestCompareAndSwap(t *testing.T) {
+	store := NewTaskStore()
+	task, _ := store.CreateTask("Test Task")
+	if task.Version != 1 {
+		t.Fatalf("Expected version 1 for a new task, got %d", task.Version)
+	}
+
+	// Запись по прочитанной версии проходит и увеличивает версию
+	stale := task
+	task.Name = "Renamed Task"
+	saved, err := store.CompareAndSwap(task)
+	if err != nil || saved.Version != 2 || saved.Name != "Renamed Task" {
+		t.Fatalf("CompareAndSwap() = %+v, %v", saved, err)
+	}
+
+	// Устаревшая копия отклоняется, задача не меняется
+	stale.Name = "Lost Update"
+	current, err := store.CompareAndSwap(stale)
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("Expected ErrVersionConflict, got %v", err)
+	}
+	if current.Name != "Renamed Task" || current.Version != 2 {
+		t.Errorf("Expected current task to be returned unchanged, got %+v", current)
+	}
+
+	// Любое изменение в хранилище увеличивает версию
+	running, _ := store.Transition(task.Id, TaskStatusRunning, "started", nil)
+	modified, _ := store.ModifyTask(task.Id, func(task *InternalTask) { task.Result = "partial" })
+	if running.Version != 3 || modified.Version != 4 {
+		t.Errorf("Expected versions 3 and 4, got %d and %d", running.Version, modified.Version)
+	}
+
+	if _, err := store.CompareAndSwap(InternalTask{Id: "non-existent-id"}); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("Expected ErrTaskNotFound, got %v", err)
+	}
+}
+
+func TestCompareAndDelete(t *testing.T) {
+	store := NewTaskStore()
+	task, _ := store.CreateTask("Test Task")
+	store.ModifyTask(task.Id, func(task *InternalTask) { task.Name = "Renamed Task" })
+
+	if err := store.CompareAndDelete(task.Id, task.Version); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("Expected ErrVersionConflict for stale version, got %v", err)
+	}
+	if _, err := store.GetTask(task.Id); err != nil {
+		t.Fatalf("Task must survive a conflicting delete: %v", err)
+	}
+	if err := store.CompareAndDelete(task.Id, task.Version+1); err != nil {
+		t.Errorf("CompareAndDelete() with current version returned error: %v", err)
+	}
+	if err := store.CompareAndDelete(task.Id, 0); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("Expected ErrTaskNotFound, got %v", err)
+	}
+}
+
+func TestTransition(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 15, 15, 4, 5, 0, time.UTC))
+	store := NewTaskStore(WithClock(clock))
+	task, _ := store.CreateTask("Test Task")
+
+	clock.Advance(time.Second)
+	running, err := store.Transition(task.Id, TaskStatusRunning, "started", func(task *InternalTask, at time.Time) {
+		task.StartedAt = at
+	})
+	if err != nil || running.Status != TaskStatusRunning || !running.StartedAt.Equal(clock.Now()) {
+		t.Fatalf("Transition(running) = %+v, %v", running, err)
+	}
+
+	clock.Advance(time.Minute)
+	if _, err := store.Transition(task.Id, TaskStatusCompleted, "done", nil); err != nil {
+		t.Fatalf("Transition(completed) returned error: %v", err)
+	}
+
+	// Из финального статуса переходов нет, задача не меняется
+	_, err = store.Transition(task.Id, TaskStatusRunning, "restart", func(task *InternalTask, at time.Time) {
+		task.Result = "clobbered"
+	})
+	if !errors.Is(err, ErrIllegalTransition) {
+		t.Errorf("Expected ErrIllegalTransition, got %v", err)
+	}
+
+	final, _ := store.GetTask(task.Id)
+	if final.Status != TaskStatusCompleted || final.Result != "" {
+		t.Errorf("Illegal transition should not change the task: %+v", final)
+	}
+	expected := []TaskTransition{
+		{To: TaskStatusPending, At: task.CreatedAt, Reason: "created"},
+		{From: TaskStatusPending, To: TaskStatusRunning, At: task.CreatedAt.Add(time.Second), Reason: "started"},
+		{From: TaskStatusRunning, To: TaskStatusCompleted, At: task.CreatedAt.Add(time.Second + time.Minute), Reason: "done"},
+	}
+	if len(final.History) != len(expected) {
+		t.Fatalf("Expected %d transitions, got %+v", len(expected), final.History)
+	}
+	for i := range expected {
+		if final.History[i] != expected[i] {
+			t.Errorf("Transition %d: expected %+v, got %+v", i, expected[i], final.History[i])
+		}
+	}
+	// Копия, полученная до перехода, не видит новых записей истории
+	if len(running.History) != 2 {
+		t.Errorf("Expected earlier copy to keep 2 transitions, got %d", len(running.History))
+	}
+
+	if _, err := store.Transition("non-existent-id", TaskStatusRunning, "", nil); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("Expected ErrTaskNotFound, got %v", err)
+	}
+}
+
+func TestCanTransition(t *testing.T) {
+	legal := [][2]string{
+		{TaskStatusPending, TaskStatusRunning},
+		{TaskStatusPending, TaskStatusCancelled},
+		{TaskStatusRunning, TaskStatusCompleted},
+		{TaskStatusRunning, TaskStatusFailed},
+		{TaskStatusRunning, TaskStatusCancelled},
+	}
+	illegal := [][2]string{
+		{TaskStatusPending, TaskStatusCompleted},
+		{TaskStatusRunning, TaskStatusPending},
+		{TaskStatusRunning, TaskStatusRunning},
+		{TaskStatusCompleted, TaskStatusRunning},
+		{TaskStatusCancelled, TaskStatusPending},
+		{TaskStatusFailed, TaskStatusCompleted},
+	}
+	for _, pair := range legal {
+		if !CanTransition(pair[0], pair[1]) {
+			t.Errorf("Expected %s -> %s to be legal", pair[0], pair[1])
+		}
+	}
+	for _, pair := range illegal {
+		if CanTransition(pair[0], pair[1]) {
+			t.Errorf("Expected %s -> %s to be illegal", pair[0], pair[1])
+		}
+	}
+}
+
 func TestDeleteTask(t *testing.T) {
 	store := NewTaskStore()
 
@@ -148,3 +300,342 @@ func TestGetTasks(t *testing.T) {
 		t.Errorf("Expected 3 tasks, got %d tasks", len(tasks))
 	}
 }
+
+// expectedList - Эталонный список: все задачи в порядке добавления, отфильтрованные
+// и устойчиво отсортированные по времени создания
+func expectedList(store *TaskStore, filter TaskFilter) []InternalTask {
+	var tasks []InternalTask
+	store.Scan(func(task InternalTask) bool {
+		if filter.Matches(task) {
+			tasks = append(tasks, task)
+		}
+		return true
+	})
+	sort.SliceStable(tasks, func(i, j int) bool { return tasks[i].CreatedAt.Before(tasks[j].CreatedAt) })
+	if filter.Limit > 0 && len(tasks) > filter.Limit {
+		tasks = tasks[:filter.Limit]
+	}
+	return tasks
+}
+
+func assertList(t *testing.T, store *TaskStore, filter TaskFilter) {
+	t.Helper()
+	expected := expectedList(store, filter)
+	tasks := store.ListTasks(filter)
+	if len(tasks) != len(expected) {
+		t.Fatalf("ListTasks(%+v) returned %d tasks, expected %d", filter, len(tasks), len(expected))
+	}
+	for i := range tasks {
+		if tasks[i].Id != expected[i].Id || tasks[i].Version != expected[i].Version {
+			t.Fatalf("ListTasks(%+v)[%d] = %s v%d, expected %s v%d",
+				filter, i, tasks[i].Id, tasks[i].Version, expected[i].Id, expected[i].Version)
+		}
+	}
+}
+
+func TestListTasks(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	store := NewTaskStore(WithClock(clock), WithShards(4))
+
+	// Задачи с одинаковым временем создания идут в порядке добавления
+	var ids []string
+	for i := 0; i < 200; i++ {
+		if i%3 == 0 {
+			clock.Advance(time.Second)
+		}
+		task, _ := store.CreateTask("Task", WithOwner([]string{"alice", "bob"}[i%2]))
+		ids = append(ids, task.Id)
+	}
+	// Индексы следуют за переходами, изменениями, удалениями и импортом
+	for i, id := range ids {
+		switch i % 5 {
+		case 0:
+			store.Transition(id, TaskStatusRunning, "started", nil)
+		case 1:
+			store.Transition(id, TaskStatusRunning, "started", nil)
+			store.Transition(id, TaskStatusCompleted, "finished", nil)
+		case 2:
+			store.ModifyTask(id, func(task *InternalTask) { task.Owner = "carol" })
+		case 3:
+			store.DeleteTask(id)
+		}
+	}
+	reimported, _ := store.GetTask(ids[4])
+	reimported.Status = TaskStatusFailed
+	reimported.CreatedAt = start
+	store.ImportTasks([]InternalTask{reimported}, ImportConflictOverwrite)
+
+	filters := []TaskFilter{
+		{},
+		{Limit: 10},
+		{Statuses: []string{TaskStatusRunning}},
+		{Statuses: []string{TaskStatusRunning, TaskStatusRunning}, Limit: 7},
+		{Statuses: []string{TaskStatusPending, TaskStatusFailed}},
+		{Owner: "carol"},
+		{Owner: "alice", Statuses: []string{TaskStatusCompleted}, Limit: 5},
+		{Owner: "nobody"},
+		{CreatedAfter: start.Add(10 * time.Second), CreatedBefore: start.Add(20 * time.Second)},
+		{CreatedAfter: start.Add(10 * time.Second), Statuses: []string{TaskStatusRunning}, Limit: 3},
+		{CreatedBefore: start.Add(5 * time.Second), Owner: "bob"},
+	}
+	for _, filter := range filters {
+		assertList(t, store, filter)
+	}
+
+	// Счетчики берутся из индекса: повторный статус не считается дважды
+	expected := len(expectedList(store, TaskFilter{Statuses: []string{TaskStatusPending, TaskStatusRunning}}))
+	if count := store.CountTasks(TaskStatusPending, TaskStatusRunning, TaskStatusPending); count != expected {
+		t.Errorf("Expected %d active tasks, got %d", expected, count)
+	}
+}
+
+func TestListTasksConcurrent(t *testing.T) {
+	store := NewTaskStore(WithShards(4))
+
+	// Запись, импорт, удаление, обход и списки одновременно: без гонок и взаимных блокировок,
+	// после чего индексы согласованы с задачами
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				task, _ := store.CreateTask("Task")
+				switch i % 4 {
+				case 0:
+					store.Transition(task.Id, TaskStatusRunning, "started", nil)
+				case 1:
+					store.DeleteTask(task.Id)
+				case 2:
+					task.Status = TaskStatusFailed
+					store.ImportTasks([]InternalTask{task}, ImportConflictOverwrite)
+				}
+				store.ListTasks(TaskFilter{Statuses: []string{TaskStatusRunning}, Limit: 10})
+				store.CountTasks(TaskStatusPending)
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 20; i++ {
+			store.Scan(func(task InternalTask) bool { return true })
+		}
+	}()
+	wg.Wait()
+
+	for _, status := range []string{TaskStatusPending, TaskStatusRunning, TaskStatusFailed} {
+		assertList(t, store, TaskFilter{Statuses: []string{status}})
+		if count, expected := store.CountTasks(status), len(expectedList(store, TaskFilter{Statuses: []string{status}})); count != expected {
+			t.Errorf("Expected %d %s tasks, got %d", expected, status, count)
+		}
+	}
+	assertList(t, store, TaskFilter{})
+}
+
+func TestWithShards(t *testing.T) {
+	for _, test := range []struct{ n, shards int }{{0, 1}, {1, 1}, {3, 4}, {64, 64}} {
+		store := NewTaskStore(WithShards(test.n))
+		if len(store.shards) != test.shards {
+			t.Errorf("WithShards(%d): expected %d shards, got %d", test.n, test.shards, len(store.shards))
+		}
+		task, _ := store.CreateTask("Task")
+		if _, err := store.GetTask(task.Id); err != nil {
+			t.Errorf("WithShards(%d): GetTask() returned error: %v", test.n, err)
+		}
+	}
+	if store := NewTaskStore(); len(store.shards) != defaultShards {
+		t.Errorf("Expected %d shards by default, got %d", defaultShards, len(store.shards))
+	}
+}
+
+func TestCountOwnerTasks(t *testing.T) {
+	store := NewTaskStore(WithShards(4))
+	started := time.Date(2024, 1, 15, 15, 4, 5, 0, time.UTC)
+
+	var running []string
+	for i := 0; i < 6; i++ {
+		task, _ := store.CreateTask("Alice Task", WithOwner("alice"))
+		if i%2 == 0 {
+			store.Transition(task.Id, TaskStatusRunning, "test", func(task *InternalTask, at time.Time) { task.StartedAt = started })
+			running = append(running, task.Id)
+		}
+	}
+	store.CreateTask("Bob Task", WithOwner("bob"))
+	ownerless, _ := store.CreateTask("Ownerless Task")
+
+	if count := store.CountOwnerTasks("alice", TaskStatusRunning); count != 3 {
+		t.Errorf("Expected 3 running tasks of alice, got %d", count)
+	}
+	if count := store.CountOwnerTasks("alice", TaskStatusPending, TaskStatusRunning, TaskStatusPending); count != 6 {
+		t.Errorf("Expected 6 active tasks of alice, got %d", count)
+	}
+
+	// Пустой владелец - только задачи без владельца, а не задачи всех владельцев
+	if count := store.CountOwnerTasks("", TaskStatusPending); count != 1 {
+		t.Errorf("Expected 1 pending ownerless task, got %d", count)
+	}
+	store.DeleteTask(ownerless.Id)
+	if count := store.CountOwnerTasks("", TaskStatusPending); count != 0 {
+		t.Errorf("Expected no ownerless tasks after deletion, got %d", count)
+	}
+
+	store.Transition(running[0], TaskStatusCompleted, "test", nil)
+	times := store.OwnerStartTimes("alice", TaskStatusRunning)
+	if len(times) != 2 || !times[0].Equal(started) || !times[1].Equal(started) {
+		t.Errorf("Expected 2 start times of running tasks of alice, got %v", times)
+	}
+	if times := store.OwnerStartTimes("bob", TaskStatusRunning); len(times) != 0 {
+		t.Errorf("Expected no running tasks of bob, got %v", times)
+	}
+}
+
+func TestScan(t *testing.T) {
+	store := NewTaskStore()
+
+	// Больше пачки, чтобы обход прошел через несколько блокировок
+	var ids []string
+	for i := 0; i < scanBatchSize*2+10; i++ {
+		task, _ := store.CreateTask("Task")
+		ids = append(ids, task.Id)
+	}
+	// Удаление больше половины задач уплотняет порядок обхода
+	for _, id := range ids[:scanBatchSize*2] {
+		store.DeleteTask(id)
+	}
+	ids = ids[scanBatchSize*2:]
+
+	var seen []string
+	store.Scan(func(task InternalTask) bool {
+		seen = append(seen, task.Id)
+		return true
+	})
+	if len(seen) != len(ids) {
+		t.Fatalf("Expected %d tasks, got %d", len(ids), len(seen))
+	}
+	for i := range ids {
+		if seen[i] != ids[i] {
+			t.Fatalf("Expected tasks in insertion order, got %s at %d", seen[i], i)
+		}
+	}
+
+	// Задачи, добавленные и удаленные во время обхода, учитываются
+	count := 0
+	store.Scan(func(task InternalTask) bool {
+		if count == 0 {
+			store.DeleteTask(ids[1])
+			store.CreateTask("Late Task")
+		}
+		count++
+		return true
+	})
+	if count != len(ids) {
+		t.Errorf("Expected %d tasks, got %d", len(ids), count)
+	}
+
+	// Остановка обхода
+	count = 0
+	store.Scan(func(task InternalTask) bool {
+		count++
+		return false
+	})
+	if count != 1 {
+		t.Errorf("Expected scan to stop after 1 task, got %d", count)
+	}
+}
+
+func TestImportTasks(t *testing.T) {
+	store := NewTaskStore()
+	existing, _ := store.CreateTask("Existing Task")
+
+	created := InternalTask{Id: "550e8400-e29b-41d4-a716-446655440000", Name: "Imported Task", Status: TaskStatusPending, CreatedAt: time.Now()}
+	replacement := existing
+	replacement.Name = "Replaced Task"
+
+	// При политике fail конфликт отменяет весь импорт
+	actions, err := store.ImportTasks([]InternalTask{created, replacement}, ImportConflictFail)
+	if !errors.Is(err, ErrTaskExists) || actions[0] != "" || actions[1] != ImportActionConflict {
+		t.Errorf("Expected conflict for existing task, got %v, %v", actions, err)
+	}
+	if _, err := store.GetTask(created.Id); err == nil {
+		t.Error("Nothing should be imported on conflict")
+	}
+
+	// skip оставляет существующую задачу
+	actions, err = store.ImportTasks([]InternalTask{created, replacement}, ImportConflictSkip)
+	if err != nil || actions[0] != ImportActionCreated || actions[1] != ImportActionSkipped {
+		t.Errorf("Unexpected skip import result: %v, %v", actions, err)
+	}
+	if task, _ := store.GetTask(existing.Id); task.Name != existing.Name {
+		t.Errorf("Expected existing task to be kept, got '%s'", task.Name)
+	}
+
+	// overwrite заменяет задачу, не меняя порядок обхода
+	actions, err = store.ImportTasks([]InternalTask{replacement}, ImportConflictOverwrite)
+	if err != nil || actions[0] != ImportActionOverwritten {
+		t.Errorf("Unexpected overwrite import result: %v, %v", actions, err)
+	}
+	var names []string
+	store.Scan(func(task InternalTask) bool {
+		names = append(names, task.Name)
+		return true
+	})
+	if len(names) != 2 || names[0] != "Replaced Task" || names[1] != "Imported Task" {
+		t.Errorf("Unexpected tasks after import: %v", names)
+	}
+
+	if _, err := store.ImportTasks(nil, "merge"); !errors.Is(err, ErrInvalidParameters) {
+		t.Errorf("Expected ErrInvalidParameters for unknown policy, got %v", err)
+	}
+}
+
+func TestValidateRecord(t *testing.T) {
+	start := time.Date(2024, 1, 15, 15, 4, 5, 0, time.UTC)
+	valid := InternalTask{
+		Id: "550e8400-e29b-41d4-a716-446655440000", Name: "Task", Status: TaskStatusCompleted,
+		CreatedAt: start, StartedAt: start.Add(time.Second), FinishedAt: start.Add(time.Minute), Result: "done",
+	}
+	if err := valid.ValidateRecord(); err != nil {
+		t.Errorf("ValidateRecord() returned error for valid task: %v", err)
+	}
+
+	cases := map[string]func(task *InternalTask){
+		"id is not a uuid":             func(task *InternalTask) { task.Id = "42" },
+		"name is required":             func(task *InternalTask) { task.Name = "" },
+		"unknown status":               func(task *InternalTask) { task.Status = "done" },
+		"createdAt is required":        func(task *InternalTask) { task.CreatedAt = time.Time{} },
+		"requires finishedAt":          func(task *InternalTask) { task.FinishedAt = time.Time{} },
+		"finishedAt is before":         func(task *InternalTask) { task.FinishedAt = start },
+		"pending with startedAt":       func(task *InternalTask) { task.Status = TaskStatusPending },
+		"running with finishedAt":      func(task *InternalTask) { task.Status = TaskStatusRunning },
+		"startedAt is before creation": func(task *InternalTask) { task.StartedAt = start.Add(-time.Second) },
+		"checkpoint too large":         func(task *InternalTask) { task.Checkpoint = make([]byte, MaxCheckpointSize+1) },
+		"checkpoint without time":      func(task *InternalTask) { task.Checkpoint = []byte("{}") },
+	}
+	for name, modify := range cases {
+		task := valid
+		modify(&task)
+		if err := task.ValidateRecord(); !errors.Is(err, ErrInvalidTaskRecord) {
+			t.Errorf("%s: expected ErrInvalidTaskRecord, got %v", name, err)
+		}
+	}
+}
+
+func TestElapsed(t *testing.T) {
+	start := time.Date(2024, 1, 15, 15, 4, 5, 0, time.UTC)
+	now := start.Add(time.Hour)
+
+	task := InternalTask{CreatedAt: start}
+	if elapsed := task.Elapsed(now); elapsed != 0 {
+		t.Errorf("Expected 0 for a task that has not started, got %s", elapsed)
+	}
+	task.StartedAt = start.Add(time.Second)
+	if elapsed := task.Elapsed(now); elapsed != time.Hour-time.Second {
+		t.Errorf("Expected elapsed up to now for a running task, got %s", elapsed)
+	}
+	task.FinishedAt = start.Add(time.Minute)
+	if elapsed := task.Elapsed(now); elapsed != 59*time.Second {
+		t.Errorf("Expected elapsed up to FinishedAt, got %s", elapsed)
+	}
+}
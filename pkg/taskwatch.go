@@ -0,0 +1,216 @@
+package pkg
+
+import (
+	"context"
+	"sync"
+)
+
+// Типы событий изменения задач
+const (
+	TaskEventCreated = "created"
+	TaskEventUpdated = "updated"
+	TaskEventDeleted = "deleted"
+	// TaskEventGap - подписчик не успевал читать и пропустил Dropped событий;
+	// состояние задач нужно перечитать (например, ListTasks)
+	TaskEventGap = "gap"
+)
+
+// Политики для подписчика, который не успевает читать события
+const (
+	// SlowConsumerDrop - события, не поместившиеся в буфер, отбрасываются,
+	// перед следующим доставленным событием приходит TaskEventGap
+	SlowConsumerDrop = "drop"
+	// SlowConsumerDisconnect - при переполнении буфера подписка закрывается
+	SlowConsumerDisconnect = "disconnect"
+)
+
+// defaultWatchBuffer - размер буфера подписки по умолчанию
+const defaultWatchBuffer = 256
+
+// TaskEvent - изменение задачи в хранилище. Before - задача до изменения (nil для created),
+// After - после (nil для deleted). Снимки общие для всех подписчиков и не должны изменяться.
+// Seq - номер события в хранилище: события одной задачи приходят в порядке изменений
+// с растущим Seq, события разных задач могут прийти не по порядку Seq
+type TaskEvent struct {
+	Type    string
+	Seq     uint64
+	Before  *InternalTask
+	After   *InternalTask
+	Dropped int
+}
+
+// Task - Задача события: состояние после изменения, для удаления - последнее состояние
+func (e TaskEvent) Task() *InternalTask {
+	if e.After != nil {
+		return e.After
+	}
+	return e.Before
+}
+
+// WatchOption - параметр подписки на изменения
+type WatchOption func(*watcher)
+
+// WithWatchBuffer - Размер буфера событий подписки; по умолчанию defaultWatchBuffer
+func WithWatchBuffer(n int) WatchOption {
+	return func(w *watcher) {
+		if n > 0 {
+			w.buffer = n
+		}
+	}
+}
+
+// WithSlowConsumer - Политика при переполнении буфера: SlowConsumerDrop (по умолчанию)
+// или SlowConsumerDisconnect
+func WithSlowConsumer(policy string) WatchOption {
+	return func(w *watcher) {
+		w.policy = policy
+	}
+}
+
+// watcher - подписка на изменения задач
+type watcher struct {
+	filter TaskFilter
+	buffer int
+	policy string
+
+	mu      sync.Mutex
+	ch      chan TaskEvent
+	dropped int
+	closed  bool
+	stop    func() bool
+}
+
+// matches - Касается ли событие подписки: задача подходила под фильтр до или после изменения
+func (w *watcher) matches(before, after *InternalTask) bool {
+	return (before != nil && w.filter.Matches(*before)) || (after != nil && w.filter.Matches(*after))
+}
+
+// send - Доставить событие без ожидания читателя. Возвращает false, если подписка закрыта
+// по политике SlowConsumerDisconnect и ее нужно убрать из списка
+func (w *watcher) send(event TaskEvent) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return true
+	}
+	if w.dropped > 0 {
+		select {
+		case w.ch <- TaskEvent{Type: TaskEventGap, Seq: event.Seq, Dropped: w.dropped}:
+			w.dropped = 0
+		default:
+			w.dropped++
+			return true
+		}
+	}
+	select {
+	case w.ch <- event:
+		return true
+	default:
+	}
+	if w.policy == SlowConsumerDisconnect {
+		// Канал закрывается сразу, чтобы после пропущенного события ничего не было доставлено
+		w.closed = true
+		close(w.ch)
+		return false
+	}
+	w.dropped++
+	return true
+}
+
+// close - Закрыть канал подписки (повторный вызов ничего не делает)
+func (w *watcher) close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.closed {
+		w.closed = true
+		close(w.ch)
+	}
+}
+
+// Watch - Подписаться на изменения задач, подходящих под filter (Limit не учитывается):
+// создание, изменение и удаление со снимками до и после. События доставляются без ожидания
+// подписчика через буфер ограниченного размера; если подписчик не успевает, действует политика
+// WithSlowConsumer. Канал закрывается при отмене ctx или отключении медленного подписчика
+func (s *TaskStore) Watch(ctx context.Context, filter TaskFilter, opts ...WatchOption) <-chan TaskEvent {
+	w := &watcher{filter: filter, buffer: defaultWatchBuffer, policy: SlowConsumerDrop}
+	for _, opt := range opts {
+		opt(w)
+	}
+	w.ch = make(chan TaskEvent, w.buffer)
+	w.stop = context.AfterFunc(ctx, func() {
+		s.unwatch(w)
+		w.close()
+	})
+
+	s.watchMu.Lock()
+	current := s.loadWatchers()
+	watchers := make([]*watcher, len(current), len(current)+1)
+	copy(watchers, current)
+	watchers = append(watchers, w)
+	s.watchers.Store(&watchers)
+	s.watchMu.Unlock()
+
+	// ctx мог быть отменен до добавления подписки в список
+	if ctx.Err() != nil {
+		s.unwatch(w)
+		w.close()
+	}
+	return w.ch
+}
+
+// loadWatchers - Текущие подписки (список не изменяется на месте, заменяется целиком)
+func (s *TaskStore) loadWatchers() []*watcher {
+	if watchers := s.watchers.Load(); watchers != nil {
+		return *watchers
+	}
+	return nil
+}
+
+// unwatch - Убрать подписку из списка
+func (s *TaskStore) unwatch(w *watcher) {
+	s.watchMu.Lock()
+	defer s.watchMu.Unlock()
+
+	current := s.loadWatchers()
+	watchers := make([]*watcher, 0, len(current))
+	for _, other := range current {
+		if other != w {
+			watchers = append(watchers, other)
+		}
+	}
+	s.watchers.Store(&watchers)
+}
+
+// publish - Разослать изменение задачи подписчикам (вызывается под блокировкой шарда задачи,
+// поэтому события одной задачи уходят в порядке изменений). Без подписчиков ничего не делает
+func (s *TaskStore) publish(eventType string, before, after *InternalTask) {
+	watchers := s.loadWatchers()
+	if len(watchers) == 0 {
+		return
+	}
+
+	var event *TaskEvent
+	for _, w := range watchers {
+		if !w.matches(before, after) {
+			continue
+		}
+		if event == nil {
+			// Копии снимков, чтобы подписчики не держали задачи хранилища
+			event = &TaskEvent{Type: eventType, Seq: s.eventSeq.Add(1), Before: cloneTask(before), After: cloneTask(after)}
+		}
+		if !w.send(*event) {
+			w.stop()
+			s.unwatch(w)
+		}
+	}
+}
+
+func cloneTask(task *InternalTask) *InternalTask {
+	if task == nil {
+		return nil
+	}
+	clone := *task
+	return &clone
+}
@@ -0,0 +1,145 @@
+package pkg
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// nextEvent - Прочитать событие подписки или упасть по таймауту
+func nextEvent(t *testing.T, events <-chan TaskEvent) TaskEvent {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		if !ok {
+			t.Fatal("Watch channel closed unexpectedly")
+		}
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for event")
+	}
+	return TaskEvent{}
+}
+
+func TestWatch(t *testing.T) {
+	store := NewTaskStore()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	all := store.Watch(ctx, TaskFilter{})
+	running := store.Watch(ctx, TaskFilter{Statuses: []string{TaskStatusRunning}})
+
+	task, _ := store.CreateTask("Task")
+	store.Transition(task.Id, TaskStatusRunning, "started", nil)
+	store.ModifyTask(task.Id, func(t *InternalTask) { t.Name = "Renamed" })
+	store.Transition(task.Id, TaskStatusCompleted, "finished", nil)
+	store.DeleteTask(task.Id)
+
+	expected := []struct {
+		eventType     string
+		before, after string
+	}{
+		{TaskEventCreated, "", TaskStatusPending},
+		{TaskEventUpdated, TaskStatusPending, TaskStatusRunning},
+		{TaskEventUpdated, TaskStatusRunning, TaskStatusRunning},
+		{TaskEventUpdated, TaskStatusRunning, TaskStatusCompleted},
+		{TaskEventDeleted, TaskStatusCompleted, ""},
+	}
+	var lastSeq uint64
+	for i, exp := range expected {
+		event := nextEvent(t, all)
+		if event.Type != exp.eventType || event.Task().Id != task.Id {
+			t.Fatalf("Event %d: expected %s for %s, got %s for %s", i, exp.eventType, task.Id, event.Type, event.Task().Id)
+		}
+		if (event.Before == nil) != (exp.before == "") || (event.Before != nil && event.Before.Status != exp.before) {
+			t.Errorf("Event %d: unexpected before snapshot %+v", i, event.Before)
+		}
+		if (event.After == nil) != (exp.after == "") || (event.After != nil && event.After.Status != exp.after) {
+			t.Errorf("Event %d: unexpected after snapshot %+v", i, event.After)
+		}
+		if event.Seq <= lastSeq {
+			t.Errorf("Event %d: expected seq > %d, got %d", i, lastSeq, event.Seq)
+		}
+		lastSeq = event.Seq
+	}
+
+	// Подписка по статусу видит вход в статус, изменения в нем и выход из него
+	for _, exp := range expected[1:4] {
+		if event := nextEvent(t, running); event.Type != exp.eventType || event.After.Status != exp.after {
+			t.Errorf("Expected %s to %s, got %s to %s", exp.eventType, exp.after, event.Type, event.After.Status)
+		}
+	}
+	select {
+	case event := <-running:
+		t.Errorf("Unexpected event for running watcher: %+v", event)
+	default:
+	}
+
+	// Снимки - копии: их изменение не затрагивает хранилище
+	imported := InternalTask{Id: "550e8400-e29b-41d4-a716-446655440000", Name: "Imported", Status: TaskStatusFailed}
+	store.ImportTasks([]InternalTask{imported}, ImportConflictFail)
+	event := nextEvent(t, all)
+	if event.Type != TaskEventCreated || event.After.Name != "Imported" {
+		t.Fatalf("Expected created event for import, got %+v", event)
+	}
+	event.After.Name = "Changed"
+	if stored, _ := store.GetTask(imported.Id); stored.Name != "Imported" {
+		t.Errorf("Event snapshot shares memory with the store")
+	}
+
+	// Отмена контекста закрывает каналы и убирает подписки
+	cancel()
+	for _, events := range []<-chan TaskEvent{all, running} {
+		select {
+		case _, ok := <-events:
+			if ok {
+				t.Error("Expected closed channel after cancel")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Channel not closed after cancel")
+		}
+	}
+	if watchers := store.loadWatchers(); len(watchers) != 0 {
+		t.Errorf("Expected no watchers after cancel, got %d", len(watchers))
+	}
+}
+
+func TestWatchSlowConsumer(t *testing.T) {
+	store := NewTaskStore()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// drop: лишние события отбрасываются, перед следующим приходит gap с их числом
+	events := store.Watch(ctx, TaskFilter{}, WithWatchBuffer(2))
+	for i := 0; i < 5; i++ {
+		store.CreateTask("Task")
+	}
+	nextEvent(t, events)
+	nextEvent(t, events)
+	last, _ := store.CreateTask("Last Task")
+	if gap := nextEvent(t, events); gap.Type != TaskEventGap || gap.Dropped != 3 {
+		t.Errorf("Expected gap with 3 dropped events, got %+v", gap)
+	}
+	if event := nextEvent(t, events); event.After == nil || event.After.Id != last.Id {
+		t.Errorf("Expected event for last task after gap, got %+v", event)
+	}
+
+	// disconnect: переполнение закрывает подписку, остальные подписки продолжают работать
+	slow := store.Watch(ctx, TaskFilter{}, WithWatchBuffer(1), WithSlowConsumer(SlowConsumerDisconnect))
+	store.CreateTask("Task")
+	store.CreateTask("Task")
+	nextEvent(t, slow)
+	if _, ok := <-slow; ok {
+		t.Error("Expected slow watcher to be disconnected")
+	}
+	if watchers := store.loadWatchers(); len(watchers) != 1 {
+		t.Errorf("Expected 1 watcher after disconnect, got %d", len(watchers))
+	}
+
+	// Подписка с уже отмененным контекстом сразу закрыта
+	done, stop := context.WithCancel(context.Background())
+	stop()
+	if _, ok := <-store.Watch(done, TaskFilter{}); ok {
+		t.Error("Expected closed channel for cancelled context")
+	}
+}
@@ -0,0 +1,65 @@
+package pkg
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultQuotaPeriod - период MaxTaskSeconds, если PeriodHours не задан
+	DefaultQuotaPeriod = 24 * time.Hour
+	// QuotaDay - окно MaxTasksPerDay
+	QuotaDay = 24 * time.Hour
+)
+
+// TenantQuota - лимиты принципала (владельца задач) во всех пространствах имен (0 - без лимита)
+type TenantQuota struct {
+	// MaxRunningTasks - одновременно выполняющихся задач; лишние задачи ждут в pending
+	MaxRunningTasks int `json:"maxRunningTasks,omitempty"`
+	// MaxPendingTasks - задач, ожидающих выполнения
+	MaxPendingTasks int `json:"maxPendingTasks,omitempty"`
+	// MaxTasksPerDay - созданных задач за последние 24 часа
+	MaxTasksPerDay int `json:"maxTasksPerDay,omitempty"`
+	// MaxTaskSeconds - секунд выполнения задач за период PeriodHours
+	MaxTaskSeconds int64 `json:"maxTaskSeconds,omitempty"`
+	// PeriodHours - период MaxTaskSeconds в часах (по умолчанию 24)
+	PeriodHours int `json:"periodHours,omitempty"`
+}
+
+// Validate - Проверить, что лимиты неотрицательны
+func (q TenantQuota) Validate() error {
+	if q.MaxRunningTasks < 0 || q.MaxPendingTasks < 0 || q.MaxTasksPerDay < 0 || q.MaxTaskSeconds < 0 || q.PeriodHours < 0 {
+		return fmt.Errorf("%w: quota limits must be >= 0", ErrInvalidParameters)
+	}
+	return nil
+}
+
+// Period - Период MaxTaskSeconds
+func (q TenantQuota) Period() time.Duration {
+	if q.PeriodHours > 0 {
+		return time.Duration(q.PeriodHours) * time.Hour
+	}
+	return DefaultQuotaPeriod
+}
+
+// UsageReport - потребление принципалов за окно [From, To)
+type UsageReport struct {
+	From  time.Time `json:"from"`
+	To    time.Time `json:"to"`
+	Usage []Usage   `json:"usage"`
+}
+
+// Usage - потребление принципала за окно отчета
+type Usage struct {
+	Principal string `json:"principal"`
+	// TasksCreated - задач, созданных в окне
+	TasksCreated int `json:"tasksCreated"`
+	// TasksFinished - запусков, закончившихся в окне (завершение, ошибка, отмена, удаление)
+	TasksFinished int `json:"tasksFinished"`
+	// TaskSeconds - секунд выполнения задач, пришедшихся на окно
+	TaskSeconds float64 `json:"taskSeconds"`
+	// RunningTasks, PendingTasks - текущее число задач (на момент запроса)
+	RunningTasks int         `json:"runningTasks"`
+	PendingTasks int         `json:"pendingTasks"`
+	Quota        TenantQuota `json:"quota"`
+}
@@ -0,0 +1,20 @@
+#!/bin/bash
+
+# Генерация API ключа для аутентификации (Authorization: Bearer <key>)
+# Использование: ./gen-apikey.sh <principal> [scope...]
+PRINCIPAL=${1:?"Usage: $0 <principal> [scope...]"}
+shift
+
+KEY=$(openssl rand -hex 32)
+HASH=$(printf '%s' "$KEY" | openssl dgst -sha256 | awk '{print $NF}')
+
+SCOPES=""
+for scope in "$@"; do
+  SCOPES="$SCOPES${SCOPES:+, }\"$scope\""
+done
+
+echo "API key for '$PRINCIPAL' (сохраните, в конфиге хранится только хеш):"
+echo "  $KEY"
+echo ""
+echo "Entry for apikeys.json:"
+echo "  {\"principal\": \"$PRINCIPAL\", \"hash\": \"$HASH\", \"scopes\": [$SCOPES]}"
@@ -1,16 +1,98 @@
 #!/bin/bash
 
-# Генерация самоподписанного сертификата для HTTPS/HTTP2
-echo "Generating self-signed certificate for HTTP/2..."
-
-openssl req -x509 -newkey rsa:4096 \
-  -keyout key.pem \
-  -out cert.pem \
-  -days 365 \
-  -nodes \
-  -subj "/C=RU/ST=Moscow/L=Moscow/O=WorkMate/OU=Development/CN=localhost"
-
-echo "Certificate generated successfully!"
-echo "Files created:"
-echo "  - cert.pem (certificate)"
-echo "  - key.pem (private key)" 
\ No newline at end of file
+# Генерация локального CA и подписанных им сертификатов для HTTPS/HTTP2 и mTLS
+#
+# Использование:
+#   ./gen-certs.sh                        - CA (если еще нет) и серверный сертификат cert.pem/key.pem
+#   ./gen-certs.sh client <name> [ou...]  - клиентский сертификат client-<name>.pem/client-<name>-key.pem
+#                                           (CN=<name> - принципал, OU - права доступа, например admin)
+set -e
+
+DAYS=365
+SUBJ="/C=RU/ST=Moscow/L=Moscow/O=WorkMate"
+
+gen_ca() {
+  if [ -f ca.pem ] && [ -f ca-key.pem ]; then
+    return
+  fi
+  echo "Generating local CA..."
+  openssl req -x509 -newkey rsa:4096 \
+    -keyout ca-key.pem \
+    -out ca.pem \
+    -days $((DAYS * 10)) \
+    -nodes \
+    -subj "$SUBJ/OU=Development/CN=WorkMate Local CA" \
+    -addext "basicConstraints=critical,CA:TRUE" \
+    -addext "keyUsage=critical,keyCertSign,cRLSign"
+}
+
+# sign <out> <key> <subj> <extensions>
+sign() {
+  local out=$1 key=$2 subj=$3 ext=$4
+  local csr ext_file
+  csr=$(mktemp)
+  ext_file=$(mktemp)
+  printf '%b\n' "$ext" > "$ext_file"
+
+  openssl req -newkey rsa:4096 -nodes -keyout "$key" -out "$csr" -subj "$subj"
+  openssl x509 -req -in "$csr" \
+    -CA ca.pem -CAkey ca-key.pem -CAcreateserial \
+    -out "$out" \
+    -days $DAYS \
+    -extfile "$ext_file"
+
+  rm -f "$csr" "$ext_file"
+}
+
+gen_server() {
+  echo "Generating server certificate for HTTP/2..."
+  sign cert.pem key.pem "$SUBJ/OU=Development/CN=localhost" \
+    "subjectAltName=DNS:localhost,IP:127.0.0.1\nextendedKeyUsage=serverAuth"
+
+  echo "Certificate generated successfully!"
+  echo "Files created:"
+  echo "  - ca.pem (local CA certificate, trust it in clients)"
+  echo "  - cert.pem (certificate)"
+  echo "  - key.pem (private key)"
+  echo ""
+  echo "To require client certificates (mTLS): cp ca.pem client-ca.pem"
+}
+
+gen_client() {
+  local name=$1
+  shift
+  if [ -z "$name" ]; then
+    echo "Usage: $0 client <name> [ou...]" >&2
+    exit 1
+  fi
+
+  local subj="$SUBJ"
+  for ou in "$@"; do
+    subj="$subj/OU=$ou"
+  done
+  subj="$subj/CN=$name"
+
+  echo "Generating client certificate for '$name'..."
+  sign "client-$name.pem" "client-$name-key.pem" "$subj" \
+    "extendedKeyUsage=clientAuth"
+
+  echo "Client certificate generated successfully!"
+  echo "Files created:"
+  echo "  - client-$name.pem (certificate)"
+  echo "  - client-$name-key.pem (private key)"
+}
+
+gen_ca
+case "$1" in
+  client)
+    shift
+    gen_client "$@"
+    ;;
+  ""|server)
+    gen_server
+    ;;
+  *)
+    echo "Unknown command: $1" >&2
+    exit 1
+    ;;
+esac